@@ -0,0 +1,672 @@
+// Package conditional 업비트가 지원하지 않는 조건부 주문(손절, 익절, 추적 손절, OCO)을 서버에서 처리한다.
+// 백그라운드에서 현재가를 주기적으로 조회하다가 조건을 만족하면 보유 수량을 매도 주문한다.
+// 추적 손절이 있는 마켓은 마켓마다 고루틴을 두고 더 짧은 주기로 현재가를 조회해 최고가를 갱신한다.
+package conditional
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/engine"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// DefaultInterval 현재가를 조회하는 기본 주기
+const DefaultInterval = 5 * time.Second
+
+// DefaultTrailInterval 추적 손절 마켓의 현재가를 조회해 최고가를 갱신하는 기본 주기
+const DefaultTrailInterval = time.Second
+
+// stateName 조건부 주문을 저장하는 파일 이름
+const stateName = "conditional"
+
+// IdentifierPrefix 조건부 주문이 실행될 때 낸 주문의 identifier 접두사. 뒤에 조건부 주문 ID가 붙는다
+const IdentifierPrefix = "cond-"
+
+// 조건부 주문 종류
+const (
+	KindStopLoss   = "stop_loss"   // 현재가가 trigger_price 이하가 되면 매도
+	KindTakeProfit = "take_profit" // 현재가가 trigger_price 이상이 되면 매도
+	// 현재가가 등록 후 최고가에서 trail_percent(%) 또는 trail_amount만큼 내려오면 시장가 매도
+	KindTrailingStop = "trailing_stop"
+)
+
+// trailPlaces 추적 손절 기준가를 비율로 계산할 때의 소수점 자릿수
+const trailPlaces = 8
+
+// 조건부 주문 상태
+const (
+	StatePending   = "pending"   // 조건을 기다리는 중
+	StateFiring    = "firing"    // 조건을 만족해 주문하는 중
+	StateTriggered = "triggered" // 조건을 만족해 주문함
+	StateCanceled  = "canceled"  // 사용자가 취소했거나 OCO의 다른 주문이 실행됨
+	StateFailed    = "failed"    // 조건을 만족했지만 거래소가 주문을 거절함
+)
+
+// 조건부 주문을 등록하거나 취소하지 못한 이유
+const (
+	ErrInvalidOrder     = "invalid_conditional_order"
+	ErrInsufficientHeld = "insufficient_position"
+	ErrAlreadyReached   = "trigger_already_reached"
+	ErrNotFound         = "conditional_order_not_found"
+	ErrNotPending       = "conditional_order_not_pending"
+)
+
+// Error 조건부 주문 요청이 거절된 이유
+type Error = engine.Error
+
+func newError(name, format string, args ...any) error {
+	return engine.NewError("conditional order", name, format, args...)
+}
+
+// Order 조건부 주문
+type Order struct {
+	ID             string        `json:"id" jsonschema:"Conditional order ID."`
+	GroupID        string        `json:"group_id,omitempty" jsonschema:"Shared by the legs of an OCO bracket. When one leg triggers, the other is canceled."`
+	Kind           string        `json:"kind" jsonschema:"stop_loss (sell when the price falls to trigger_price), take_profit (sell when the price rises to trigger_price) or trailing_stop (sell when the price falls by the trail distance from high_price)."`
+	Market         string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Volume         upbit.Decimal `json:"volume" jsonschema:"Volume to sell when triggered."`
+	TriggerPrice   upbit.Decimal `json:"trigger_price" jsonschema:"Current price that triggers the order. For trailing stops this is the current trail level (high_price minus the trail distance), updated on every check."`
+	TrailPercent   upbit.Decimal `json:"trail_percent,omitempty" jsonschema:"Trailing stop distance from high_price in percent."`
+	TrailAmount    upbit.Decimal `json:"trail_amount,omitempty" jsonschema:"Trailing stop distance from high_price in the quote currency."`
+	HighPrice      upbit.Decimal `json:"high_price,omitempty" jsonschema:"Highest current price observed since the trailing stop was created."`
+	LimitPrice     upbit.Decimal `json:"limit_price,omitempty" jsonschema:"Limit price of the sell order placed when triggered. A market sell order is placed if empty."`
+	State          string        `json:"state" jsonschema:"pending, firing (the sell order is being placed), triggered, canceled or failed."`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	TriggeredPrice upbit.Decimal `json:"triggered_price,omitempty" jsonschema:"Current price observed when the condition was met."`
+	OrderUuid      string        `json:"order_uuid,omitempty" jsonschema:"UUID of the order placed when triggered."`
+	Note           string        `json:"note,omitempty" jsonschema:"Why the order was canceled or failed."`
+}
+
+// Market 조건 확인에 사용하는 시세 조회 기능
+type Market interface {
+	GetTicker(ctx context.Context, symbol string) ([]upbit.Ticker, error)
+}
+
+// Engine 조건부 주문을 보관하고 현재가를 감시하다가 조건을 만족하면 trader로 주문한다
+type Engine struct {
+	trader        upbit.Trader
+	market        Market
+	base          engine.Base
+	trailInterval time.Duration
+
+	// checkMu 조건 확인이 겹쳐 같은 주문을 두 번 내지 않도록 한다
+	checkMu sync.Mutex
+
+	// mu 조건부 주문 목록과 추적 손절 감시 고루틴을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu       sync.Mutex
+	orders   []*Order
+	runCtx   context.Context // Run에 전달된 ctx. Run 전에는 nil이며 감시 고루틴을 시작하지 않는다
+	watchers map[string]bool // 최고가를 감시 중인 마켓
+}
+
+// firing 조건을 만족해 주문할 조건부 주문과 그때의 현재가
+type firing struct {
+	order Order
+	price upbit.Decimal
+}
+
+// NewEngine 조건부 주문 엔진 생성. st가 nil이면 조건부 주문을 메모리에만 보관한다.
+// trailInterval이 0 이하이면 DefaultTrailInterval마다 추적 손절의 최고가를 갱신한다.
+func NewEngine(trader upbit.Trader, market Market, st *store.Store, interval, trailInterval time.Duration) (*Engine, error) {
+	if trailInterval <= 0 {
+		trailInterval = DefaultTrailInterval
+	}
+	e := &Engine{
+		trader:        trader,
+		market:        market,
+		base:          engine.NewBase(st, interval, DefaultInterval),
+		trailInterval: trailInterval,
+		watchers:      map[string]bool{},
+	}
+	if err := e.base.Load(stateName, &e.orders); err != nil {
+		return nil, err
+	}
+	// 주문하는 중에 서버가 멈춘 주문은 다시 대기시킨다. 같은 identifier로 주문하므로 이미 나간 주문은 중복되지 않는다
+	for _, o := range e.orders {
+		if o.State == StateFiring {
+			o.State = StatePending
+		}
+	}
+	return e, nil
+}
+
+// Add 조건부 주문을 등록한다. 여러 개를 함께 등록하면 OCO로 묶여 하나가 실행되면 나머지는 취소된다.
+// 보유 수량이 부족하거나 이미 조건을 만족하는 주문은 거절한다.
+func (e *Engine) Add(ctx context.Context, orders ...Order) ([]Order, error) {
+	if len(orders) == 0 {
+		return nil, newError(ErrInvalidOrder, "no conditional order to add")
+	}
+	for _, o := range orders {
+		if err := o.validate(); err != nil {
+			return nil, err
+		}
+	}
+	balances, err := e.balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkPrices(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// 동시에 등록하는 주문끼리 같은 보유 수량을 쓰지 않도록 확보된 수량은 목록에 추가하기 직전에 잠금 안에서 센다
+	if err := e.checkPosition(balances, orders); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var groupID string
+	if len(orders) > 1 {
+		groupID = uuid.NewString()
+	}
+
+	added := make([]Order, 0, len(orders))
+	for _, o := range orders {
+		o.ID = uuid.NewString()
+		o.GroupID = groupID
+		o.State = StatePending
+		o.CreatedAt = now
+		o.UpdatedAt = now
+		e.orders = append(e.orders, &o)
+		added = append(added, o)
+		if o.Kind == KindTrailingStop {
+			e.watch(o.Market)
+		}
+	}
+	if err := e.save(); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// List 조건부 주문 목록. market, state가 비어 있으면 전체를 반환한다
+func (e *Engine) List(market, state string) []Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	res := []Order{}
+	for _, o := range e.orders {
+		if (market == "" || o.Market == market) && (state == "" || o.State == state) {
+			res = append(res, *o)
+		}
+	}
+	return res
+}
+
+// Cancel 대기 중인 조건부 주문을 취소한다. OCO로 묶인 주문도 함께 취소하고 취소된 주문을 반환한다.
+// 매도 주문을 내는 중인 주문과 그 OCO 주문은 취소할 수 없다.
+func (e *Engine) Cancel(id string) ([]Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	target := e.find(id)
+	if target == nil {
+		return nil, newError(ErrNotFound, "conditional order %s not found", id)
+	}
+	if target.State != StatePending {
+		return nil, newError(ErrNotPending, "conditional order %s is already %s", id, target.State)
+	}
+	if target.GroupID != "" {
+		for _, o := range e.orders {
+			if o.GroupID == target.GroupID && o.State == StateFiring {
+				return nil, newError(ErrNotPending, "the other leg %s of the OCO bracket is already firing", o.ID)
+			}
+		}
+	}
+
+	var canceled []Order
+	for _, o := range e.orders {
+		if o == target || (target.GroupID != "" && o.GroupID == target.GroupID && o.State == StatePending) {
+			o.setState(StateCanceled, "canceled by the user")
+			canceled = append(canceled, *o)
+		}
+	}
+	return canceled, e.save()
+}
+
+// Run ctx가 끝날 때까지 주기적으로 조건을 확인하고, 추적 손절이 있는 마켓의 최고가를 마켓마다 감시한다
+func (e *Engine) Run(ctx context.Context) {
+	e.mu.Lock()
+	e.runCtx = ctx
+	for _, o := range e.orders {
+		if o.Kind == KindTrailingStop && o.State == StatePending {
+			e.watch(o.Market)
+		}
+	}
+	e.mu.Unlock()
+
+	e.base.Run(ctx, "conditional orders", e.Check)
+}
+
+// watch market의 최고가를 감시하는 고루틴이 없으면 시작한다. e.mu를 잡고 호출해야 한다
+func (e *Engine) watch(market string) {
+	if e.runCtx == nil || e.watchers[market] {
+		return
+	}
+	e.watchers[market] = true
+	go e.watchTrail(e.runCtx, market)
+}
+
+// watchTrail trailInterval마다 market의 현재가를 조회해 추적 손절의 최고가를 갱신한다.
+// 현재가가 기준가에 닿으면 확인 주기를 기다리지 않고 바로 조건을 확인하며,
+// 대기 중인 추적 손절이 남지 않으면 끝난다.
+func (e *Engine) watchTrail(ctx context.Context, market string) {
+	ticker := time.NewTicker(e.trailInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.mu.Lock()
+			delete(e.watchers, market)
+			e.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+
+		prices, err := e.prices(ctx, []string{market})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("trailing stops of %s: %v", market, err)
+			}
+			continue
+		}
+		price, ok := prices[market]
+		if !ok {
+			continue
+		}
+
+		watching, reached := e.observe(market, price)
+		if !watching {
+			return
+		}
+		if reached {
+			if err := e.Check(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("conditional orders: %v", err)
+			}
+		}
+	}
+}
+
+// observe 현재가로 market의 추적 손절 최고가를 갱신하고 기준가에 닿은 주문이 있는지 반환한다.
+// 대기 중인 추적 손절이 없으면 감시를 끝내도록 watching을 false로 반환한다.
+func (e *Engine) observe(market string, price upbit.Decimal) (watching, reached bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	changed := false
+	for _, o := range e.orders {
+		if o.Market != market || o.Kind != KindTrailingStop || o.State != StatePending {
+			continue
+		}
+		watching = true
+		if o.trail(price) {
+			changed = true
+		}
+		if o.triggered(price) {
+			reached = true
+		}
+	}
+	if !watching {
+		delete(e.watchers, market)
+		return false, false
+	}
+	if changed {
+		if err := e.save(); err != nil {
+			log.Printf("trailing stops of %s: %v", market, err)
+		}
+	}
+	return true, reached
+}
+
+// Check 대기 중인 조건부 주문의 현재가를 한 번 조회하고 조건을 만족한 주문을 실행한다.
+// 현재가 조회와 주문은 잠금 밖에서 하고 결과만 잠금을 잡고 반영한다.
+func (e *Engine) Check(ctx context.Context) error {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	markets := e.pendingMarkets()
+	if len(markets) == 0 {
+		return nil
+	}
+	prices, err := e.prices(ctx, markets)
+	if err != nil {
+		return err
+	}
+
+	fired, changed := e.trigger(prices)
+	orders := make([]upbit.Order, len(fired))
+	errs := make([]error, len(fired))
+	for i, f := range fired {
+		orders[i], errs[i] = e.fire(ctx, f.order)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, f := range fired {
+		if e.record(f, orders[i], errs[i]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return e.save()
+}
+
+// trigger 현재가로 추적 손절의 기준가를 올리고 조건을 만족한 주문을 고른다. OCO로 묶인 주문은 한쪽만 고른다.
+// 고른 주문은 firing 상태로 바꿔 주문하는 동안 취소되지 않도록 한다.
+func (e *Engine) trigger(prices map[string]upbit.Decimal) (fired []firing, changed bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	groups := map[string]bool{}
+	for _, o := range e.orders {
+		if o.GroupID != "" && o.State == StateFiring {
+			groups[o.GroupID] = true
+		}
+	}
+	for _, o := range e.orders {
+		price, ok := prices[o.Market]
+		if !ok || o.State != StatePending {
+			continue
+		}
+		if o.Kind == KindTrailingStop && o.trail(price) {
+			changed = true
+		}
+		if !o.triggered(price) || (o.GroupID != "" && groups[o.GroupID]) {
+			continue
+		}
+		if o.GroupID != "" {
+			groups[o.GroupID] = true
+		}
+		o.State = StateFiring
+		fired = append(fired, firing{order: *o, price: price})
+	}
+	return fired, changed
+}
+
+// fire 조건을 만족한 주문을 낸다.
+// 같은 identifier로 주문하므로 응답을 받지 못한 주문을 다시 보내도 중복 주문되지 않는다.
+func (e *Engine) fire(ctx context.Context, o Order) (upbit.Order, error) {
+	params := upbit.RequestParams{
+		Market:     o.Market,
+		Side:       "ask",
+		OrdType:    upbit.OrdTypeMarket,
+		Volume:     o.Volume,
+		SmpType:    upbit.SmpCancelMaker,
+		Identifier: IdentifierPrefix + o.ID,
+	}
+	if o.LimitPrice != "" {
+		params.OrdType = upbit.OrdTypeLimit
+		params.Price = o.LimitPrice
+	}
+	return e.trader.PlaceOrder(ctx, params)
+}
+
+// record firing 상태인 주문에 주문 결과를 반영한다. 일시적인 오류로 주문하지 못하면 대기 상태로 되돌려 다음 확인에서 다시 시도한다.
+func (e *Engine) record(f firing, order upbit.Order, err error) bool {
+	o := e.find(f.order.ID)
+	if o == nil || o.State != StateFiring {
+		return false
+	}
+	if err != nil {
+		if upbit.IsTemporary(err) {
+			log.Printf("conditional order %s triggered at %s but the order could not be placed, retrying: %v", o.ID, f.price, err)
+			o.State = StatePending
+			return false
+		}
+		o.TriggeredPrice = f.price
+		o.setState(StateFailed, err.Error())
+		return true
+	}
+
+	o.TriggeredPrice = f.price
+	o.OrderUuid = order.Uuid
+	o.setState(StateTriggered, "")
+	if o.GroupID != "" {
+		for _, other := range e.orders {
+			if other != o && other.GroupID == o.GroupID && other.State == StatePending {
+				other.setState(StateCanceled, fmt.Sprintf("the other leg %s of the OCO bracket was triggered", o.ID))
+			}
+		}
+	}
+	return true
+}
+
+// balances 화폐별 보유 수량. 거래소에 조회하므로 잠금 밖에서 호출한다
+func (e *Engine) balances(ctx context.Context) (map[string]upbit.Decimal, error) {
+	accounts, err := e.trader.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances := map[string]upbit.Decimal{}
+	for _, a := range accounts {
+		balances[a.Currency] = a.Balance
+	}
+	return balances, nil
+}
+
+// checkPosition 등록할 주문의 매도 수량을 보유하고 있는지 확인. OCO는 한쪽만 실행되므로 가장 큰 수량만 필요하다.
+// 다른 주문에 묶인 수량과 대기 중인 다른 조건부 주문이 매도할 수량은 실행할 때 쓸 수 없으므로 빼고 확인한다.
+// 잠금을 잡고 호출한다.
+func (e *Engine) checkPosition(balances map[string]upbit.Decimal, orders []Order) error {
+	committed := e.committed()
+	need := map[string]upbit.Decimal{}
+	for _, o := range orders {
+		currency := baseCurrency(o.Market)
+		need[currency] = upbit.MaxDecimal(need[currency], o.Volume)
+	}
+	for currency, volume := range need {
+		held, ok := balances[currency]
+		if !ok {
+			held = "0"
+		}
+		if available := held.Sub(committed[currency]); volume.GreaterThan(available) {
+			return newError(ErrInsufficientHeld, "volume %s exceeds the %s available (%s), %s of the %s balance is committed to other conditional orders",
+				volume, currency, available, committed[currency], held)
+		}
+	}
+	return nil
+}
+
+// committed 화폐별로 대기 중인 조건부 주문이 매도할 수량. OCO는 한쪽만 실행되므로 가장 큰 수량만 센다.
+// 잠금을 잡고 호출한다.
+func (e *Engine) committed() map[string]upbit.Decimal {
+	res := map[string]upbit.Decimal{}
+	groups := map[string]Order{}
+	for _, o := range e.orders {
+		if o.State != StatePending && o.State != StateFiring {
+			continue
+		}
+		if o.GroupID == "" {
+			currency := baseCurrency(o.Market)
+			res[currency] = res[currency].Add(o.Volume)
+			continue
+		}
+		if g, ok := groups[o.GroupID]; !ok || o.Volume.GreaterThan(g.Volume) {
+			groups[o.GroupID] = *o
+		}
+	}
+	for _, o := range groups {
+		currency := baseCurrency(o.Market)
+		res[currency] = res[currency].Add(o.Volume)
+	}
+	return res
+}
+
+// checkPrices 이미 조건을 만족하는 주문은 등록하자마자 실행되므로 거절한다.
+// 추적 손절은 현재가를 최고가로 삼아 기준가를 정한다.
+func (e *Engine) checkPrices(ctx context.Context, orders []Order) error {
+	var markets []string
+	for _, o := range orders {
+		if !slices.Contains(markets, o.Market) {
+			markets = append(markets, o.Market)
+		}
+	}
+	prices, err := e.prices(ctx, markets)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		o := &orders[i]
+		price, ok := prices[o.Market]
+		if !ok {
+			return fmt.Errorf("no current price for %s", o.Market)
+		}
+		if o.Kind == KindTrailingStop {
+			o.HighPrice = price
+			o.TriggerPrice = o.trailLevel()
+			if o.TriggerPrice.Sign() <= 0 {
+				return newError(ErrInvalidOrder, "trail_amount %s must be less than the current price %s", o.TrailAmount, price)
+			}
+			continue
+		}
+		if o.triggered(price) {
+			return newError(ErrAlreadyReached, "%s trigger price %s is already reached by the current price %s", o.Kind, o.TriggerPrice, price)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) pendingMarkets() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var markets []string
+	for _, o := range e.orders {
+		if o.State == StatePending && !slices.Contains(markets, o.Market) {
+			markets = append(markets, o.Market)
+		}
+	}
+	return markets
+}
+
+// prices 마켓별 현재가
+func (e *Engine) prices(ctx context.Context, markets []string) (map[string]upbit.Decimal, error) {
+	tickers, err := e.market.GetTicker(ctx, strings.Join(markets, ","))
+	if err != nil {
+		return nil, err
+	}
+	prices := make(map[string]upbit.Decimal, len(tickers))
+	for _, t := range tickers {
+		prices[t.Market] = t.TradePrice
+	}
+	return prices, nil
+}
+
+// baseCurrency 마켓에서 매도하는 화폐. e.g. KRW-BTC → BTC
+func baseCurrency(market string) string {
+	_, base, _ := strings.Cut(market, "-")
+	return base
+}
+
+func (e *Engine) find(id string) *Order {
+	for _, o := range e.orders {
+		if o.ID == id {
+			return o
+		}
+	}
+	return nil
+}
+
+func (e *Engine) save() error {
+	return e.base.Save(stateName, e.orders)
+}
+
+// triggered 현재가가 조건을 만족하는지 여부
+func (o *Order) triggered(price upbit.Decimal) bool {
+	switch o.Kind {
+	case KindStopLoss, KindTrailingStop:
+		return !price.GreaterThan(o.TriggerPrice)
+	case KindTakeProfit:
+		return !price.LessThan(o.TriggerPrice)
+	}
+	return false
+}
+
+// trail 현재가가 최고가를 넘으면 최고가와 기준가를 올린다. 기준가가 바뀌었는지 반환한다
+func (o *Order) trail(price upbit.Decimal) bool {
+	if !price.GreaterThan(o.HighPrice) {
+		return false
+	}
+	o.HighPrice = price
+	o.TriggerPrice = o.trailLevel()
+	o.UpdatedAt = time.Now()
+	return true
+}
+
+// trailLevel 최고가에서 추적 거리만큼 내려온 기준가
+func (o *Order) trailLevel() upbit.Decimal {
+	if o.TrailAmount != "" {
+		return o.HighPrice.Sub(o.TrailAmount)
+	}
+	hundred := upbit.DecimalFromInt(100)
+	return o.HighPrice.Mul(hundred.Sub(o.TrailPercent)).Div(hundred, trailPlaces)
+}
+
+func (o *Order) setState(state, note string) {
+	o.State = state
+	o.Note = note
+	o.UpdatedAt = time.Now()
+}
+
+func (o Order) validate() error {
+	if o.Market == "" {
+		return newError(ErrInvalidOrder, "market is required")
+	}
+	if o.Volume.Sign() <= 0 {
+		return newError(ErrInvalidOrder, "volume must be positive")
+	}
+	switch o.Kind {
+	case KindStopLoss, KindTakeProfit:
+		if o.TriggerPrice.Sign() <= 0 {
+			return newError(ErrInvalidOrder, "trigger_price must be positive")
+		}
+		if o.TrailPercent != "" || o.TrailAmount != "" {
+			return newError(ErrInvalidOrder, "trail_percent and trail_amount are only allowed for trailing stops")
+		}
+	case KindTrailingStop:
+		return o.validateTrail()
+	default:
+		return newError(ErrInvalidOrder, "unknown conditional order kind %q", o.Kind)
+	}
+	if o.LimitPrice != "" {
+		if o.LimitPrice.Sign() <= 0 {
+			return newError(ErrInvalidOrder, "limit_price must be positive")
+		}
+		if _, err := upbit.PriceUnit(o.Market, o.LimitPrice); err == nil && !upbit.IsValidPrice(o.Market, o.LimitPrice) {
+			return newError(ErrInvalidOrder, "limit_price %s is not aligned to the tick size of %s", o.LimitPrice, o.Market)
+		}
+	}
+	return nil
+}
+
+// validateTrail 추적 손절은 시장가로만 매도하며 trail_percent와 trail_amount 중 하나만 지정한다
+func (o Order) validateTrail() error {
+	if o.LimitPrice != "" {
+		return newError(ErrInvalidOrder, "trailing stops are always executed as market sell orders, limit_price is not allowed")
+	}
+	if (o.TrailPercent == "") == (o.TrailAmount == "") {
+		return newError(ErrInvalidOrder, "exactly one of trail_percent and trail_amount is required")
+	}
+	if o.TrailPercent != "" && (o.TrailPercent.Sign() <= 0 || !o.TrailPercent.LessThan(upbit.DecimalFromInt(100))) {
+		return newError(ErrInvalidOrder, "trail_percent must be between 0 and 100")
+	}
+	if o.TrailAmount != "" && o.TrailAmount.Sign() <= 0 {
+		return newError(ErrInvalidOrder, "trail_amount must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package conditional_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"upbit-mcp-server/conditional"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+// newEngine 현재가 1억 원, 0.01 BTC를 보유한 거래소의 조건부 주문 엔진.
+// 일시적인 오류를 엔진이 다시 시도하는지 보기 위해 클라이언트는 재시도하지 않는다.
+func newEngine(t *testing.T) (*upbittest.Exchange, *conditional.Engine) {
+	t.Helper()
+
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"BTC": "0.01"})
+	client := ex.Client(upbit.WithRetryPolicy(upbit.RetryPolicy{MaxAttempts: 1}))
+	e, err := conditional.NewEngine(client, client, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ex, e
+}
+
+func find(t *testing.T, e *conditional.Engine, id string) conditional.Order {
+	t.Helper()
+	for _, o := range e.List("", "") {
+		if o.ID == id {
+			return o
+		}
+	}
+	t.Fatalf("conditional order %s not found", id)
+	return conditional.Order{}
+}
+
+func TestStopLossTrigger(t *testing.T) {
+	ex, e := newEngine(t)
+	ctx := context.Background()
+
+	added, err := e.Add(ctx, conditional.Order{Kind: conditional.KindStopLoss, Market: "KRW-BTC", Volume: "0.001", TriggerPrice: "90000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := added[0].ID
+
+	ex.SetPrice("KRW-BTC", "95000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if o := find(t, e, id); o.State != conditional.StatePending {
+		t.Fatalf("state above the trigger price = %s", o.State)
+	}
+
+	ex.SetPrice("KRW-BTC", "89000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	o := find(t, e, id)
+	if o.State != conditional.StateTriggered || !o.TriggeredPrice.Equal("89000000") {
+		t.Fatalf("order = %s, triggered at %s", o.State, o.TriggeredPrice)
+	}
+	order, ok := ex.Order(o.OrderUuid)
+	if !ok || order.Identifier != conditional.IdentifierPrefix+id || order.Side != "ask" || !order.ExecutedVolume.Equal("0.001") {
+		t.Errorf("sell order = %+v", order)
+	}
+}
+
+func TestOCOCancelsOtherLeg(t *testing.T) {
+	ex, e := newEngine(t)
+	ctx := context.Background()
+
+	added, err := e.Add(ctx,
+		conditional.Order{Kind: conditional.KindStopLoss, Market: "KRW-BTC", Volume: "0.005", TriggerPrice: "90000000"},
+		conditional.Order{Kind: conditional.KindTakeProfit, Market: "KRW-BTC", Volume: "0.005", TriggerPrice: "110000000"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop, take := added[0].ID, added[1].ID
+
+	ex.SetPrice("KRW-BTC", "110000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if o := find(t, e, take); o.State != conditional.StateTriggered {
+		t.Errorf("take profit = %s", o.State)
+	}
+	if o := find(t, e, stop); o.State != conditional.StateCanceled || o.Note == "" {
+		t.Errorf("stop loss = %s (%s)", o.State, o.Note)
+	}
+
+	// 취소된 쪽은 가격이 내려와도 실행되지 않는다
+	ex.SetPrice("KRW-BTC", "85000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+}
+
+func TestCommittedVolumeIsNotAvailable(t *testing.T) {
+	_, e := newEngine(t)
+	ctx := context.Background()
+
+	if _, err := e.Add(ctx, conditional.Order{Kind: conditional.KindStopLoss, Market: "KRW-BTC", Volume: "0.008", TriggerPrice: "90000000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 0.01 BTC 중 0.008 BTC는 다른 조건부 주문이 매도할 수량이다
+	_, err := e.Add(ctx, conditional.Order{Kind: conditional.KindTakeProfit, Market: "KRW-BTC", Volume: "0.003", TriggerPrice: "110000000"})
+	var condErr *conditional.Error
+	if !errors.As(err, &condErr) || condErr.Name != conditional.ErrInsufficientHeld {
+		t.Fatalf("Add = %v, want %s", err, conditional.ErrInsufficientHeld)
+	}
+	if _, err := e.Add(ctx, conditional.Order{Kind: conditional.KindTakeProfit, Market: "KRW-BTC", Volume: "0.002", TriggerPrice: "110000000"}); err != nil {
+		t.Errorf("Add within the available volume: %v", err)
+	}
+}
+
+// barrierAccounts 두 번째 조회가 끝날 때까지 계좌 조회 응답을 미루는 Trader
+type barrierAccounts struct {
+	upbit.Trader
+	wg sync.WaitGroup
+}
+
+func (b *barrierAccounts) GetAccounts(ctx context.Context) ([]upbit.Account, error) {
+	accounts, err := b.Trader.GetAccounts(ctx)
+	b.wg.Done()
+	b.wg.Wait()
+	return accounts, err
+}
+
+func TestConcurrentAddsDoNotOvercommit(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"BTC": "0.01"})
+	client := ex.Client()
+	trader := &barrierAccounts{Trader: client}
+	trader.wg.Add(2)
+	e, err := conditional.NewEngine(trader, client, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 두 주문 모두 0.01 BTC를 본 뒤에 등록하지만 합쳐서 보유 수량을 넘을 수는 없다
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = e.Add(context.Background(), conditional.Order{Kind: conditional.KindStopLoss, Market: "KRW-BTC", Volume: "0.006", TriggerPrice: "90000000"})
+		}()
+	}
+	wg.Wait()
+
+	var added, rejected int
+	for _, err := range errs {
+		var condErr *conditional.Error
+		switch {
+		case err == nil:
+			added++
+		case errors.As(err, &condErr) && condErr.Name == conditional.ErrInsufficientHeld:
+			rejected++
+		default:
+			t.Fatal(err)
+		}
+	}
+	if added != 1 || rejected != 1 {
+		t.Errorf("added %d, rejected %d, want 1 and 1", added, rejected)
+	}
+}
+
+func TestTemporaryErrorRetries(t *testing.T) {
+	ex, e := newEngine(t)
+	ctx := context.Background()
+
+	added, err := e.Add(ctx, conditional.Order{Kind: conditional.KindStopLoss, Market: "KRW-BTC", Volume: "0.001", TriggerPrice: "90000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := added[0].ID
+
+	// 거래소 오류로 주문하지 못하면 대기 상태로 되돌리고 다음 확인에서 다시 주문한다
+	ex.SetPrice("KRW-BTC", "90000000")
+	ex.FailNext(upbittest.Failure{Method: "POST", Path: "orders", Status: http.StatusInternalServerError, Name: upbit.ErrServerError})
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if o := find(t, e, id); o.State != conditional.StatePending || o.OrderUuid != "" {
+		t.Fatalf("order after the failure = %s", o.State)
+	}
+
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	o := find(t, e, id)
+	if o.State != conditional.StateTriggered {
+		t.Fatalf("order after the retry = %s (%s)", o.State, o.Note)
+	}
+	if order, ok := ex.Order(o.OrderUuid); !ok || order.Identifier != conditional.IdentifierPrefix+id {
+		t.Errorf("sell order = %+v", order)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 2 {
+		t.Errorf("order requests = %d, want 2", n)
+	}
+}
+
+func TestTrailingStopFollowsHigh(t *testing.T) {
+	ex, e := newEngine(t)
+	ctx := context.Background()
+
+	added, err := e.Add(ctx, conditional.Order{Kind: conditional.KindTrailingStop, Market: "KRW-BTC", Volume: "0.001", TrailPercent: "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := added[0].ID
+	if o := added[0]; !o.HighPrice.Equal("100000000") || !o.TriggerPrice.Equal("90000000") {
+		t.Fatalf("high = %s, trail level %s", o.HighPrice, o.TriggerPrice)
+	}
+
+	// 최고가가 오르면 기준가도 오르고, 내려와도 기준가는 그대로 둔다
+	for _, step := range []struct {
+		price, high, level upbit.Decimal
+	}{
+		{price: "120000000", high: "120000000", level: "108000000"},
+		{price: "110000000", high: "120000000", level: "108000000"},
+	} {
+		ex.SetPrice("KRW-BTC", step.price)
+		if err := e.Check(ctx); err != nil {
+			t.Fatal(err)
+		}
+		o := find(t, e, id)
+		if o.State != conditional.StatePending || !o.HighPrice.Equal(step.high) || !o.TriggerPrice.Equal(step.level) {
+			t.Fatalf("at %s: %s, high %s, trail level %s", step.price, o.State, o.HighPrice, o.TriggerPrice)
+		}
+	}
+
+	ex.SetPrice("KRW-BTC", "108000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if o := find(t, e, id); o.State != conditional.StateTriggered || !o.TriggeredPrice.Equal("108000000") {
+		t.Errorf("order = %s, triggered at %s", o.State, o.TriggeredPrice)
+	}
+}
+
+func TestTrailWatcherBetweenChecks(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"BTC": "0.01"})
+	client := ex.Client()
+	// 확인 주기는 테스트 중에 오지 않으므로 최고가 갱신과 실행은 감시 고루틴만 한다
+	e, err := conditional.NewEngine(client, client, nil, time.Hour, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go e.Run(ctx)
+
+	added, err := e.Add(ctx, conditional.Order{Kind: conditional.KindTrailingStop, Market: "KRW-BTC", Volume: "0.001", TrailAmount: "5000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := added[0].ID
+
+	ex.SetPrice("KRW-BTC", "130000000")
+	waitFor(t, func() bool { return find(t, e, id).HighPrice.Equal("130000000") })
+	if o := find(t, e, id); o.State != conditional.StatePending || !o.TriggerPrice.Equal("125000000") {
+		t.Fatalf("order = %s, trail level %s", o.State, o.TriggerPrice)
+	}
+
+	ex.SetPrice("KRW-BTC", "125000000")
+	waitFor(t, func() bool { return find(t, e, id).State == conditional.StateTriggered })
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+}
+
+// waitFor cond가 참이 될 때까지 기다린다
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"upbit-mcp-server/conditional"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// conditionalEngineKey는 context 내에서 조건부 주문 엔진을 식별하기 위한 키
+type conditionalEngineKey struct{}
+
+type CreateConditionalOrderRequest struct {
+	Market       string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Kind         string        `json:"kind" jsonschema:"Allowed: 'stop_loss' (sell when the current price falls to or below trigger_price), 'take_profit' (sell when the current price rises to or above trigger_price)."`
+	Volume       upbit.Decimal `json:"volume" jsonschema:"Volume of the held asset to sell when triggered."`
+	TriggerPrice upbit.Decimal `json:"trigger_price" jsonschema:"Current price that triggers the sell order."`
+	LimitPrice   upbit.Decimal `json:"limit_price,omitempty" jsonschema:"Optional. Place a limit sell order at this price when triggered. A market sell order is placed if omitted."`
+}
+
+type CreateOCOOrderRequest struct {
+	Market               string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Volume               upbit.Decimal `json:"volume" jsonschema:"Volume of the held asset to sell when either leg triggers."`
+	StopPrice            upbit.Decimal `json:"stop_price" jsonschema:"Stop-loss trigger price, below the current price."`
+	StopLimitPrice       upbit.Decimal `json:"stop_limit_price,omitempty" jsonschema:"Optional limit price of the stop-loss sell order. Market sell if omitted."`
+	TakeProfitPrice      upbit.Decimal `json:"take_profit_price" jsonschema:"Take-profit trigger price, above the current price."`
+	TakeProfitLimitPrice upbit.Decimal `json:"take_profit_limit_price,omitempty" jsonschema:"Optional limit price of the take-profit sell order. Market sell if omitted."`
+}
+
+type CreateTrailingStopOrderRequest struct {
+	Market       string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Volume       upbit.Decimal `json:"volume" jsonschema:"Volume of the held asset to sell at market price when the trailing stop is breached."`
+	TrailPercent upbit.Decimal `json:"trail_percent,omitempty" jsonschema:"Distance from the highest price in percent (e.g. 5 sells when the price falls 5% below its high). Either trail_percent or trail_amount is required."`
+	TrailAmount  upbit.Decimal `json:"trail_amount,omitempty" jsonschema:"Distance from the highest price in the quote currency (e.g. 1000000 KRW). Either trail_percent or trail_amount is required."`
+}
+
+type GetConditionalOrdersRequest struct {
+	Market string `json:"market,omitempty" jsonschema:"Optional. Only return conditional orders of this market."`
+	State  string `json:"state,omitempty" jsonschema:"Optional. Only return conditional orders in this state. Allowed: 'pending', 'firing', 'triggered', 'canceled', 'failed'."`
+}
+
+type CancelConditionalOrderRequest struct {
+	ID string `json:"id" jsonschema:"ID of the pending conditional order to cancel. The other leg of an OCO bracket is canceled too."`
+}
+
+type ConditionalOrdersResult struct {
+	Orders []conditional.Order `json:"orders"`
+}
+
+func CreateConditionalOrder(ctx context.Context, req *mcp.CallToolRequest, params *CreateConditionalOrderRequest) (
+	*mcp.CallToolResult,
+	*ConditionalOrdersResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(conditionalEngineKey{}).(*conditional.Engine)
+	if !ok {
+		return nil, nil, missingDependency("conditional order engine")
+	}
+
+	orders, err := addConditionalOrders(ctx, req, engine, conditional.Order{
+		Kind:         params.Kind,
+		Market:       params.Market,
+		Volume:       params.Volume,
+		TriggerPrice: params.TriggerPrice,
+		LimitPrice:   params.LimitPrice,
+	})
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ConditionalOrdersResult{Orders: orders}, nil
+}
+
+func CreateOCOOrder(ctx context.Context, req *mcp.CallToolRequest, params *CreateOCOOrderRequest) (
+	*mcp.CallToolResult,
+	*ConditionalOrdersResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(conditionalEngineKey{}).(*conditional.Engine)
+	if !ok {
+		return nil, nil, missingDependency("conditional order engine")
+	}
+
+	orders, err := addConditionalOrders(ctx, req, engine,
+		conditional.Order{
+			Kind:         conditional.KindStopLoss,
+			Market:       params.Market,
+			Volume:       params.Volume,
+			TriggerPrice: params.StopPrice,
+			LimitPrice:   params.StopLimitPrice,
+		},
+		conditional.Order{
+			Kind:         conditional.KindTakeProfit,
+			Market:       params.Market,
+			Volume:       params.Volume,
+			TriggerPrice: params.TakeProfitPrice,
+			LimitPrice:   params.TakeProfitLimitPrice,
+		},
+	)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ConditionalOrdersResult{Orders: orders}, nil
+}
+
+func CreateTrailingStopOrder(ctx context.Context, req *mcp.CallToolRequest, params *CreateTrailingStopOrderRequest) (
+	*mcp.CallToolResult,
+	*ConditionalOrdersResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(conditionalEngineKey{}).(*conditional.Engine)
+	if !ok {
+		return nil, nil, missingDependency("conditional order engine")
+	}
+
+	orders, err := addConditionalOrders(ctx, req, engine, conditional.Order{
+		Kind:         conditional.KindTrailingStop,
+		Market:       params.Market,
+		Volume:       params.Volume,
+		TrailPercent: params.TrailPercent,
+		TrailAmount:  params.TrailAmount,
+	})
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ConditionalOrdersResult{Orders: orders}, nil
+}
+
+func GetConditionalOrders(ctx context.Context, req *mcp.CallToolRequest, params *GetConditionalOrdersRequest) (
+	*mcp.CallToolResult,
+	*ConditionalOrdersResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(conditionalEngineKey{}).(*conditional.Engine)
+	if !ok {
+		return nil, nil, missingDependency("conditional order engine")
+	}
+
+	return &res, &ConditionalOrdersResult{Orders: engine.List(params.Market, params.State)}, nil
+}
+
+func CancelConditionalOrder(ctx context.Context, req *mcp.CallToolRequest, params *CancelConditionalOrderRequest) (
+	*mcp.CallToolResult,
+	*ConditionalOrdersResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(conditionalEngineKey{}).(*conditional.Engine)
+	if !ok {
+		return nil, nil, missingDependency("conditional order engine")
+	}
+
+	canceled, err := engine.Cancel(params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ConditionalOrdersResult{Orders: canceled}, nil
+}
+
+// addConditionalOrders 조건을 만족했을 때 낼 매도 주문마다 사용자 확인을 받은 뒤 조건부 주문을 등록한다.
+// 실행 시점에는 확인할 사용자가 없으므로 등록할 때 확인한다.
+func addConditionalOrders(ctx context.Context, req *mcp.CallToolRequest, engine *conditional.Engine, orders ...conditional.Order) ([]conditional.Order, error) {
+	for _, o := range orders {
+		params := upbit.RequestParams{
+			Market:  o.Market,
+			Side:    "ask",
+			OrdType: upbit.OrdTypeMarket,
+			Volume:  o.Volume,
+		}
+		if o.LimitPrice != "" {
+			params.OrdType = upbit.OrdTypeLimit
+			params.Price = o.LimitPrice
+		}
+		if err := confirmOrder(ctx, req, params); err != nil {
+			return nil, err
+		}
+	}
+	return engine.Add(ctx, orders...)
+}
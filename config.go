@@ -0,0 +1,332 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"upbit-mcp-server/conditional"
+	"upbit-mcp-server/dca"
+	"upbit-mcp-server/execution"
+	"upbit-mcp-server/grid"
+	"upbit-mcp-server/iceberg"
+	"upbit-mcp-server/journal"
+	"upbit-mcp-server/paper"
+	"upbit-mcp-server/risk"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+)
+
+// config 서버 실행 설정. 플래그가 환경 변수보다 우선한다.
+type config struct {
+	AccessKey string
+	SecretKey string
+	BaseURL   string
+	ProxyURL  string
+	Timeout   time.Duration
+	UserAgent string
+	Attempts  int
+
+	// Paper 모의 거래 모드. 주문은 업비트로 보내지 않고 가상 원장에서 체결한다.
+	Paper        bool
+	PaperBalance string
+
+	// StateDir 재시작 후에도 유지할 상태(일일 거래 금액 등)를 저장하는 디렉터리
+	StateDir string
+
+	// 위험 관리 한도. 0이나 빈 값은 제한하지 않는다.
+	MaxOrderKRW    string
+	MaxDailyKRW    string
+	MaxPositionPct float64
+	AllowedMarkets string
+	DeniedMarkets  string
+	MaxOpenOrders  int
+
+	// ConfirmOrders 주문 전에 MCP elicitation으로 사용자 확인을 받는다
+	ConfirmOrders  bool
+	ConfirmAbove   string
+	ConfirmTimeout time.Duration
+
+	// WatchInterval 조건부 주문의 조건을 확인하는 간격
+	WatchInterval time.Duration
+	// TrailInterval 추적 손절 마켓의 최고가를 갱신하는 간격
+	TrailInterval time.Duration
+}
+
+func loadConfig() (*config, error) {
+	cfg := &config{
+		AccessKey: os.Getenv("UPBIT_ACCESS_KEY"),
+		SecretKey: os.Getenv("UPBIT_SECRET_KEY"),
+	}
+
+	timeout, err := envDuration("UPBIT_TIMEOUT", upbit.DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	confirmTimeout, err := envDuration("UPBIT_CONFIRM_TIMEOUT", DefaultConfirmTimeout)
+	if err != nil {
+		return nil, err
+	}
+	watchInterval, err := envDuration("UPBIT_WATCH_INTERVAL", conditional.DefaultInterval)
+	if err != nil {
+		return nil, err
+	}
+	trailInterval, err := envDuration("UPBIT_TRAIL_INTERVAL", conditional.DefaultTrailInterval)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := envInt("UPBIT_MAX_ATTEMPTS", upbit.DefaultRetryPolicy().MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	maxPositionPct, err := envFloat("UPBIT_MAX_POSITION_PCT", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxOpenOrders, err := envInt("UPBIT_MAX_OPEN_ORDERS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	flag.StringVar(&cfg.BaseURL, "base-url", envOr("UPBIT_BASE_URL", upbit.BaseURL), "Upbit API base URL")
+	flag.StringVar(&cfg.ProxyURL, "proxy", os.Getenv("UPBIT_PROXY_URL"), "HTTP proxy URL used for Upbit API requests")
+	flag.DurationVar(&cfg.Timeout, "timeout", timeout, "Timeout for a single Upbit API request")
+	flag.StringVar(&cfg.UserAgent, "user-agent", os.Getenv("UPBIT_USER_AGENT"), "User-Agent header sent to the Upbit API")
+	flag.IntVar(&cfg.Attempts, "max-attempts", attempts, "Maximum attempts for retryable Upbit API requests (1 disables retries)")
+	flag.BoolVar(&cfg.Paper, "paper", envBool("UPBIT_PAPER"), "Simulate orders against live market data without sending them to Upbit")
+	flag.StringVar(&cfg.PaperBalance, "paper-balance", envOr("UPBIT_PAPER_BALANCE", string(paper.DefaultBalance)), "Initial KRW balance of the paper trading account")
+	flag.StringVar(&cfg.StateDir, "state-dir", envOr("UPBIT_STATE_DIR", defaultStateDir()), "Directory where persistent server state is stored")
+	flag.StringVar(&cfg.MaxOrderKRW, "max-order-krw", os.Getenv("UPBIT_MAX_ORDER_KRW"), "Maximum amount of a single order in KRW (0 disables)")
+	flag.StringVar(&cfg.MaxDailyKRW, "max-daily-krw", os.Getenv("UPBIT_MAX_DAILY_KRW"), "Maximum amount ordered per day (KST) in KRW (0 disables)")
+	flag.Float64Var(&cfg.MaxPositionPct, "max-position-pct", maxPositionPct, "Maximum share of the portfolio a single asset may reach after a buy, in percent (0 disables)")
+	flag.StringVar(&cfg.AllowedMarkets, "allowed-markets", os.Getenv("UPBIT_ALLOWED_MARKETS"), "Comma separated markets that may be traded (empty allows all)")
+	flag.StringVar(&cfg.DeniedMarkets, "denied-markets", os.Getenv("UPBIT_DENIED_MARKETS"), "Comma separated markets that may not be traded")
+	flag.IntVar(&cfg.MaxOpenOrders, "max-open-orders", maxOpenOrders, "Maximum number of open orders (0 disables)")
+	flag.BoolVar(&cfg.ConfirmOrders, "confirm-orders", envBool("UPBIT_CONFIRM_ORDERS"), "Ask the client user to confirm market orders and large limit orders before placing them")
+	flag.StringVar(&cfg.ConfirmAbove, "confirm-above", envOr("UPBIT_CONFIRM_ABOVE", "0"), "Limit orders whose total in the quote currency exceeds this amount require confirmation")
+	flag.DurationVar(&cfg.ConfirmTimeout, "confirm-timeout", confirmTimeout, "How long to wait for the user to confirm an order before rejecting it")
+	flag.DurationVar(&cfg.WatchInterval, "watch-interval", watchInterval, "How often the current price is checked for conditional (stop-loss, take-profit) orders")
+	flag.DurationVar(&cfg.TrailInterval, "trail-interval", trailInterval, "How often each market with trailing stops is polled to track the highest price")
+	flag.Parse()
+
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("UPBIT_ACCESS_KEY and UPBIT_SECRET_KEY must be set")
+	}
+	if cfg.Attempts < 0 {
+		return nil, fmt.Errorf("invalid max attempts %d: must not be negative", cfg.Attempts)
+	}
+	if cfg.MaxPositionPct < 0 {
+		return nil, fmt.Errorf("invalid max position percent %v: must not be negative", cfg.MaxPositionPct)
+	}
+	if cfg.MaxOpenOrders < 0 {
+		return nil, fmt.Errorf("invalid max open orders %d: must not be negative", cfg.MaxOpenOrders)
+	}
+	if _, err := upbit.ParseDecimal(cfg.PaperBalance); err != nil {
+		return nil, fmt.Errorf("invalid paper balance: %w", err)
+	}
+	if _, err := upbit.ParseDecimal(cfg.MaxOrderKRW); err != nil {
+		return nil, fmt.Errorf("invalid max order amount: %w", err)
+	}
+	if _, err := upbit.ParseDecimal(cfg.MaxDailyKRW); err != nil {
+		return nil, fmt.Errorf("invalid max daily amount: %w", err)
+	}
+	if _, err := upbit.ParseDecimal(cfg.ConfirmAbove); err != nil {
+		return nil, fmt.Errorf("invalid confirmation threshold: %w", err)
+	}
+	if cfg.StateDir == "" {
+		return nil, fmt.Errorf("state directory could not be determined, set UPBIT_STATE_DIR")
+	}
+	return cfg, nil
+}
+
+// openStore 상태 저장소. 모의 거래 모드는 실제 계좌의 상태와 섞이지 않도록 하위 디렉터리를 사용한다.
+func (cfg *config) openStore() (*store.Store, error) {
+	st, err := store.Open(cfg.StateDir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Paper {
+		return st.Sub("paper")
+	}
+	return st, nil
+}
+
+// trader 주문 도구가 사용할 Trader. 모의 거래 모드이면 client의 시세로 체결하는 가상 거래소를 사용하고,
+// 어느 쪽이든 위험 관리 한도를 거치고, 모든 주문에 identifier를 붙여 기록한다.
+func (cfg *config) trader(client *upbit.Client, st *store.Store) (upbit.Trader, error) {
+	var trader upbit.Trader = client
+	// 모의 거래 주문은 재시작하면 사라지므로 identifier 기록도 저장하지 않는다
+	journalStore := st
+	if cfg.Paper {
+		trader = paper.NewExchange(client, map[string]upbit.Decimal{
+			"KRW": upbit.MustParseDecimal(cfg.PaperBalance),
+		})
+		journalStore = nil
+	}
+
+	guard, err := risk.NewGuard(trader, client, cfg.riskLimits(), st)
+	if err != nil {
+		return nil, err
+	}
+	// 같은 identifier로 다시 보낸 주문은 한도 확인 전에 기존 주문으로 응답한다
+	return journal.New(guard, journalStore)
+}
+
+// conditionalEngine 조건부 주문 엔진. 조건을 만족하면 trader로 주문하므로 위험 관리 한도와 identifier 기록을 거친다.
+func (cfg *config) conditionalEngine(trader upbit.Trader, client *upbit.Client, st *store.Store) (*conditional.Engine, error) {
+	// 모의 거래 잔고는 재시작하면 사라지므로 조건부 주문도 저장하지 않는다
+	if cfg.Paper {
+		st = nil
+	}
+	return conditional.NewEngine(trader, client, st, cfg.WatchInterval, cfg.TrailInterval)
+}
+
+// dcaScheduler 적립식 매수 스케줄러. 조건부 주문과 같이 trader로 주문한다.
+func (cfg *config) dcaScheduler(trader upbit.Trader, client *upbit.Client, st *store.Store) (*dca.Scheduler, error) {
+	if cfg.Paper {
+		st = nil
+	}
+	return dca.NewScheduler(trader, client, st, dca.DefaultInterval)
+}
+
+// gridEngine 그리드 엔진. 그리드 주문도 trader로 보낸다.
+func (cfg *config) gridEngine(trader upbit.Trader, client *upbit.Client, st *store.Store) (*grid.Engine, error) {
+	if cfg.Paper {
+		st = nil
+	}
+	return grid.NewEngine(trader, client, st, grid.DefaultInterval)
+}
+
+// executionEngine TWAP/VWAP 실행 엔진. 나눈 주문도 trader로 보낸다.
+func (cfg *config) executionEngine(trader upbit.Trader, client *upbit.Client, st *store.Store) (*execution.Engine, error) {
+	if cfg.Paper {
+		st = nil
+	}
+	return execution.NewEngine(trader, client, st, execution.DefaultInterval)
+}
+
+// icebergEngine 빙산 주문 엔진. 보이는 주문도 trader로 보낸다.
+func (cfg *config) icebergEngine(trader upbit.Trader, st *store.Store) (*iceberg.Engine, error) {
+	if cfg.Paper {
+		st = nil
+	}
+	return iceberg.NewEngine(trader, st, iceberg.DefaultInterval)
+}
+
+func (cfg *config) riskLimits() risk.Limits {
+	return risk.Limits{
+		MaxOrderKRW:    upbit.MustParseDecimal(cfg.MaxOrderKRW),
+		MaxDailyKRW:    upbit.MustParseDecimal(cfg.MaxDailyKRW),
+		MaxPositionPct: cfg.MaxPositionPct,
+		AllowedMarkets: splitList(cfg.AllowedMarkets),
+		DeniedMarkets:  splitList(cfg.DeniedMarkets),
+		MaxOpenOrders:  cfg.MaxOpenOrders,
+	}
+}
+
+// confirmPolicy 주문 확인 정책. 확인을 사용하지 않으면 nil
+func (cfg *config) confirmPolicy() *confirmPolicy {
+	if !cfg.ConfirmOrders {
+		return nil
+	}
+	return &confirmPolicy{
+		LimitAbove: upbit.MustParseDecimal(cfg.ConfirmAbove),
+		Timeout:    cfg.ConfirmTimeout,
+	}
+}
+
+// clientOptions 설정값을 upbit.Client 옵션으로 변환
+func (cfg *config) clientOptions() ([]upbit.Option, error) {
+	opts := []upbit.Option{
+		upbit.WithBaseURL(cfg.BaseURL),
+		upbit.WithTimeout(cfg.Timeout),
+		upbit.WithUserAgent(cfg.UserAgent),
+	}
+
+	retryPolicy := upbit.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.Attempts
+	opts = append(opts, upbit.WithRetryPolicy(retryPolicy))
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		opts = append(opts, upbit.WithTransport(transport))
+	}
+
+	return opts, nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envInt(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func envBool(key string) bool {
+	b, _ := strconv.ParseBool(os.Getenv(key))
+	return b
+}
+
+func envFloat(key string, fallback float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func defaultStateDir() string {
+	dir, err := store.DefaultDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// splitList 쉼표로 구분된 목록을 나눈다
+func splitList(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestEnvNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		n       int
+		f       float64
+		wantErr bool
+	}{
+		{name: "unset uses the default", value: "", n: 3, f: 1.5},
+		{name: "number", value: "10", n: 10, f: 10},
+		// 잘못 쓴 한도가 조용히 기본값(제한 없음)이 되지 않는다
+		{name: "percent sign", value: "10%", wantErr: true},
+		{name: "not a number", value: "ten", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UPBIT_TEST_NUMBER", tt.value)
+			n, err := envInt("UPBIT_TEST_NUMBER", 3)
+			if (err != nil) != tt.wantErr || n != tt.n {
+				t.Errorf("envInt = %d, %v", n, err)
+			}
+			f, err := envFloat("UPBIT_TEST_NUMBER", 1.5)
+			if (err != nil) != tt.wantErr || f != tt.f {
+				t.Errorf("envFloat = %v, %v", f, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultConfirmTimeout 주문 확인 응답을 기다리는 기본 시간
+const DefaultConfirmTimeout = time.Minute
+
+// confirmPolicyKey는 context 내에서 주문 확인 정책을 식별하기 위한 키
+type confirmPolicyKey struct{}
+
+// confirmPolicy 주문 전에 MCP elicitation으로 사용자 확인을 받을 조건.
+// 시장가 주문은 항상, 지정가 주문은 주문 총액(결제 화폐 기준)이 LimitAbove를 넘을 때 확인한다.
+type confirmPolicy struct {
+	LimitAbove upbit.Decimal
+	Timeout    time.Duration
+}
+
+// confirmSchema 사용자에게 요청하는 입력. 체크 하나로 주문 여부만 받는다
+var confirmSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"confirm": map[string]any{
+			"type":        "boolean",
+			"title":       "Place this order",
+			"description": "Check to send the order to Upbit",
+		},
+	},
+	"required": []string{"confirm"},
+}
+
+// needsConfirm 주문이 사용자 확인 대상인지 여부
+func (p *confirmPolicy) needsConfirm(params upbit.RequestParams) bool {
+	if params.OrdType != "limit" {
+		return true
+	}
+	return params.Price.Mul(params.Volume).GreaterThan(p.LimitAbove)
+}
+
+// confirmOrder 정책에 해당하는 주문이면 연결된 클라이언트의 사용자에게 주문 내용을 보여주고 확인을 받는다.
+// 거절, 취소, 시간 초과, elicitation 미지원은 모두 주문하지 않는 것으로 처리한다.
+func confirmOrder(ctx context.Context, req *mcp.CallToolRequest, params upbit.RequestParams) error {
+	policy, ok := ctx.Value(confirmPolicyKey{}).(*confirmPolicy)
+	if !ok || !policy.needsConfirm(params) {
+		return nil
+	}
+
+	message, err := describeOrder(ctx, params)
+	if err != nil {
+		return err
+	}
+	return elicitConfirmation(ctx, req, policy, message)
+}
+
+// elicitConfirmation 사용자에게 message를 보여주고 확인을 받는다
+func elicitConfirmation(ctx context.Context, req *mcp.CallToolRequest, policy *confirmPolicy, message string) error {
+	confirmCtx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+
+	res, err := req.Session.Elicit(confirmCtx, &mcp.ElicitParams{
+		Message:         message,
+		RequestedSchema: confirmSchema,
+	})
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case confirmCtx.Err() != nil:
+			return notConfirmed("confirmation_timeout", "the user did not confirm the order within %s", policy.Timeout)
+		}
+		return notConfirmed("confirmation_unavailable", "could not ask the user to confirm the order: %v", err)
+	}
+
+	if res.Action != "accept" {
+		return notConfirmed("order_not_confirmed", "the user did not confirm the order (action: %s)", res.Action)
+	}
+	if confirmed, _ := res.Content["confirm"].(bool); !confirmed {
+		return notConfirmed("order_not_confirmed", "the user did not check the confirmation")
+	}
+	return nil
+}
+
+// describeOrder 확인 창에 보여줄 주문 내용. 수수료를 포함한 예상 금액을 함께 보여준다
+func describeOrder(ctx context.Context, params upbit.RequestParams) (string, error) {
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return "", missingDependency("Upbit trader")
+	}
+	chance, err := trader.GetChance(ctx, params.Market)
+	if err != nil {
+		return "", err
+	}
+
+	quote, base, _ := strings.Cut(params.Market, "-")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Confirm Upbit order\n\nmarket: %s\n", params.Market)
+	kind := orderKind(params)
+	switch {
+	case params.OrdType == upbit.OrdTypeLimit:
+		side, feeRate := "buy", chance.BidFee
+		if params.Side == "ask" {
+			side, feeRate = "sell", chance.AskFee
+		}
+		total := params.Price.Mul(params.Volume)
+		fee := total.Mul(feeRate)
+		fmt.Fprintf(&b, "side: %s (%s)\nprice: %s %s\nvolume: %s %s\n", side, kind, params.Price, quote, params.Volume, base)
+		if params.Side == "bid" {
+			fmt.Fprintf(&b, "estimated cost: %s %s (fee %s included)\n", total.Add(fee), quote, fee)
+		} else {
+			fmt.Fprintf(&b, "estimated proceeds: %s %s (fee %s deducted)\n", total.Sub(fee), quote, fee)
+		}
+	case upbit.IsAmountOrder(params.OrdType, params.Side):
+		fee := params.Price.Mul(chance.BidFee)
+		fmt.Fprintf(&b, "side: buy (%s)\namount: %s %s\nestimated cost: %s %s (fee %s included)\n", kind, params.Price, quote, params.Price.Add(fee), quote, fee)
+	default:
+		fmt.Fprintf(&b, "side: sell (%s)\nvolume: %s %s\n", kind, params.Volume, base)
+		if client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client); ok {
+			if tickers, err := client.GetTicker(ctx, params.Market); err == nil && len(tickers) > 0 {
+				total := params.Volume.Mul(tickers[0].TradePrice)
+				fee := total.Mul(chance.AskFee)
+				fmt.Fprintf(&b, "estimated proceeds: %s %s at current price %s (fee %s deducted)\n", total.Sub(fee), quote, tickers[0].TradePrice, fee)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// orderKind 확인 창에 보여줄 주문 종류. e.g. market, limit, best ioc
+func orderKind(params upbit.RequestParams) string {
+	kind := params.OrdType
+	if kind == upbit.OrdTypePrice {
+		kind = upbit.OrdTypeMarket
+	}
+	if params.TimeInForce != "" {
+		kind += " " + params.TimeInForce
+	}
+	return kind
+}
+
+func notConfirmed(name, format string, args ...any) error {
+	return &toolErr{payload: toolErrorPayload{
+		Name:    name,
+		Message: fmt.Sprintf(format, args...),
+	}}
+}
@@ -0,0 +1,183 @@
+package dca
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 자주 쓰는 일정의 약칭
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// searchLimit 다음 실행 시각을 찾는 범위. 이 안에 실행 시각이 없으면 실행되지 않는 일정으로 본다
+const searchLimit = 5
+
+// Schedule cron 형식(분 시 일 월 요일)의 반복 일정
+type Schedule struct {
+	expr   string
+	minute field
+	hour   field
+	dom    field
+	month  field
+	dow    field
+	// 일과 요일이 모두 지정되면 둘 중 하나만 맞아도 실행한다 (cron과 같은 규칙).
+	// Vixie cron처럼 *로 시작하는 필드(*, */2)는 지정하지 않은 것으로 본다.
+	domAny bool
+	dowAny bool
+}
+
+// field 허용되는 값의 비트 집합
+type field uint64
+
+func (f field) has(n int) bool {
+	return f&(1<<uint(n)) != 0
+}
+
+// ParseSchedule cron 형식의 일정 파싱.
+// 다섯 필드(분 0-59, 시 0-23, 일 1-31, 월 1-12 또는 jan-dec, 요일 0-7 또는 sun-sat, 0과 7은 일요일)에
+// *, 목록(1,15), 범위(mon-fri), 간격(*/10, 9-18/3)을 사용할 수 있고 @daily, @weekly 같은 약칭도 지원한다.
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.ToLower(strings.TrimSpace(expr))
+	if m, ok := macros[spec]; ok {
+		spec = m
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+
+	s := &Schedule{expr: strings.TrimSpace(expr)}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: minute: %w", expr, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: hour: %w", expr, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of month: %w", expr, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: month: %w", expr, err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of week: %w", expr, err)
+	}
+	// 7도 일요일
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// String 파싱한 원래 일정
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next after 이후(after는 제외)의 첫 실행 시각. after의 시간대를 기준으로 계산하며 실행 시각이 없으면 zero time을 반환한다.
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchLimit, 0, 0)
+	for t.Before(limit) {
+		if !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom, dow := s.dom.has(t.Day()), s.dow.has(int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseField 쉼표로 구분한 항목(*, n, a-b, 뒤에 /step)을 비트 집합으로 변환
+func parseField(spec string, min, max int, names []string) (field, error) {
+	var f field
+	for _, item := range strings.Split(spec, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseValue(loStr, min, max, names); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = parseValue(hiStr, min, max, names); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("invalid range %q", rng)
+				}
+			case !hasStep:
+				// 간격 없는 단일 값. 간격이 있으면 n/step은 n부터 최댓값까지
+				hi = lo
+			}
+		}
+		for n := lo; n <= hi; n += step {
+			f |= 1 << uint(n)
+		}
+	}
+	return f, nil
+}
+
+func parseValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if s == name {
+			return i + min, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, min, max)
+	}
+	return n, nil
+}
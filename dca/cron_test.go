@@ -0,0 +1,121 @@
+package dca
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	// 2026-01-01은 목요일
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{expr: "*/15 * * * *", after: at(2026, 1, 1, 10, 7), want: at(2026, 1, 1, 10, 15)},
+		// after 자체는 포함하지 않는다
+		{expr: "*/15 * * * *", after: at(2026, 1, 1, 10, 15), want: at(2026, 1, 1, 10, 30)},
+		{expr: "0 9 * * *", after: at(2026, 1, 1, 10, 7), want: at(2026, 1, 2, 9, 0)},
+		{expr: "30 9-18/3 * * *", after: at(2026, 1, 1, 10, 0), want: at(2026, 1, 1, 12, 30)},
+		{expr: "0 9 * * mon-fri", after: at(2026, 1, 2, 10, 0), want: at(2026, 1, 5, 9, 0)},
+		{expr: "0 0 * * 7", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 4, 0, 0)},
+		{expr: "0 0 * * sun", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 4, 0, 0)},
+		{expr: "0 0 1,15 * *", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 15, 0, 0)},
+		{expr: "0 0 1 jan *", after: at(2026, 1, 1, 0, 0), want: at(2027, 1, 1, 0, 0)},
+		{expr: "0 0 29 2 *", after: at(2026, 1, 1, 0, 0), want: at(2028, 2, 29, 0, 0)},
+		{expr: "@monthly", after: at(2026, 1, 15, 0, 0), want: at(2026, 2, 1, 0, 0)},
+		{expr: "@weekly", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 4, 0, 0)},
+		{expr: "@hourly", after: at(2026, 1, 1, 23, 30), want: at(2026, 1, 2, 0, 0)},
+		// 일과 요일이 모두 지정되면 둘 중 하나만 맞아도 된다: 13일 또는 금요일
+		{expr: "0 0 13 * fri", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 2, 0, 0)},
+		// *로 시작하는 필드는 지정하지 않은 것으로 보므로 둘 다 맞아야 한다: 홀수 날이면서 월요일
+		{expr: "0 0 */2 * mon", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 5, 0, 0)},
+		{expr: "0 0 1 * */2", after: at(2026, 1, 1, 0, 0), want: at(2026, 2, 1, 0, 0)},
+		// 존재하지 않는 날짜는 실행되지 않는다
+		{expr: "0 0 31 2 *", after: at(2026, 1, 1, 0, 0), want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextKeepsLocation(t *testing.T) {
+	kst := time.FixedZone("KST", 9*60*60)
+	s, err := ParseSchedule("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.Next(time.Date(2026, 1, 1, 8, 59, 30, 0, kst))
+	if want := time.Date(2026, 1, 1, 9, 0, 0, 0, kst); !got.Equal(want) || got.Location() != kst {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@reboot",
+	}
+	for _, expr := range tests {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestParseField(t *testing.T) {
+	bits := func(ns ...int) field {
+		var f field
+		for _, n := range ns {
+			f |= 1 << uint(n)
+		}
+		return f
+	}
+
+	tests := []struct {
+		spec  string
+		min   int
+		max   int
+		names []string
+		want  field
+	}{
+		{spec: "*", min: 1, max: 5, want: bits(1, 2, 3, 4, 5)},
+		{spec: "*/2", min: 0, max: 6, want: bits(0, 2, 4, 6)},
+		{spec: "3", min: 0, max: 59, want: bits(3)},
+		{spec: "3/20", min: 0, max: 59, want: bits(3, 23, 43)},
+		{spec: "1-3,10", min: 0, max: 59, want: bits(1, 2, 3, 10)},
+		{spec: "10-20/5", min: 0, max: 59, want: bits(10, 15, 20)},
+		{spec: "jan,mar-apr", min: 1, max: 12, names: monthNames, want: bits(1, 3, 4)},
+		{spec: "mon-fri", min: 0, max: 7, names: dayNames, want: bits(1, 2, 3, 4, 5)},
+	}
+	for _, tt := range tests {
+		got, err := parseField(tt.spec, tt.min, tt.max, tt.names)
+		if err != nil || got != tt.want {
+			t.Errorf("parseField(%q) = %b, %v, want %b", tt.spec, got, err, tt.want)
+		}
+	}
+}
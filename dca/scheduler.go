@@ -0,0 +1,378 @@
+// Package dca 정해진 일정마다 일정 금액을 시장가로 매수하는 적립식 매수(Dollar-Cost Averaging) 계획을 실행한다.
+package dca
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/engine"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// DefaultInterval 실행할 계획이 있는지 확인하는 기본 주기. 일정은 분 단위이므로 1분보다 짧아야 한다
+const DefaultInterval = 10 * time.Second
+
+// MissedAfter 예정 시각보다 이만큼 늦으면(서버가 꺼져 있던 경우 등) 매수하지 않고 놓친 것으로 기록한다
+const MissedAfter = time.Hour
+
+// IdentifierPrefix 계획이 실행될 때 낸 주문의 identifier 접두사. 뒤에 계획 ID와 예정 시각이 붙는다
+const IdentifierPrefix = "dca-"
+
+// maxHistory 보관하는 실행 기록 수. 넘으면 오래된 기록부터 지운다
+const maxHistory = 1000
+
+// 저장 파일 이름
+const (
+	plansName   = "dca"
+	historyName = "dca_history"
+)
+
+// Location 일정을 해석하는 시간대 (KST)
+var Location = time.FixedZone("KST", 9*60*60)
+
+// 실행 결과
+const (
+	StatusExecuted = "executed" // 매수 주문함
+	StatusSkipped  = "skipped"  // 현재가가 상한가보다 높아 매수하지 않음
+	StatusFailed   = "failed"   // 거래소가 주문을 거절함
+	StatusMissed   = "missed"   // 예정 시각을 MissedAfter 이상 지나 매수하지 않음
+)
+
+// 계획을 등록하거나 변경하지 못한 이유
+const (
+	ErrInvalidPlan  = "invalid_dca_plan"
+	ErrPlanNotFound = "dca_plan_not_found"
+)
+
+// Error 적립식 매수 계획 요청이 거절된 이유
+type Error = engine.Error
+
+func newError(name, format string, args ...any) error {
+	return engine.NewError("dca plan", name, format, args...)
+}
+
+// Plan 적립식 매수 계획
+type Plan struct {
+	ID           string        `json:"id" jsonschema:"Recurring buy plan ID."`
+	Market       string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Amount       upbit.Decimal `json:"amount" jsonschema:"Amount in the quote currency bought at market price on every run."`
+	Schedule     string        `json:"schedule" jsonschema:"Cron schedule (minute hour day-of-month month day-of-week) in KST."`
+	PriceCeiling upbit.Decimal `json:"price_ceiling,omitempty" jsonschema:"Runs are skipped while the current price is above this price."`
+	Paused       bool          `json:"paused" jsonschema:"Paused plans are not executed until resumed."`
+	NextRun      *time.Time    `json:"next_run,omitempty" jsonschema:"Next scheduled run. Absent while paused."`
+	LastRun      *time.Time    `json:"last_run,omitempty" jsonschema:"Scheduled time of the last run."`
+	LastStatus   string        `json:"last_status,omitempty" jsonschema:"Result of the last run: executed, skipped, failed or missed."`
+	Note         string        `json:"note,omitempty" jsonschema:"Why the scheduler paused the plan."`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// Execution 계획의 실행 기록
+type Execution struct {
+	PlanID       string        `json:"plan_id"`
+	Market       string        `json:"market"`
+	Amount       upbit.Decimal `json:"amount"`
+	ScheduledAt  time.Time     `json:"scheduled_at" jsonschema:"Scheduled time of the run."`
+	ExecutedAt   time.Time     `json:"executed_at" jsonschema:"Time the run was processed."`
+	Status       string        `json:"status" jsonschema:"executed, skipped, failed or missed."`
+	CurrentPrice upbit.Decimal `json:"current_price,omitempty" jsonschema:"Current price checked against the price ceiling."`
+	OrderUuid    string        `json:"order_uuid,omitempty" jsonschema:"UUID of the market buy order."`
+	Note         string        `json:"note,omitempty" jsonschema:"Why the run was skipped, failed or missed."`
+}
+
+// Market 상한가 확인에 사용하는 시세 조회 기능
+type Market interface {
+	GetTicker(ctx context.Context, symbol string) ([]upbit.Ticker, error)
+}
+
+// Scheduler 적립식 매수 계획을 보관하고 예정 시각이 되면 trader로 시장가 매수한다
+type Scheduler struct {
+	trader upbit.Trader
+	market Market
+	base   engine.Base
+
+	// checkMu 계획 실행이 겹쳐 같은 회차를 두 번 매수하지 않도록 한다
+	checkMu sync.Mutex
+
+	// mu 계획과 실행 기록을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu        sync.Mutex
+	plans     []*Plan
+	schedules map[string]*Schedule
+	history   []Execution
+}
+
+// NewScheduler 적립식 매수 스케줄러 생성. st가 nil이면 계획과 실행 기록을 메모리에만 보관한다.
+func NewScheduler(trader upbit.Trader, market Market, st *store.Store, interval time.Duration) (*Scheduler, error) {
+	s := &Scheduler{
+		trader:    trader,
+		market:    market,
+		base:      engine.NewBase(st, interval, DefaultInterval),
+		schedules: map[string]*Schedule{},
+	}
+	if err := s.base.Load(plansName, &s.plans); err != nil {
+		return nil, err
+	}
+	if err := s.base.Load(historyName, &s.history); err != nil {
+		return nil, err
+	}
+	for _, p := range s.plans {
+		sched, err := ParseSchedule(p.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("dca plan %s: %w", p.ID, err)
+		}
+		s.schedules[p.ID] = sched
+	}
+	return s, nil
+}
+
+// Add 계획을 등록하고 다음 실행 시각을 정한다
+func (s *Scheduler) Add(p Plan) (Plan, error) {
+	if p.Market == "" {
+		return Plan{}, newError(ErrInvalidPlan, "market is required")
+	}
+	if p.Amount.Sign() <= 0 {
+		return Plan{}, newError(ErrInvalidPlan, "amount must be positive")
+	}
+	if p.PriceCeiling.Sign() < 0 {
+		return Plan{}, newError(ErrInvalidPlan, "price_ceiling must not be negative")
+	}
+	sched, err := ParseSchedule(p.Schedule)
+	if err != nil {
+		return Plan{}, newError(ErrInvalidPlan, "%v", err)
+	}
+	now := time.Now().In(Location)
+	next := sched.Next(now)
+	if next.IsZero() {
+		return Plan{}, newError(ErrInvalidPlan, "schedule %q never runs", p.Schedule)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.ID = uuid.NewString()
+	p.Paused = false
+	p.NextRun = &next
+	p.LastRun = nil
+	p.LastStatus = ""
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	s.plans = append(s.plans, &p)
+	s.schedules[p.ID] = sched
+	if err := s.save(); err != nil {
+		return Plan{}, err
+	}
+	return p, nil
+}
+
+// List 등록된 계획 목록
+func (s *Scheduler) List() []Plan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]Plan, 0, len(s.plans))
+	for _, p := range s.plans {
+		res = append(res, *p)
+	}
+	return res
+}
+
+// SetPaused 계획을 일시 중지하거나 다시 시작한다. 다시 시작하면 지금 이후의 예정 시각부터 실행한다
+func (s *Scheduler) SetPaused(id string, paused bool) (Plan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.find(id)
+	if p == nil {
+		return Plan{}, newError(ErrPlanNotFound, "dca plan %s not found", id)
+	}
+	now := time.Now().In(Location)
+	p.Paused = paused
+	p.NextRun = nil
+	p.Note = ""
+	if !paused {
+		p.reschedule(s.schedules[id], now)
+	}
+	p.UpdatedAt = now
+	if err := s.save(); err != nil {
+		return Plan{}, err
+	}
+	return *p, nil
+}
+
+// reschedule now 이후의 다음 실행 시각을 정한다. 더 이상 실행 시각이 없으면 계획을 일시 중지하고 note에 이유를 남긴다
+func (p *Plan) reschedule(sched *Schedule, now time.Time) {
+	next := sched.Next(now)
+	if next.IsZero() {
+		p.Paused = true
+		p.NextRun = nil
+		p.Note = fmt.Sprintf("paused because schedule %q has no run after %s", p.Schedule, now.Format(time.RFC3339))
+		return
+	}
+	p.NextRun = &next
+}
+
+// Delete 계획을 삭제한다. 실행 기록은 남긴다
+func (s *Scheduler) Delete(id string) (Plan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.plans, func(p *Plan) bool { return p.ID == id })
+	if i < 0 {
+		return Plan{}, newError(ErrPlanNotFound, "dca plan %s not found", id)
+	}
+	p := s.plans[i]
+	s.plans = slices.Delete(s.plans, i, i+1)
+	delete(s.schedules, id)
+	if err := s.save(); err != nil {
+		return Plan{}, err
+	}
+	return *p, nil
+}
+
+// History 최근 실행 기록(최신순). planID가 비어 있으면 전체 계획, limit이 0이면 전부 반환한다
+func (s *Scheduler) History(planID string, limit int) []Execution {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := []Execution{}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if limit > 0 && len(res) >= limit {
+			break
+		}
+		if planID == "" || s.history[i].PlanID == planID {
+			res = append(res, s.history[i])
+		}
+	}
+	return res
+}
+
+// Run ctx가 끝날 때까지 주기적으로 예정 시각이 된 계획을 실행한다
+func (s *Scheduler) Run(ctx context.Context) {
+	s.base.Run(ctx, "dca plans", func(ctx context.Context) error {
+		return s.Check(ctx, time.Now())
+	})
+}
+
+// Check now 기준으로 예정 시각이 된 계획을 실행한다.
+// 잠금을 잡고 실행할 계획의 복사본을 만든 뒤 시세 조회와 주문은 잠금 밖에서 하고 결과만 다시 잠금을 잡고 반영한다.
+func (s *Scheduler) Check(ctx context.Context, now time.Time) error {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	now = now.In(Location)
+	s.mu.Lock()
+	var due []Plan
+	for _, p := range s.plans {
+		if !p.Paused && p.NextRun != nil && !p.NextRun.After(now) {
+			due = append(due, *p)
+		}
+	}
+	s.mu.Unlock()
+
+	var execs []Execution
+	for _, p := range due {
+		if exec, done := s.execute(ctx, p, *p.NextRun, now); done {
+			execs = append(execs, exec)
+		}
+	}
+	if len(execs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, exec := range execs {
+		s.history = append(s.history, exec)
+		p := s.find(exec.PlanID)
+		if p == nil {
+			continue
+		}
+		scheduled := exec.ScheduledAt
+		p.LastRun = &scheduled
+		p.LastStatus = exec.Status
+		p.UpdatedAt = now
+		// 실행하는 사이에 일시 중지되었거나 다시 시작된 계획은 그때 정한 다음 실행 시각을 그대로 둔다
+		if p.NextRun != nil && p.NextRun.Equal(scheduled) {
+			p.reschedule(s.schedules[p.ID], now)
+		}
+	}
+	if len(s.history) > maxHistory {
+		s.history = slices.Delete(s.history, 0, len(s.history)-maxHistory)
+	}
+	return s.save()
+}
+
+// execute 예정된 한 번의 매수를 실행한다. 일시적인 오류로 주문하지 못하면 done이 false이고 다음 확인에서 다시 시도한다.
+// identifier가 계획과 예정 시각으로 정해지므로 응답을 받지 못한 주문을 다시 보내도 중복 매수되지 않는다.
+func (s *Scheduler) execute(ctx context.Context, p Plan, scheduled, now time.Time) (exec Execution, done bool) {
+	exec = Execution{
+		PlanID:      p.ID,
+		Market:      p.Market,
+		Amount:      p.Amount,
+		ScheduledAt: scheduled,
+		ExecutedAt:  now,
+	}
+	if now.Sub(scheduled) > MissedAfter {
+		exec.Status = StatusMissed
+		exec.Note = fmt.Sprintf("the run was %s late", now.Sub(scheduled).Truncate(time.Minute))
+		return exec, true
+	}
+
+	if p.PriceCeiling.Sign() > 0 {
+		tickers, err := s.market.GetTicker(ctx, p.Market)
+		if err != nil || len(tickers) == 0 {
+			log.Printf("dca plan %s: could not get the current price of %s, retrying: %v", p.ID, p.Market, err)
+			return exec, false
+		}
+		exec.CurrentPrice = tickers[0].TradePrice
+		if exec.CurrentPrice.GreaterThan(p.PriceCeiling) {
+			exec.Status = StatusSkipped
+			exec.Note = fmt.Sprintf("current price %s is above the price ceiling %s", exec.CurrentPrice, p.PriceCeiling)
+			return exec, true
+		}
+	}
+
+	order, err := s.trader.PlaceOrder(ctx, upbit.RequestParams{
+		Market:     p.Market,
+		Side:       "bid",
+		OrdType:    upbit.OrdTypePrice,
+		Price:      p.Amount,
+		SmpType:    upbit.SmpCancelMaker,
+		Identifier: IdentifierPrefix + p.ID + "-" + scheduled.Format("200601021504"),
+	})
+	if err != nil {
+		if upbit.IsTemporary(err) {
+			log.Printf("dca plan %s: the order could not be placed, retrying: %v", p.ID, err)
+			return exec, false
+		}
+		exec.Status = StatusFailed
+		exec.Note = err.Error()
+		return exec, true
+	}
+	exec.Status = StatusExecuted
+	exec.OrderUuid = order.Uuid
+	return exec, true
+}
+
+func (s *Scheduler) find(id string) *Plan {
+	for _, p := range s.plans {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) save() error {
+	if err := s.base.Save(plansName, s.plans); err != nil {
+		return err
+	}
+	return s.base.Save(historyName, s.history)
+}
@@ -0,0 +1,143 @@
+package dca_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"upbit-mcp-server/dca"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+// identifiers 주문할 때 보낸 identifier를 기록하는 Trader
+type identifiers struct {
+	upbit.Trader
+	sent []string
+}
+
+func (r *identifiers) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	r.sent = append(r.sent, params.Identifier)
+	return r.Trader.PlaceOrder(ctx, params)
+}
+
+// newScheduler 현재가 1억 원인 거래소에서 매일 9시에 10,000원어치 매수하는 계획을 등록한다.
+// 일시적인 오류를 스케줄러가 다시 시도하는지 보기 위해 클라이언트는 재시도하지 않는다.
+func newScheduler(t *testing.T, ceiling upbit.Decimal) (*upbittest.Exchange, *identifiers, *dca.Scheduler, dca.Plan) {
+	t.Helper()
+
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	client := ex.Client(upbit.WithRetryPolicy(upbit.RetryPolicy{MaxAttempts: 1}))
+	trader := &identifiers{Trader: client}
+	s, err := dca.NewScheduler(trader, client, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.Add(dca.Plan{Market: "KRW-BTC", Amount: "10000", Schedule: "0 9 * * *", PriceCeiling: ceiling})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ex, trader, s, p
+}
+
+func TestMissedRun(t *testing.T) {
+	ex, _, s, p := newScheduler(t, "")
+
+	// 서버가 꺼져 있어 예정 시각을 두 시간 넘긴 회차는 매수하지 않는다
+	now := p.NextRun.Add(2 * time.Hour)
+	if err := s.Check(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	history := s.History(p.ID, 0)
+	if len(history) != 1 || history[0].Status != dca.StatusMissed || !history[0].ScheduledAt.Equal(*p.NextRun) {
+		t.Fatalf("history = %+v", history)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 0 {
+		t.Errorf("orders placed = %d, want 0", n)
+	}
+	got := s.List()[0]
+	if got.LastStatus != dca.StatusMissed || !got.NextRun.After(now) {
+		t.Errorf("plan = %s, next run %s", got.LastStatus, got.NextRun)
+	}
+}
+
+func TestPriceCeilingSkip(t *testing.T) {
+	ex, _, s, p := newScheduler(t, "90000000")
+
+	if err := s.Check(context.Background(), *p.NextRun); err != nil {
+		t.Fatal(err)
+	}
+	history := s.History(p.ID, 0)
+	if len(history) != 1 || history[0].Status != dca.StatusSkipped || !history[0].CurrentPrice.Equal("100000000") {
+		t.Fatalf("history = %+v", history)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 0 {
+		t.Errorf("orders placed = %d, want 0", n)
+	}
+	if got := s.List()[0]; !got.NextRun.After(*p.NextRun) {
+		t.Errorf("next run = %s, want after %s", got.NextRun, p.NextRun)
+	}
+}
+
+func TestTemporaryErrorRetriesWithSameIdentifier(t *testing.T) {
+	ex, trader, s, p := newScheduler(t, "")
+	ctx := context.Background()
+
+	// 거래소 오류로 주문하지 못한 회차는 기록하지 않고 다음 확인에서 다시 시도한다
+	ex.FailNext(upbittest.Failure{Method: "POST", Path: "orders", Status: http.StatusInternalServerError, Name: upbit.ErrServerError})
+	if err := s.Check(ctx, *p.NextRun); err != nil {
+		t.Fatal(err)
+	}
+	if history := s.History(p.ID, 0); len(history) != 0 {
+		t.Fatalf("history after the failure = %+v", history)
+	}
+	if got := s.List()[0]; !got.NextRun.Equal(*p.NextRun) {
+		t.Fatalf("next run = %s, want %s", got.NextRun, p.NextRun)
+	}
+
+	if err := s.Check(ctx, p.NextRun.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	history := s.History(p.ID, 0)
+	if len(history) != 1 || history[0].Status != dca.StatusExecuted {
+		t.Fatalf("history = %+v", history)
+	}
+	want := dca.IdentifierPrefix + p.ID + "-" + p.NextRun.Format("200601021504")
+	if len(trader.sent) != 2 || trader.sent[0] != want || trader.sent[1] != want {
+		t.Errorf("identifiers = %v, want %s twice", trader.sent, want)
+	}
+	order, ok := ex.Order(history[0].OrderUuid)
+	if !ok || order.Identifier != want || order.State != upbit.OrderStateDone {
+		t.Errorf("order = %+v", order)
+	}
+}
+
+func TestScheduleWithoutNextRunPauses(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	s, err := dca.NewScheduler(ex.Client(), ex.Client(), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.Add(dca.Plan{Market: "KRW-BTC", Amount: "10000", Schedule: "0 9 29 2 *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2100년은 윤년이 아니므로 2097년 이후 5년 안에는 2월 29일이 없다
+	now := time.Date(2097, 1, 1, 0, 0, 0, 0, dca.Location)
+	if err := s.Check(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	got := s.List()[0]
+	if !got.Paused || got.NextRun != nil || got.Note == "" {
+		t.Fatalf("plan = paused %v, next run %v, note %q", got.Paused, got.NextRun, got.Note)
+	}
+
+	// 일시 중지된 계획은 다시 실행되지 않는다
+	if err := s.Check(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if history := s.History(p.ID, 0); len(history) != 1 || history[0].Status != dca.StatusMissed {
+		t.Errorf("history = %+v", history)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"upbit-mcp-server/dca"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dcaSchedulerKey는 context 내에서 적립식 매수 스케줄러를 식별하기 위한 키
+type dcaSchedulerKey struct{}
+
+type CreateRecurringBuyRequest struct {
+	Market       string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Amount       upbit.Decimal `json:"amount" jsonschema:"Amount in the quote currency to buy at market price on every run (e.g. 50000 KRW)."`
+	Schedule     string        `json:"schedule" jsonschema:"Cron schedule in KST with 5 fields: minute hour day-of-month month day-of-week. Supports *, lists (1,15), ranges (mon-fri), steps (*/10) and @daily, @weekly, @monthly. e.g. '0 9 * * mon' runs every Monday at 09:00 KST."`
+	PriceCeiling upbit.Decimal `json:"price_ceiling,omitempty" jsonschema:"Optional. Skip a run when the current price is above this price."`
+}
+
+type RecurringBuyIDRequest struct {
+	ID string `json:"id" jsonschema:"Recurring buy plan ID."`
+}
+
+type GetRecurringBuyHistoryRequest struct {
+	PlanID string `json:"plan_id,omitempty" jsonschema:"Optional. Only return runs of this plan."`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Optional. Maximum number of most recent runs to return. All stored runs are returned if omitted."`
+}
+
+type RecurringBuysResult struct {
+	Plans []dca.Plan `json:"plans"`
+}
+
+type RecurringBuyHistoryResult struct {
+	Executions []dca.Execution `json:"executions"`
+}
+
+func CreateRecurringBuy(ctx context.Context, req *mcp.CallToolRequest, params *CreateRecurringBuyRequest) (
+	*mcp.CallToolResult,
+	*dca.Plan,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	scheduler, ok := ctx.Value(dcaSchedulerKey{}).(*dca.Scheduler)
+	if !ok {
+		return nil, nil, missingDependency("recurring buy scheduler")
+	}
+
+	if _, err := dca.ParseSchedule(params.Schedule); err != nil {
+		return nil, nil, validationError("%v", err)
+	}
+
+	// 실행 시점에는 확인할 사용자가 없으므로 매번 낼 시장가 매수 주문을 등록할 때 확인한다
+	if err := confirmOrder(ctx, req, upbit.RequestParams{
+		Market:  params.Market,
+		Side:    "bid",
+		OrdType: upbit.OrdTypePrice,
+		Price:   params.Amount,
+	}); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	plan, err := scheduler.Add(dca.Plan{
+		Market:       params.Market,
+		Amount:       params.Amount,
+		Schedule:     params.Schedule,
+		PriceCeiling: params.PriceCeiling,
+	})
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &plan, nil
+}
+
+func GetRecurringBuys(ctx context.Context, req *mcp.CallToolRequest, params any) (
+	*mcp.CallToolResult,
+	*RecurringBuysResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	scheduler, ok := ctx.Value(dcaSchedulerKey{}).(*dca.Scheduler)
+	if !ok {
+		return nil, nil, missingDependency("recurring buy scheduler")
+	}
+
+	return &res, &RecurringBuysResult{Plans: scheduler.List()}, nil
+}
+
+func PauseRecurringBuy(ctx context.Context, req *mcp.CallToolRequest, params *RecurringBuyIDRequest) (
+	*mcp.CallToolResult,
+	*dca.Plan,
+	error,
+) {
+	return setRecurringBuyPaused(ctx, params.ID, true)
+}
+
+func ResumeRecurringBuy(ctx context.Context, req *mcp.CallToolRequest, params *RecurringBuyIDRequest) (
+	*mcp.CallToolResult,
+	*dca.Plan,
+	error,
+) {
+	return setRecurringBuyPaused(ctx, params.ID, false)
+}
+
+func DeleteRecurringBuy(ctx context.Context, req *mcp.CallToolRequest, params *RecurringBuyIDRequest) (
+	*mcp.CallToolResult,
+	*dca.Plan,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	scheduler, ok := ctx.Value(dcaSchedulerKey{}).(*dca.Scheduler)
+	if !ok {
+		return nil, nil, missingDependency("recurring buy scheduler")
+	}
+
+	plan, err := scheduler.Delete(params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &plan, nil
+}
+
+func GetRecurringBuyHistory(ctx context.Context, req *mcp.CallToolRequest, params *GetRecurringBuyHistoryRequest) (
+	*mcp.CallToolResult,
+	*RecurringBuyHistoryResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	scheduler, ok := ctx.Value(dcaSchedulerKey{}).(*dca.Scheduler)
+	if !ok {
+		return nil, nil, missingDependency("recurring buy scheduler")
+	}
+	if params.Limit < 0 {
+		return nil, nil, validationError("limit must not be negative")
+	}
+
+	return &res, &RecurringBuyHistoryResult{Executions: scheduler.History(params.PlanID, params.Limit)}, nil
+}
+
+func setRecurringBuyPaused(ctx context.Context, id string, paused bool) (*mcp.CallToolResult, *dca.Plan, error) {
+	var res mcp.CallToolResult
+
+	scheduler, ok := ctx.Value(dcaSchedulerKey{}).(*dca.Scheduler)
+	if !ok {
+		return nil, nil, missingDependency("recurring buy scheduler")
+	}
+
+	plan, err := scheduler.SetPaused(id, paused)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &plan, nil
+}
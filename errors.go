@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"upbit-mcp-server/internal/engine"
+	"upbit-mcp-server/risk"
+	"upbit-mcp-server/upbit"
+)
+
+// toolErrorPayload 도구 오류로 에이전트에게 전달되는 내용
+type toolErrorPayload struct {
+	Name       string `json:"name"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+// toolErr 구조화된 도구 오류.
+// go-sdk는 핸들러가 반환한 오류를 IsError가 설정된 CallToolResult의 텍스트로 전달하므로
+// Error()에서 {"error":{"name":...,"message":...}} 형식의 JSON을 반환한다.
+type toolErr struct {
+	payload toolErrorPayload
+	cause   error
+}
+
+func (e *toolErr) Error() string {
+	b, _ := json.Marshal(map[string]toolErrorPayload{"error": e.payload})
+	return string(b)
+}
+
+func (e *toolErr) Unwrap() error {
+	return e.cause
+}
+
+// toolError 핸들러에서 발생한 오류를 에이전트가 오류 이름으로 판단할 수 있도록 변환
+func toolError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var te *toolErr
+	if errors.As(err, &te) {
+		return err
+	}
+
+	payload := toolErrorPayload{Name: "internal_error", Message: err.Error()}
+
+	var apiErr *upbit.APIError
+	var rejection *upbit.OrderRejection
+	var violation *risk.Violation
+	var engineErr *engine.Error
+	switch {
+	case errors.As(err, &apiErr):
+		payload = toolErrorPayload{
+			Name:       apiErr.Name,
+			Message:    apiErr.Message,
+			StatusCode: apiErr.StatusCode,
+		}
+	case errors.As(err, &rejection):
+		payload = toolErrorPayload{
+			Name:    rejection.Name,
+			Message: rejection.Message,
+		}
+	case errors.As(err, &violation):
+		payload = toolErrorPayload{
+			Name:    "risk_" + violation.Rule,
+			Message: violation.Message,
+		}
+	case errors.As(err, &engineErr):
+		payload = toolErrorPayload{
+			Name:    engineErr.Name,
+			Message: engineErr.Message,
+		}
+	case errors.Is(err, context.Canceled):
+		payload.Name = "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		payload.Name = "timeout"
+	}
+
+	return &toolErr{payload: payload, cause: err}
+}
+
+// validationError 업비트에 요청하기 전에 도구 입력이 잘못된 것을 발견했을 때의 오류
+func validationError(format string, args ...any) error {
+	return &toolErr{payload: toolErrorPayload{
+		Name:    upbit.ErrValidation,
+		Message: fmt.Sprintf(format, args...),
+	}}
+}
+
+// missingDependency 서버 설정이 잘못되어 ctx에 what이 없을 때의 도구 오류
+func missingDependency(what string) error {
+	return toolError(fmt.Errorf("%s not found in context", what))
+}
+
+// errorPayload 여러 작업의 결과를 한 번에 돌려줄 때 작업별 오류를 담는 내용
+func errorPayload(err error) *toolErrorPayload {
+	var te *toolErr
+	if !errors.As(toolError(err), &te) {
+		return nil
+	}
+	return &te.payload
+}
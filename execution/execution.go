@@ -0,0 +1,588 @@
+// Package execution 큰 주문을 시간에 나누어 작은 시장가 주문으로 체결하는 실행 알고리즘(TWAP, VWAP)을 제공한다.
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/engine"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// DefaultInterval 나눈 주문을 낼 시각이 되었는지 확인하는 기본 주기
+const DefaultInterval = time.Second
+
+// IdentifierPrefix 실행 알고리즘이 낸 주문의 identifier 접두사. 뒤에 실행 ID와 조각 번호가 붙는다
+const IdentifierPrefix = "exec-"
+
+// stateName 실행 기록을 저장하는 파일 이름
+const stateName = "executions"
+
+// 실행 범위 제한
+const (
+	MaxSlices        = 100
+	MinDuration      = time.Minute
+	MaxDuration      = 24 * time.Hour
+	MinSliceInterval = 5 * time.Second
+)
+
+// amountPlaces 나눈 주문 금액과 수량의 소수점 자릿수
+const amountPlaces = 8
+
+// 실행 알고리즘
+const (
+	AlgoTWAP = "twap" // 같은 간격, 같은 크기로 나눈다
+	AlgoVWAP = "vwap" // 과거 같은 시간대의 분봉 거래대금 비율로 나눈다
+)
+
+// 실행 상태
+const (
+	StateStarting  = "starting" // 저장했고 첫 조각을 주문하는 중
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateAborted   = "aborted"
+	StateFailed    = "failed"
+)
+
+// 조각 주문 상태
+const (
+	SlicePending = "pending" // 아직 낼 시각이 되지 않음
+	SlicePlaced  = "placed"  // 주문했고 체결 결과를 기다림
+	SliceFilled  = "filled"  // 주문이 끝남
+	SliceCarried = "carried" // 최소 주문 금액보다 작아 다음 조각으로 넘김
+)
+
+// 실행을 시작하거나 중단하지 못한 이유
+const (
+	ErrInvalidExecution  = "invalid_execution"
+	ErrExecutionNotFound = "execution_not_found"
+	ErrNotRunning        = "execution_not_running"
+)
+
+// Error 실행 요청이 거절된 이유
+type Error = engine.Error
+
+func newError(name, format string, args ...any) error {
+	return engine.NewError("execution", name, format, args...)
+}
+
+// Config 실행할 부모 주문과 알고리즘
+type Config struct {
+	Market    string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Side      string        `json:"side" jsonschema:"bid (buy an amount in the quote currency) or ask (sell a volume)."`
+	Amount    upbit.Decimal `json:"amount,omitempty" jsonschema:"Total amount in the quote currency to buy."`
+	Volume    upbit.Decimal `json:"volume,omitempty" jsonschema:"Total volume to sell."`
+	Algorithm string        `json:"algorithm" jsonschema:"twap or vwap."`
+	Duration  time.Duration `json:"-"`
+	Slices    int           `json:"slices" jsonschema:"Number of child orders."`
+}
+
+// total 매수는 금액, 매도는 수량
+func (cfg Config) total() upbit.Decimal {
+	if cfg.Side == "bid" {
+		return cfg.Amount
+	}
+	return cfg.Volume
+}
+
+// Slice 나눈 조각 주문 하나
+type Slice struct {
+	At             time.Time     `json:"at" jsonschema:"Scheduled time of the child order."`
+	Target         upbit.Decimal `json:"target" jsonschema:"Planned amount (buy, quote currency) or volume (sell) of this slice."`
+	Ordered        upbit.Decimal `json:"ordered,omitempty" jsonschema:"Amount or volume actually ordered, including what was carried over from earlier slices."`
+	State          string        `json:"state" jsonschema:"pending, placed, filled or carried (below the minimum order total, added to the next slice)."`
+	OrderUuid      string        `json:"order_uuid,omitempty"`
+	ExecutedVolume upbit.Decimal `json:"executed_volume,omitempty"`
+	ExecutedFunds  upbit.Decimal `json:"executed_funds,omitempty"`
+	PaidFee        upbit.Decimal `json:"paid_fee,omitempty"`
+}
+
+// Execution 실행 중이거나 끝난 실행과 결과 보고
+type Execution struct {
+	ID string `json:"id"`
+	Config
+	State      string        `json:"state" jsonschema:"starting, running, completed, aborted or failed."`
+	StartAt    time.Time     `json:"start_at"`
+	EndAt      time.Time     `json:"end_at" jsonschema:"End of the execution window."`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	MinTotal   upbit.Decimal `json:"min_total" jsonschema:"Minimum order total of the market. Smaller slices are carried over to the next slice."`
+	Children   []Slice       `json:"child_orders"`
+
+	ArrivalPrice   upbit.Decimal `json:"arrival_price" jsonschema:"Current price when the execution started."`
+	ExecutedVolume upbit.Decimal `json:"executed_volume"`
+	ExecutedFunds  upbit.Decimal `json:"executed_funds" jsonschema:"Executed amount in the quote currency, fees excluded."`
+	PaidFee        upbit.Decimal `json:"paid_fee"`
+	AvgPrice       upbit.Decimal `json:"avg_price" jsonschema:"Average fill price of all child orders."`
+	SlippageBps    float64       `json:"slippage_bps" jsonschema:"Average price vs. arrival price in basis points. Positive means worse than the arrival price (paid more when buying, received less when selling)."`
+	Remaining      upbit.Decimal `json:"remaining" jsonschema:"Amount (buy) or volume (sell) not ordered yet."`
+	Note           string        `json:"note,omitempty"`
+}
+
+// Market 현재가와 분봉 조회 기능
+type Market interface {
+	GetTicker(ctx context.Context, symbol string) ([]upbit.Ticker, error)
+	GetMinuteCandles(ctx context.Context, unit int, params upbit.RequestParams) ([]*upbit.Candle, error)
+}
+
+// Engine 실행을 보관하고 예정 시각마다 조각 주문을 낸다
+type Engine struct {
+	trader upbit.Trader
+	market Market
+	base   engine.Base
+
+	// checkMu 조각 주문이 겹쳐 같은 조각을 두 번 주문하지 않도록 한다
+	checkMu sync.Mutex
+
+	// mu 실행 목록을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu         sync.Mutex
+	executions []*Execution
+}
+
+// NewEngine 실행 엔진 생성. st가 nil이면 실행 기록을 메모리에만 보관한다.
+// 서버가 다시 시작되면 밀린 조각을 한꺼번에 주문하지 않도록 실행 중이거나 시작하던 실행은 중단된 것으로 바꾼다.
+func NewEngine(trader upbit.Trader, market Market, st *store.Store, interval time.Duration) (*Engine, error) {
+	e := &Engine{
+		trader: trader,
+		market: market,
+		base:   engine.NewBase(st, interval, DefaultInterval),
+	}
+	if err := e.base.Load(stateName, &e.executions); err != nil {
+		return nil, err
+	}
+	for _, x := range e.executions {
+		switch x.State {
+		case StateRunning:
+			x.finish(StateAborted, "interrupted by a server restart")
+		case StateStarting:
+			x.finish(StateAborted, fmt.Sprintf("interrupted by a server restart while the first child order (identifier %s%s-0) was placed", IdentifierPrefix, x.ID))
+		}
+	}
+	return e, nil
+}
+
+// Start 실행을 시작한다. 시작할 때의 현재가를 도착 가격으로 기록하고 첫 조각은 바로 주문한다.
+// 주문하기 전에 시작 중 상태로 먼저 저장하고, 주문 결과를 저장하지 못하면 낸 주문을 취소한다.
+func (e *Engine) Start(ctx context.Context, cfg Config) (Execution, error) {
+	if err := cfg.validate(); err != nil {
+		return Execution{}, err
+	}
+	tickers, err := e.market.GetTicker(ctx, cfg.Market)
+	if err != nil {
+		return Execution{}, err
+	}
+	if len(tickers) == 0 {
+		return Execution{}, fmt.Errorf("no current price for %s", cfg.Market)
+	}
+	chance, err := e.trader.GetChance(ctx, cfg.Market)
+	if err != nil {
+		return Execution{}, err
+	}
+
+	now := time.Now()
+	x := &Execution{
+		ID:           uuid.NewString(),
+		Config:       cfg,
+		State:        StateStarting,
+		StartAt:      now,
+		EndAt:        now.Add(cfg.Duration),
+		MinTotal:     chance.Market.Bid.MinTotal,
+		ArrivalPrice: tickers[0].TradePrice,
+	}
+	if cfg.Side == "ask" {
+		x.MinTotal = chance.Market.Ask.MinTotal
+	}
+
+	weights := e.weights(ctx, x)
+	x.Children = plan(now, cfg, weights)
+	x.report()
+
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	e.executions = append(e.executions, x)
+	if err := e.save(); err != nil {
+		e.executions = e.executions[:len(e.executions)-1]
+		e.mu.Unlock()
+		return Execution{}, err
+	}
+	w := x.copy()
+	e.mu.Unlock()
+
+	// 시작 중인 실행은 확인과 중단의 대상이 아니므로 복사본을 그대로 반영한다
+	w.State = StateRunning
+	e.step(ctx, &w, now)
+
+	e.mu.Lock()
+	*x = w
+	err = e.save()
+	e.mu.Unlock()
+	if err == nil {
+		return w, nil
+	}
+
+	e.rollback(ctx, &w)
+	e.mu.Lock()
+	*x = w
+	x.finish(StateFailed, fmt.Sprintf("could not be saved, placed child orders were canceled: %v", err))
+	e.mu.Unlock()
+	return Execution{}, err
+}
+
+// rollback 저장하지 못한 실행이 낸 주문 중 아직 체결되지 않은 주문을 취소한다
+func (e *Engine) rollback(ctx context.Context, x *Execution) {
+	for _, s := range x.Children {
+		if s.State != SlicePlaced {
+			continue
+		}
+		if _, err := e.trader.CancelOrder(ctx, s.OrderUuid); err != nil {
+			log.Printf("execution %s: could not cancel order %s: %v", x.ID, s.OrderUuid, err)
+		}
+	}
+	e.settle(ctx, x)
+	x.report()
+}
+
+// List 실행 목록
+func (e *Engine) List() []Execution {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	res := make([]Execution, 0, len(e.executions))
+	for _, x := range e.executions {
+		res = append(res, x.copy())
+	}
+	return res
+}
+
+// Get 실행 하나
+func (e *Engine) Get(id string) (Execution, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	x := e.find(id)
+	if x == nil {
+		return Execution{}, newError(ErrExecutionNotFound, "execution %s not found", id)
+	}
+	return x.copy(), nil
+}
+
+// Abort 남은 조각을 주문하지 않고 실행을 중단한다. 이미 낸 주문의 체결 결과는 계속 반영한다
+func (e *Engine) Abort(id string) (Execution, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	x := e.find(id)
+	if x == nil {
+		return Execution{}, newError(ErrExecutionNotFound, "execution %s not found", id)
+	}
+	if x.State != StateRunning {
+		return Execution{}, newError(ErrNotRunning, "execution %s is already %s", id, x.State)
+	}
+	x.report()
+	x.finish(StateAborted, fmt.Sprintf("aborted by the user, %s was not ordered", x.Remaining))
+	if err := e.save(); err != nil {
+		return Execution{}, err
+	}
+	return x.copy(), nil
+}
+
+// Run ctx가 끝날 때까지 주기적으로 조각 주문을 내고 체결 결과를 반영한다
+func (e *Engine) Run(ctx context.Context) {
+	e.base.Run(ctx, "executions", func(ctx context.Context) error {
+		return e.Check(ctx, time.Now())
+	})
+}
+
+// Check now 기준으로 낼 시각이 된 조각을 주문하고, 주문한 조각의 체결 결과를 반영한다.
+// 잠금을 잡고 복사본을 만든 뒤 주문과 조회는 잠금 밖에서 하고 바뀐 복사본만 다시 잠금을 잡고 반영한다.
+func (e *Engine) Check(ctx context.Context, now time.Time) error {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	var work []Execution
+	for _, x := range e.executions {
+		if x.State == StateRunning || x.hasPlaced() {
+			work = append(work, x.copy())
+		}
+	}
+	e.mu.Unlock()
+
+	var done []Execution
+	for i := range work {
+		if e.step(ctx, &work[i], now) {
+			done = append(done, work[i])
+		}
+	}
+	if len(done) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, d := range done {
+		if x := e.find(d.ID); x != nil {
+			x.apply(d)
+		}
+	}
+	return e.save()
+}
+
+// step 실행 하나를 진행한다. 바뀐 것이 있으면 true
+func (e *Engine) step(ctx context.Context, x *Execution, now time.Time) bool {
+	changed := e.settle(ctx, x)
+
+	if x.State == StateRunning {
+		for i := range x.Children {
+			s := &x.Children[i]
+			if s.State != SlicePending || s.At.After(now) {
+				continue
+			}
+			if !e.place(ctx, x, i) {
+				break
+			}
+			changed = true
+			if x.State != StateRunning {
+				break
+			}
+		}
+		if x.State == StateRunning && !x.hasPending() && !x.hasPlaced() {
+			x.finish(StateCompleted, x.Note)
+			changed = true
+		}
+	}
+
+	if changed {
+		x.report()
+	}
+	return changed
+}
+
+// place 조각 하나를 주문한다. 계획한 누적량에서 지금까지 주문한 양을 뺀 만큼 주문하므로 넘긴 조각의 양도 함께 주문된다.
+// 일시적인 오류로 주문하지 못하면 false를 반환하고 다음 확인에서 다시 시도한다
+func (e *Engine) place(ctx context.Context, x *Execution, i int) bool {
+	s := &x.Children[i]
+	last := i == len(x.Children)-1
+
+	var planned, ordered upbit.Decimal = "0", "0"
+	for j := 0; j <= i; j++ {
+		planned = planned.Add(x.Children[j].Target)
+		ordered = ordered.Add(x.Children[j].Ordered)
+	}
+	size := planned.Sub(ordered)
+	if last {
+		size = x.total().Sub(ordered)
+	}
+
+	value := size
+	if x.Side == "ask" {
+		// 시장가 매도 금액은 도착 가격이 아닌 지금의 현재가로 추정한다
+		tickers, err := e.market.GetTicker(ctx, x.Market)
+		if err != nil || len(tickers) == 0 {
+			log.Printf("execution %s: slice %d could not be priced, retrying: %v", x.ID, i, err)
+			return false
+		}
+		value = size.Mul(tickers[0].TradePrice)
+	}
+	if value.LessThan(x.MinTotal) {
+		s.State = SliceCarried
+		if last && size.Sign() > 0 {
+			x.Note = fmt.Sprintf("the remaining %s is below the minimum order total and was not ordered", size)
+		}
+		return true
+	}
+
+	params := upbit.RequestParams{
+		Market:     x.Market,
+		Side:       x.Side,
+		SmpType:    upbit.SmpCancelMaker,
+		Identifier: fmt.Sprintf("%s%s-%d", IdentifierPrefix, x.ID, i),
+	}
+	if x.Side == "bid" {
+		params.OrdType = upbit.OrdTypePrice
+		params.Price = size
+	} else {
+		params.OrdType = upbit.OrdTypeMarket
+		params.Volume = size
+	}
+
+	order, err := e.trader.PlaceOrder(ctx, params)
+	if err != nil {
+		if upbit.IsTemporary(err) {
+			log.Printf("execution %s: slice %d could not be placed, retrying: %v", x.ID, i, err)
+			return false
+		}
+		x.finish(StateFailed, fmt.Sprintf("slice %d was rejected: %v", i, err))
+		return true
+	}
+	s.State = SlicePlaced
+	s.Ordered = size
+	s.OrderUuid = order.Uuid
+	return true
+}
+
+// settle 주문한 조각 중 끝난 주문의 체결 결과를 반영한다
+func (e *Engine) settle(ctx context.Context, x *Execution) bool {
+	changed := false
+	for i := range x.Children {
+		s := &x.Children[i]
+		if s.State != SlicePlaced {
+			continue
+		}
+		order, err := e.trader.GetOrder(ctx, s.OrderUuid)
+		if err != nil {
+			log.Printf("execution %s: could not get order %s: %v", x.ID, s.OrderUuid, err)
+			continue
+		}
+		if order.IsOpen() {
+			continue
+		}
+		s.State = SliceFilled
+		s.ExecutedVolume = order.ExecutedVolume
+		s.ExecutedFunds = order.ExecutedFunds()
+		s.PaidFee = order.PaidFee
+		changed = true
+	}
+	return changed
+}
+
+// report 체결 결과를 합산해 평균 체결가와 도착 가격 대비 슬리피지를 계산한다
+func (x *Execution) report() {
+	var volume, funds, fee, ordered upbit.Decimal = "0", "0", "0", "0"
+	for _, s := range x.Children {
+		volume = volume.Add(s.ExecutedVolume)
+		funds = funds.Add(s.ExecutedFunds)
+		fee = fee.Add(s.PaidFee)
+		ordered = ordered.Add(s.Ordered)
+	}
+	x.ExecutedVolume, x.ExecutedFunds, x.PaidFee = volume, funds, fee
+	x.Remaining = x.total().Sub(ordered)
+	x.AvgPrice = "0"
+	x.SlippageBps = 0
+	if volume.Sign() > 0 {
+		x.AvgPrice = funds.Div(volume, amountPlaces)
+		diff := x.AvgPrice.Sub(x.ArrivalPrice)
+		if x.Side == "ask" {
+			diff = diff.Neg()
+		}
+		x.SlippageBps = diff.Float64() / x.ArrivalPrice.Float64() * 10000
+	}
+}
+
+// apply 잠금 밖에서 진행한 결과를 반영한다. 그 사이에 사용자가 중단했으면 중단 상태는 그대로 둔다
+func (x *Execution) apply(done Execution) {
+	state, note, finished := x.State, x.Note, x.FinishedAt
+	*x = done
+	if state != StateRunning {
+		x.State, x.Note, x.FinishedAt = state, note, finished
+	}
+}
+
+func (x *Execution) finish(state, note string) {
+	now := time.Now()
+	x.State = state
+	x.Note = note
+	x.FinishedAt = &now
+}
+
+func (x *Execution) hasPending() bool {
+	for _, s := range x.Children {
+		if s.State == SlicePending {
+			return true
+		}
+	}
+	return false
+}
+
+func (x *Execution) hasPlaced() bool {
+	for _, s := range x.Children {
+		if s.State == SlicePlaced {
+			return true
+		}
+	}
+	return false
+}
+
+// copy 잠금 밖으로 내보낼 복사본
+func (x *Execution) copy() Execution {
+	c := *x
+	c.Children = append([]Slice(nil), x.Children...)
+	return c
+}
+
+func (e *Engine) find(id string) *Execution {
+	for _, x := range e.executions {
+		if x.ID == id {
+			return x
+		}
+	}
+	return nil
+}
+
+func (e *Engine) save() error {
+	return e.base.Save(stateName, e.executions)
+}
+
+func (cfg Config) validate() error {
+	if cfg.Market == "" {
+		return newError(ErrInvalidExecution, "market is required")
+	}
+	switch cfg.Side {
+	case "bid":
+		if cfg.Amount.Sign() <= 0 || cfg.Volume != "" {
+			return newError(ErrInvalidExecution, "buy executions require a positive amount and no volume")
+		}
+	case "ask":
+		if cfg.Volume.Sign() <= 0 || cfg.Amount != "" {
+			return newError(ErrInvalidExecution, "sell executions require a positive volume and no amount")
+		}
+	default:
+		return newError(ErrInvalidExecution, "side must be bid or ask")
+	}
+	if cfg.Algorithm != AlgoTWAP && cfg.Algorithm != AlgoVWAP {
+		return newError(ErrInvalidExecution, "algorithm must be %s or %s", AlgoTWAP, AlgoVWAP)
+	}
+	if cfg.Duration < MinDuration || cfg.Duration > MaxDuration {
+		return newError(ErrInvalidExecution, "duration must be between %s and %s", MinDuration, MaxDuration)
+	}
+	if cfg.Slices < 1 || cfg.Slices > MaxSlices {
+		return newError(ErrInvalidExecution, "slices must be between 1 and %d", MaxSlices)
+	}
+	if cfg.Duration/time.Duration(cfg.Slices) < MinSliceInterval {
+		return newError(ErrInvalidExecution, "child orders must be at least %s apart", MinSliceInterval)
+	}
+	return nil
+}
+
+// plan 실행 구간을 같은 간격으로 나누고 가중치 비율로 조각의 크기를 정한다. 나머지는 마지막 조각에 더한다
+func plan(start time.Time, cfg Config, weights []float64) []Slice {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	total := cfg.total()
+	step := cfg.Duration / time.Duration(cfg.Slices)
+
+	res := make([]Slice, cfg.Slices)
+	var assigned upbit.Decimal = "0"
+	for i := range res {
+		target := total.Mul(upbit.DecimalFromFloat(weights[i]/sum)).Round(amountPlaces, upbit.RoundDown)
+		if i == len(res)-1 {
+			target = total.Sub(assigned)
+		}
+		assigned = assigned.Add(target)
+		res[i] = Slice{
+			At:     start.Add(step * time.Duration(i)),
+			Target: target,
+			State:  SlicePending,
+		}
+	}
+	return res
+}
@@ -0,0 +1,148 @@
+package execution_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"upbit-mcp-server/execution"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+func newEngine(t *testing.T, trader upbit.Trader, ex *upbittest.Exchange) *execution.Engine {
+	t.Helper()
+	e, err := execution.NewEngine(trader, ex.Client(), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// checkAt now에 확인하고 바뀐 실행을 반환한다
+func checkAt(t *testing.T, e *execution.Engine, x execution.Execution, now time.Time) execution.Execution {
+	t.Helper()
+	if err := e.Check(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.Get(x.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestCarryBelowMinTotal(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	e := newEngine(t, ex.Client(), ex)
+
+	// 4,000원씩 다섯 조각은 모두 최소 주문 금액 5,000원보다 작다
+	x, err := e.Start(context.Background(), execution.Config{Market: "KRW-BTC", Side: "bid", Amount: "20000", Algorithm: execution.AlgoTWAP, Duration: time.Minute, Slices: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.State != execution.StateRunning || x.Children[0].State != execution.SliceCarried {
+		t.Fatalf("started execution = %s, first slice %s", x.State, x.Children[0].State)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 0 {
+		t.Fatalf("orders placed = %d, want 0", n)
+	}
+
+	// 넘긴 조각은 다음 조각과 함께 8,000원으로 주문한다
+	for i := 1; i < 4; i++ {
+		x = checkAt(t, e, x, x.Children[i].At)
+	}
+	for i, want := range []string{execution.SliceCarried, execution.SliceFilled, execution.SliceCarried, execution.SlicePlaced} {
+		if s := x.Children[i]; s.State != want {
+			t.Errorf("slice %d = %s, want %s", i, s.State, want)
+		}
+	}
+	if s := x.Children[3]; !s.Ordered.Equal("8000") {
+		t.Errorf("fourth slice ordered %s, want 8000", s.Ordered)
+	}
+
+	// 마지막 조각의 남은 4,000원은 주문하지 못하고 실행을 끝낸다
+	x = checkAt(t, e, x, x.Children[4].At)
+	if x.State != execution.StateCompleted || x.Children[4].State != execution.SliceCarried || x.Note == "" {
+		t.Fatalf("execution = %s, last slice %s, note %q", x.State, x.Children[4].State, x.Note)
+	}
+	if !x.ExecutedFunds.Equal("16000") || !x.Remaining.Equal("4000") {
+		t.Errorf("executed funds = %s, remaining %s", x.ExecutedFunds, x.Remaining)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 2 {
+		t.Errorf("orders placed = %d, want 2", n)
+	}
+}
+
+func TestLastSliceOrdersRemainder(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"BTC": "0.01"})
+	e := newEngine(t, ex.Client(), ex)
+
+	// 0.001을 셋으로 나누면 0.00033333씩이고 나머지는 마지막 조각이 주문한다
+	x, err := e.Start(context.Background(), execution.Config{Market: "KRW-BTC", Side: "ask", Volume: "0.001", Algorithm: execution.AlgoTWAP, Duration: time.Minute, Slices: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x = checkAt(t, e, x, x.Children[1].At)
+	x = checkAt(t, e, x, x.Children[2].At)
+	x = checkAt(t, e, x, x.EndAt)
+
+	for i, want := range []upbit.Decimal{"0.00033333", "0.00033333", "0.00033334"} {
+		if s := x.Children[i]; s.State != execution.SliceFilled || !s.Ordered.Equal(want) || !s.ExecutedVolume.Equal(want) {
+			t.Errorf("slice %d = %s, ordered %s, executed %s, want %s", i, s.State, s.Ordered, s.ExecutedVolume, want)
+		}
+	}
+	if x.State != execution.StateCompleted || !x.ExecutedVolume.Equal("0.001") || !x.Remaining.IsZero() {
+		t.Errorf("execution = %s, executed %s, remaining %s", x.State, x.ExecutedVolume, x.Remaining)
+	}
+	if !x.AvgPrice.Equal("100000000") || x.SlippageBps != 0 {
+		t.Errorf("avg price = %s, slippage %v bps", x.AvgPrice, x.SlippageBps)
+	}
+}
+
+// abortOnPlace 주문을 보내기 직전에 abort를 호출하는 Trader
+type abortOnPlace struct {
+	upbit.Trader
+	abort func()
+}
+
+func (a *abortOnPlace) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	if a.abort != nil {
+		a.abort()
+	}
+	return a.Trader.PlaceOrder(ctx, params)
+}
+
+func TestAbortWhileSlicePlaced(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	trader := &abortOnPlace{Trader: ex.Client()}
+	e := newEngine(t, trader, ex)
+
+	x, err := e.Start(context.Background(), execution.Config{Market: "KRW-BTC", Side: "bid", Amount: "30000", Algorithm: execution.AlgoTWAP, Duration: time.Minute, Slices: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 두 번째 조각을 주문하는 사이에 사용자가 실행을 중단한다
+	trader.abort = func() {
+		trader.abort = nil
+		if _, err := e.Abort(x.ID); err != nil {
+			t.Error(err)
+		}
+	}
+	x = checkAt(t, e, x, x.Children[1].At)
+	if x.State != execution.StateAborted || x.Children[1].State != execution.SlicePlaced {
+		t.Fatalf("execution = %s, second slice %s", x.State, x.Children[1].State)
+	}
+
+	// 중단된 뒤에도 이미 낸 조각의 체결은 반영하고 남은 조각은 주문하지 않는다
+	x = checkAt(t, e, x, x.Children[2].At)
+	if x.State != execution.StateAborted || x.Children[1].State != execution.SliceFilled || x.Children[2].State != execution.SlicePending {
+		t.Fatalf("execution = %s, slices %s/%s", x.State, x.Children[1].State, x.Children[2].State)
+	}
+	if !x.ExecutedFunds.Equal("12000") || !x.Remaining.Equal("18000") {
+		t.Errorf("executed funds = %s, remaining %s", x.ExecutedFunds, x.Remaining)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 2 {
+		t.Errorf("orders placed = %d, want 2", n)
+	}
+}
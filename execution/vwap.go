@@ -0,0 +1,87 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"upbit-mcp-server/upbit"
+)
+
+// profileDays VWAP 거래량 분포를 만들 때 참고하는 과거 일수
+const profileDays = 5
+
+// candleUnits 업비트 분봉 단위. 실행 구간을 한 번에 조회할 수 있는(200개 이하) 가장 작은 단위를 사용한다
+var candleUnits = []int{1, 3, 5, 10, 15, 30, 60, 240}
+
+// maxCandles 분봉 한 번 조회의 최대 개수
+const maxCandles = 200
+
+// candleTimeLayout 분봉의 candle_date_time_utc 형식
+const candleTimeLayout = "2006-01-02T15:04:05"
+
+// weights 조각별 크기 비율. TWAP은 모두 같고, VWAP은 지난 며칠 같은 시간대의 분봉 거래대금을 조각 구간별로 합한 값이다.
+// 분봉을 조회하지 못하거나 거래가 없으면 TWAP으로 나눈다
+func (e *Engine) weights(ctx context.Context, x *Execution) []float64 {
+	weights := make([]float64, x.Slices)
+	for i := range weights {
+		weights[i] = 1
+	}
+	if x.Algorithm != AlgoVWAP {
+		return weights
+	}
+
+	profile, err := e.volumeProfile(ctx, x.Market, x.StartAt, x.Duration, x.Slices)
+	if err != nil {
+		x.Note = fmt.Sprintf("could not build the volume profile, slicing evenly: %v", err)
+		return weights
+	}
+	var sum float64
+	for _, v := range profile {
+		sum += v
+	}
+	if sum <= 0 {
+		x.Note = fmt.Sprintf("no trades in the same time window of the last %d days, slicing evenly", profileDays)
+		return weights
+	}
+	return profile
+}
+
+// volumeProfile 지난 profileDays일 동안 [start, start+duration)과 같은 시간대의 거래대금을 n개 구간으로 나누어 합한다
+func (e *Engine) volumeProfile(ctx context.Context, market string, start time.Time, duration time.Duration, n int) ([]float64, error) {
+	unit := candleUnits[len(candleUnits)-1]
+	for _, u := range candleUnits {
+		if duration <= time.Duration(u*maxCandles)*time.Minute {
+			unit = u
+			break
+		}
+	}
+	count := min(int(duration/(time.Duration(unit)*time.Minute))+1, maxCandles)
+	step := duration / time.Duration(n)
+
+	profile := make([]float64, n)
+	for day := 1; day <= profileDays; day++ {
+		shift := time.Duration(day) * 24 * time.Hour
+		to := start.Add(duration).Add(-shift).UTC()
+		candles, err := e.market.GetMinuteCandles(ctx, unit, upbit.RequestParams{
+			Market: market,
+			To:     to.Format(time.RFC3339),
+			Count:  count,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range candles {
+			t, err := time.Parse(candleTimeLayout, c.CandleDateTimeUtc)
+			if err != nil {
+				continue
+			}
+			offset := t.Add(shift).Sub(start)
+			if offset < 0 || offset >= duration {
+				continue
+			}
+			// step은 나누고 버린 값이므로 마지막 구간 끝의 몇 ns는 마지막 조각에 더한다
+			profile[min(int(offset/step), n-1)] += c.CandleAccTradePrice.Float64()
+		}
+	}
+	return profile, nil
+}
@@ -0,0 +1,44 @@
+package execution
+
+import (
+	"context"
+	"testing"
+	"time"
+	"upbit-mcp-server/upbit"
+)
+
+// candleAtTo 조회 끝 시각(To)에 분봉 하나만 돌려주는 Market
+type candleAtTo struct{}
+
+func (candleAtTo) GetTicker(ctx context.Context, symbol string) ([]upbit.Ticker, error) {
+	return nil, nil
+}
+
+func (candleAtTo) GetMinuteCandles(ctx context.Context, unit int, params upbit.RequestParams) ([]*upbit.Candle, error) {
+	to, err := time.Parse(time.RFC3339, params.To)
+	if err != nil {
+		return nil, err
+	}
+	return []*upbit.Candle{{CandleDateTimeUtc: to.UTC().Format(candleTimeLayout), CandleAccTradePrice: "1000"}}, nil
+}
+
+func TestVolumeProfileUnevenSteps(t *testing.T) {
+	e := &Engine{market: candleAtTo{}}
+
+	// 1분을 7로 나누면 조각 구간이 3ns 모자라, 실행이 끝나기 1ns 전의 분봉은 마지막 구간 뒤에 놓인다
+	end := time.Date(2026, 1, 5, 9, 1, 0, 0, time.UTC)
+	start := end.Add(-time.Minute + time.Nanosecond)
+	profile, err := e.volumeProfile(context.Background(), "KRW-BTC", start, time.Minute, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range profile {
+		want := 0.0
+		if i == 6 {
+			want = 1000 * profileDays
+		}
+		if v != want {
+			t.Errorf("profile[%d] = %v, want %v", i, v, want)
+		}
+	}
+}
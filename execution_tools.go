@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"time"
+	"upbit-mcp-server/execution"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// executionEngineKey는 context 내에서 실행 알고리즘 엔진을 식별하기 위한 키
+type executionEngineKey struct{}
+
+type StartExecutionRequest struct {
+	Market    string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Side      string        `json:"side" jsonschema:"Allowed: 'bid' (buy amount in the quote currency), 'ask' (sell volume)."`
+	Amount    upbit.Decimal `json:"amount,omitempty" jsonschema:"Total amount in the quote currency to buy. Required for bid."`
+	Volume    upbit.Decimal `json:"volume,omitempty" jsonschema:"Total volume to sell. Required for ask."`
+	Algorithm string        `json:"algorithm" jsonschema:"Allowed: 'twap' (equal child orders at equal intervals), 'vwap' (child orders sized by the minute volume profile of the same time window over the last days)."`
+	Duration  string        `json:"duration" jsonschema:"Execution window as a Go duration between 1m and 24h (e.g. 30m, 2h)."`
+	Slices    int           `json:"slices" jsonschema:"Number of child market orders (1-100). Child orders must be at least 5s apart."`
+}
+
+type ExecutionIDRequest struct {
+	ID string `json:"id" jsonschema:"Execution ID."`
+}
+
+// ExecutionSummary 실행 목록의 한 항목
+type ExecutionSummary struct {
+	ID string `json:"id"`
+	execution.Config
+	State          string        `json:"state"`
+	StartAt        time.Time     `json:"start_at"`
+	EndAt          time.Time     `json:"end_at"`
+	ArrivalPrice   upbit.Decimal `json:"arrival_price"`
+	AvgPrice       upbit.Decimal `json:"avg_price"`
+	SlippageBps    float64       `json:"slippage_bps"`
+	ExecutedVolume upbit.Decimal `json:"executed_volume"`
+	ExecutedFunds  upbit.Decimal `json:"executed_funds"`
+	Remaining      upbit.Decimal `json:"remaining"`
+}
+
+type GetExecutionsResult struct {
+	Executions []ExecutionSummary `json:"executions"`
+}
+
+func StartExecution(ctx context.Context, req *mcp.CallToolRequest, params *StartExecutionRequest) (
+	*mcp.CallToolResult,
+	*execution.Execution,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(executionEngineKey{}).(*execution.Engine)
+	if !ok {
+		return nil, nil, missingDependency("execution engine")
+	}
+
+	duration, err := time.ParseDuration(params.Duration)
+	if err != nil {
+		return nil, nil, validationError("invalid duration %q: %v", params.Duration, err)
+	}
+
+	// 나눈 주문은 확인할 사용자가 없을 때 나가므로 전체 주문을 시작할 때 확인한다
+	parent := upbit.RequestParams{
+		Market:  params.Market,
+		Side:    params.Side,
+		OrdType: upbit.OrdTypePrice,
+		Price:   params.Amount,
+	}
+	if params.Side == "ask" {
+		parent.OrdType = upbit.OrdTypeMarket
+		parent.Price = ""
+		parent.Volume = params.Volume
+	}
+	if err := confirmOrder(ctx, req, parent); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	x, err := engine.Start(ctx, execution.Config{
+		Market:    params.Market,
+		Side:      params.Side,
+		Amount:    params.Amount,
+		Volume:    params.Volume,
+		Algorithm: params.Algorithm,
+		Duration:  duration,
+		Slices:    params.Slices,
+	})
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &x, nil
+}
+
+func GetExecutions(ctx context.Context, req *mcp.CallToolRequest, params any) (
+	*mcp.CallToolResult,
+	*GetExecutionsResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(executionEngineKey{}).(*execution.Engine)
+	if !ok {
+		return nil, nil, missingDependency("execution engine")
+	}
+
+	result := &GetExecutionsResult{Executions: []ExecutionSummary{}}
+	for _, x := range engine.List() {
+		result.Executions = append(result.Executions, ExecutionSummary{
+			ID:             x.ID,
+			Config:         x.Config,
+			State:          x.State,
+			StartAt:        x.StartAt,
+			EndAt:          x.EndAt,
+			ArrivalPrice:   x.ArrivalPrice,
+			AvgPrice:       x.AvgPrice,
+			SlippageBps:    x.SlippageBps,
+			ExecutedVolume: x.ExecutedVolume,
+			ExecutedFunds:  x.ExecutedFunds,
+			Remaining:      x.Remaining,
+		})
+	}
+
+	return &res, result, nil
+}
+
+func GetExecution(ctx context.Context, req *mcp.CallToolRequest, params *ExecutionIDRequest) (
+	*mcp.CallToolResult,
+	*execution.Execution,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(executionEngineKey{}).(*execution.Engine)
+	if !ok {
+		return nil, nil, missingDependency("execution engine")
+	}
+
+	x, err := engine.Get(params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &x, nil
+}
+
+func AbortExecution(ctx context.Context, req *mcp.CallToolRequest, params *ExecutionIDRequest) (
+	*mcp.CallToolResult,
+	*execution.Execution,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(executionEngineKey{}).(*execution.Engine)
+	if !ok {
+		return nil, nil, missingDependency("execution engine")
+	}
+
+	x, err := engine.Abort(params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &x, nil
+}
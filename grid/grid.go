@@ -0,0 +1,642 @@
+// Package grid 가격 구간을 일정 간격의 가격으로 나누어 각 칸의 아래 가격에 사고 위 가격에 파는 그리드 매매를 실행한다.
+package grid
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/engine"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// DefaultInterval 그리드 주문의 체결을 확인하는 기본 주기
+const DefaultInterval = 10 * time.Second
+
+// MaxLevels 한 그리드의 최대 가격 수
+const MaxLevels = 100
+
+// IdentifierPrefix 그리드가 낸 주문의 identifier 접두사. 뒤에 그리드 ID, 칸 번호와 주문 순번이 붙는다
+const IdentifierPrefix = "grid-"
+
+// stateName 그리드를 저장하는 파일 이름
+const stateName = "grid"
+
+// 초기 매수 주문이 체결될 때까지 기다리는 시간과 조회 간격
+const (
+	initialBuyTimeout = 10 * time.Second
+	pollInterval      = 200 * time.Millisecond
+)
+
+// volumePlaces 주문 수량의 소수점 자릿수
+const volumePlaces = 8
+
+// 그리드 상태
+const (
+	StateStarting = "starting" // 저장했고 처음 주문을 내는 중
+	StateRunning  = "running"
+	StateStopped  = "stopped"
+)
+
+// 그리드를 시작하거나 변경하지 못한 이유
+const (
+	ErrInvalidGrid  = "invalid_grid"
+	ErrGridNotFound = "grid_not_found"
+	ErrNotRunning   = "grid_not_running"
+)
+
+// Error 그리드 요청이 거절된 이유
+type Error = engine.Error
+
+func newError(name, format string, args ...any) error {
+	return engine.NewError("grid", name, format, args...)
+}
+
+// Config 그리드 설정
+type Config struct {
+	Market  string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Lower   upbit.Decimal `json:"lower_price" jsonschema:"Lowest grid price."`
+	Upper   upbit.Decimal `json:"upper_price" jsonschema:"Highest grid price."`
+	Levels  int           `json:"levels" jsonschema:"Number of grid prices from lower_price to upper_price. levels-1 cells are traded."`
+	Capital upbit.Decimal `json:"capital" jsonschema:"Total amount in the quote currency committed to the grid, split evenly between the cells."`
+}
+
+// Cell 이웃한 두 가격 사이의 한 칸. 아래 가격에 매수하고 체결되면 위 가격에 같은 수량을 매도한다
+type Cell struct {
+	BuyPrice   upbit.Decimal `json:"buy_price"`
+	SellPrice  upbit.Decimal `json:"sell_price"`
+	Volume     upbit.Decimal `json:"volume" jsonschema:"Volume bought at buy_price."`
+	Side       string        `json:"side,omitempty" jsonschema:"bid: waiting to buy at buy_price, ask: holding held_volume and waiting to sell at sell_price. Empty when the cell stopped trading."`
+	OrderUuid  string        `json:"order_uuid,omitempty" jsonschema:"UUID of the open order of the cell."`
+	Held       upbit.Decimal `json:"held_volume,omitempty" jsonschema:"Volume bought and not yet sold."`
+	Cost       upbit.Decimal `json:"cost,omitempty" jsonschema:"Amount paid for held_volume including fees."`
+	RoundTrips int           `json:"round_trips" jsonschema:"Number of completed buy and sell cycles."`
+	Orders     int           `json:"orders" jsonschema:"Number of orders placed for the cell."`
+	Note       string        `json:"note,omitempty" jsonschema:"Why the cell stopped trading."`
+}
+
+// Grid 실행 중이거나 중지된 그리드
+type Grid struct {
+	ID string `json:"id"`
+	Config
+	State          string        `json:"state" jsonschema:"starting, running or stopped."`
+	Cells          []Cell        `json:"cells"`
+	InitialBuyUuid string        `json:"initial_buy_uuid,omitempty" jsonschema:"UUID of the market buy order that bought the volume sold by the cells above the starting price."`
+	RealizedProfit upbit.Decimal `json:"realized_profit" jsonschema:"Profit of completed round trips in the quote currency, after fees."`
+	RoundTrips     int           `json:"round_trips" jsonschema:"Number of completed buy and sell cycles of all cells."`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	StoppedAt      *time.Time    `json:"stopped_at,omitempty"`
+	Note           string        `json:"note,omitempty" jsonschema:"Problems while starting or stopping the grid."`
+}
+
+// HeldVolume 그리드가 매수해 아직 매도하지 않은 수량
+func (g Grid) HeldVolume() upbit.Decimal {
+	var held upbit.Decimal = "0"
+	for _, c := range g.Cells {
+		held = held.Add(c.Held)
+	}
+	return held
+}
+
+// Layout 시작 전에 계산한 그리드 배치
+type Layout struct {
+	Prices       []upbit.Decimal `json:"prices" jsonschema:"Grid prices aligned to the tick size, from low to high."`
+	CellAmount   upbit.Decimal   `json:"cell_amount" jsonschema:"Amount in the quote currency assigned to each cell."`
+	CurrentPrice upbit.Decimal   `json:"current_price"`
+	BuyCells     int             `json:"buy_cells" jsonschema:"Cells below the current price that start with a buy order."`
+	SellCells    int             `json:"sell_cells" jsonschema:"Cells above the current price that start with a sell order."`
+	InitialBuy   upbit.Decimal   `json:"initial_buy" jsonschema:"Amount bought at market price when starting, to hold the volume sold by sell_cells."`
+}
+
+// Market 현재가 조회 기능
+type Market interface {
+	GetTicker(ctx context.Context, symbol string) ([]upbit.Ticker, error)
+}
+
+// Engine 그리드를 보관하고 주문 체결을 확인해 반대쪽 주문을 낸다
+type Engine struct {
+	trader upbit.Trader
+	market Market
+	base   engine.Base
+
+	// checkMu 체결 확인과 중지가 겹쳐 같은 칸의 주문을 두 번 내지 않도록 한다
+	checkMu sync.Mutex
+
+	// mu 그리드 목록을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu    sync.Mutex
+	grids []*Grid
+}
+
+// NewEngine 그리드 엔진 생성. st가 nil이면 그리드를 메모리에만 보관한다.
+// 시작하던 중에 서버가 멈춘 그리드는 낸 주문을 알 수 없으므로 중지된 것으로 바꾼다.
+func NewEngine(trader upbit.Trader, market Market, st *store.Store, interval time.Duration) (*Engine, error) {
+	e := &Engine{
+		trader: trader,
+		market: market,
+		base:   engine.NewBase(st, interval, DefaultInterval),
+	}
+	if err := e.base.Load(stateName, &e.grids); err != nil {
+		return nil, err
+	}
+	for _, g := range e.grids {
+		if g.State == StateStarting {
+			g.stop(fmt.Sprintf("interrupted by a server restart while starting, check open orders with identifiers starting with %s%s-", IdentifierPrefix, g.ID))
+		}
+	}
+	return e, nil
+}
+
+// Preview 현재가 기준으로 그리드를 어떻게 배치할지 계산한다
+func (e *Engine) Preview(ctx context.Context, cfg Config) (Layout, error) {
+	if err := cfg.validate(); err != nil {
+		return Layout{}, err
+	}
+	prices, err := cfg.prices()
+	if err != nil {
+		return Layout{}, err
+	}
+	tickers, err := e.market.GetTicker(ctx, cfg.Market)
+	if err != nil {
+		return Layout{}, err
+	}
+	if len(tickers) == 0 {
+		return Layout{}, fmt.Errorf("no current price for %s", cfg.Market)
+	}
+
+	l := Layout{
+		Prices:       prices,
+		CellAmount:   cfg.Capital.Div(upbit.DecimalFromInt(int64(cfg.Levels-1)), volumePlaces),
+		CurrentPrice: tickers[0].TradePrice,
+	}
+	for _, p := range prices[:len(prices)-1] {
+		if p.LessThan(l.CurrentPrice) {
+			l.BuyCells++
+		} else {
+			l.SellCells++
+		}
+	}
+	l.InitialBuy = l.CellAmount.Mul(upbit.DecimalFromInt(int64(l.SellCells)))
+	return l, nil
+}
+
+// Start 그리드를 시작한다. 현재가 아래 칸은 매수 주문을 내고, 위 칸은 시장가로 함께 매수한 수량을 나누어 매도 주문을 낸다.
+// 주문하기 전에 시작 중 상태로 먼저 저장한다. 주문 중 하나라도 실패하거나 시작한 그리드를 저장하지 못하면
+// 이미 낸 주문을 취소하고 오류를 반환한다. 초기 매수한 수량은 계좌에 남는다.
+func (e *Engine) Start(ctx context.Context, cfg Config) (Grid, error) {
+	layout, err := e.Preview(ctx, cfg)
+	if err != nil {
+		return Grid{}, err
+	}
+
+	now := time.Now()
+	g := &Grid{
+		ID:             uuid.NewString(),
+		Config:         cfg,
+		State:          StateStarting,
+		RealizedProfit: "0",
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for i := 0; i < cfg.Levels-1; i++ {
+		buy := layout.Prices[i]
+		g.Cells = append(g.Cells, Cell{
+			BuyPrice:  buy,
+			SellPrice: layout.Prices[i+1],
+			Volume:    layout.CellAmount.Div(buy, volumePlaces),
+			Side:      "bid",
+		})
+	}
+
+	e.mu.Lock()
+	e.grids = append(e.grids, g)
+	if err := e.save(); err != nil {
+		e.grids = e.grids[:len(e.grids)-1]
+		e.mu.Unlock()
+		return Grid{}, err
+	}
+	work := g.copy()
+	e.mu.Unlock()
+
+	// 시작 중인 그리드는 확인과 중지의 대상이 아니므로 복사본을 그대로 반영한다
+	if err := e.open(ctx, &work, layout); err != nil {
+		work.stop(err.Error())
+		e.mu.Lock()
+		*g = work
+		if saveErr := e.save(); saveErr != nil {
+			log.Printf("grid %s: could not save the failed start: %v", work.ID, saveErr)
+		}
+		e.mu.Unlock()
+		return Grid{}, err
+	}
+
+	work.State = StateRunning
+	e.mu.Lock()
+	*g = work.copy()
+	err = e.save()
+	e.mu.Unlock()
+	if err == nil {
+		return work, nil
+	}
+
+	e.cancelAll(ctx, &work)
+	work.stop(fmt.Sprintf("could not be saved, the orders placed were canceled: %v", err))
+	e.mu.Lock()
+	*g = work
+	e.mu.Unlock()
+	return Grid{}, err
+}
+
+// open 시작할 때의 초기 매수와 칸 주문을 낸다. 칸 주문이 실패하면 이미 낸 주문을 취소한다
+func (e *Engine) open(ctx context.Context, g *Grid, layout Layout) error {
+	if layout.SellCells > 0 {
+		if err := e.buyInitial(ctx, g, layout); err != nil {
+			return err
+		}
+	}
+	for i := range g.Cells {
+		if err := e.place(ctx, g, i); err != nil {
+			e.cancelAll(ctx, g)
+			if g.InitialBuyUuid != "" {
+				return fmt.Errorf("grid order could not be placed, the orders already placed were canceled and the %s %s bought by the initial order %s is kept: %w", g.HeldVolume(), g.Market, g.InitialBuyUuid, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// List 그리드 목록
+func (e *Engine) List() []Grid {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	res := make([]Grid, 0, len(e.grids))
+	for _, g := range e.grids {
+		res = append(res, g.copy())
+	}
+	return res
+}
+
+// Get 그리드 하나
+func (e *Engine) Get(id string) (Grid, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	g := e.find(id)
+	if g == nil {
+		return Grid{}, newError(ErrGridNotFound, "grid %s not found", id)
+	}
+	return g.copy(), nil
+}
+
+// Stop 그리드의 대기 주문을 모두 취소하고 중지한다. 매수해 둔 수량은 매도하지 않고 계좌에 남긴다.
+// 취소 전에 체결을 한 번 더 확인해 수익에 반영한다.
+func (e *Engine) Stop(ctx context.Context, id string) (Grid, error) {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	g := e.find(id)
+	if g == nil {
+		e.mu.Unlock()
+		return Grid{}, newError(ErrGridNotFound, "grid %s not found", id)
+	}
+	if g.State != StateRunning {
+		e.mu.Unlock()
+		return Grid{}, newError(ErrNotRunning, "grid %s is already %s", id, g.State)
+	}
+	work := g.copy()
+	e.mu.Unlock()
+
+	if err := e.sync(ctx, &work); err != nil {
+		log.Printf("grid %s: could not check fills before stopping: %v", work.ID, err)
+	}
+	e.cancelAll(ctx, &work)
+	work.stop("")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if g := e.find(id); g != nil {
+		*g = work.copy()
+	}
+	if err := e.save(); err != nil {
+		return Grid{}, err
+	}
+	return work, nil
+}
+
+// Run ctx가 끝날 때까지 주기적으로 체결을 확인한다
+func (e *Engine) Run(ctx context.Context) {
+	e.base.Run(ctx, "grids", e.Check)
+}
+
+// Check 실행 중인 그리드의 체결을 한 번 확인한다.
+// 잠금을 잡고 복사본을 만든 뒤 조회와 주문은 잠금 밖에서 하고 바뀐 복사본만 다시 잠금을 잡고 반영한다.
+func (e *Engine) Check(ctx context.Context) error {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	var work []Grid
+	for _, g := range e.grids {
+		if g.State == StateRunning {
+			work = append(work, g.copy())
+		}
+	}
+	e.mu.Unlock()
+
+	var changed []Grid
+	for i := range work {
+		w := &work[i]
+		updated := w.UpdatedAt
+		if err := e.sync(ctx, w); err != nil {
+			log.Printf("grid %s: %v", w.ID, err)
+		}
+		if !w.UpdatedAt.Equal(updated) {
+			changed = append(changed, *w)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, w := range changed {
+		if g := e.find(w.ID); g != nil {
+			*g = w
+		}
+	}
+	return e.save()
+}
+
+// sync 대기 주문 목록에서 사라진 주문을 조회해 체결되었으면 반대쪽 주문을 내고,
+// 일시적인 오류로 내지 못한 주문을 다시 낸다
+func (e *Engine) sync(ctx context.Context, g *Grid) error {
+	open, err := e.openOrders(ctx, g.Market)
+	if err != nil {
+		return err
+	}
+
+	for i := range g.Cells {
+		c := &g.Cells[i]
+		if c.Side == "" {
+			continue
+		}
+		if c.OrderUuid != "" {
+			if open[c.OrderUuid] {
+				continue
+			}
+			order, err := e.trader.GetOrder(ctx, c.OrderUuid)
+			if err != nil {
+				// 한 칸의 조회 실패로 나머지 칸의 확인이 멈추지 않도록 다음 확인으로 미룬다
+				log.Printf("grid %s: cell %d: %v", g.ID, i, err)
+				continue
+			}
+			if order.IsOpen() {
+				continue
+			}
+			e.settle(g, c, order)
+		}
+		if c.Side != "" && c.OrderUuid == "" {
+			if err := e.place(ctx, g, i); err != nil {
+				log.Printf("grid %s: %v", g.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// settle 끝난 주문을 칸에 반영한다. 매수가 체결되면 매도로, 매도가 체결되면 수익을 기록하고 매수로 바꾼다.
+// 그리드 밖에서 취소된 주문은 사용자가 원한 것으로 보고 그 칸의 매매를 멈추되,
+// 취소 전에 일부 체결된 수량은 held_volume과 실현 수익에 반영해 계좌에 남은 수량을 추적한다.
+func (e *Engine) settle(g *Grid, c *Cell, order upbit.Order) {
+	c.OrderUuid = ""
+	g.UpdatedAt = time.Now()
+
+	funds := order.SettledFunds()
+	if order.State != upbit.OrderStateDone {
+		c.Side = ""
+		c.Note = fmt.Sprintf("order %s was canceled outside the grid", order.Uuid)
+		if order.ExecutedVolume.Sign() <= 0 {
+			return
+		}
+
+		switch order.Side {
+		case "bid":
+			c.Held = order.ExecutedVolume
+			c.Cost = funds.Add(order.PaidFee)
+			c.Note += fmt.Sprintf(" after buying %s, which is kept in the account", order.ExecutedVolume)
+		case "ask":
+			// 판 수량만큼의 매수 비용으로 수익을 계산하고 나머지는 보유 수량으로 남긴다
+			cost := c.Cost.Mul(order.ExecutedVolume).Div(c.Held, volumePlaces)
+			g.RealizedProfit = g.RealizedProfit.Add(funds.Sub(order.PaidFee).Sub(cost))
+			c.Held = c.Held.Sub(order.ExecutedVolume)
+			c.Cost = c.Cost.Sub(cost)
+			c.Note += fmt.Sprintf(" after selling %s, the remaining %s is kept in the account", order.ExecutedVolume, c.Held)
+		}
+		return
+	}
+
+	switch order.Side {
+	case "bid":
+		c.Held = order.ExecutedVolume
+		c.Cost = funds.Add(order.PaidFee)
+		c.Side = "ask"
+	case "ask":
+		proceeds := funds.Sub(order.PaidFee)
+		g.RealizedProfit = g.RealizedProfit.Add(proceeds.Sub(c.Cost))
+		g.RoundTrips++
+		c.RoundTrips++
+		c.Held = ""
+		c.Cost = ""
+		c.Side = "bid"
+	}
+}
+
+// place 칸의 현재 방향으로 지정가 주문을 낸다. 일시적인 오류는 다음 확인에서 다시 시도하고,
+// 거래소가 거절한 주문은 그 칸의 매매를 멈춘다
+func (e *Engine) place(ctx context.Context, g *Grid, i int) error {
+	c := &g.Cells[i]
+	params := upbit.RequestParams{
+		Market:     g.Market,
+		Side:       c.Side,
+		OrdType:    upbit.OrdTypeLimit,
+		Price:      c.BuyPrice,
+		Volume:     c.Volume,
+		SmpType:    upbit.SmpCancelMaker,
+		Identifier: fmt.Sprintf("%s%s-%d-%d", IdentifierPrefix, g.ID, i, c.Orders),
+	}
+	if c.Side == "ask" {
+		params.Price = c.SellPrice
+		params.Volume = c.Held
+	}
+
+	order, err := e.trader.PlaceOrder(ctx, params)
+	if err != nil {
+		if !upbit.IsTemporary(err) {
+			g.UpdatedAt = time.Now()
+			c.Side = ""
+			c.Note = fmt.Sprintf("%s order at %s was rejected: %v", params.Side, params.Price, err)
+		}
+		return fmt.Errorf("cell %d: %w", i, err)
+	}
+	c.Orders++
+	c.OrderUuid = order.Uuid
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+// buyInitial 현재가 위 칸에서 매도할 수량을 시장가로 한 번에 매수하고 칸마다 나누어 준다
+func (e *Engine) buyInitial(ctx context.Context, g *Grid, layout Layout) error {
+	order, err := e.trader.PlaceOrder(ctx, upbit.RequestParams{
+		Market:     g.Market,
+		Side:       "bid",
+		OrdType:    upbit.OrdTypePrice,
+		Price:      layout.InitialBuy,
+		SmpType:    upbit.SmpCancelMaker,
+		Identifier: IdentifierPrefix + g.ID + "-init",
+	})
+	if err != nil {
+		return err
+	}
+	g.InitialBuyUuid = order.Uuid
+
+	order, err = upbit.WaitOrderClosed(ctx, e.trader, order.Uuid, initialBuyTimeout, pollInterval)
+	if err != nil {
+		return err
+	}
+	if order.ExecutedVolume.Sign() <= 0 {
+		return fmt.Errorf("initial market buy order %s was not filled", order.Uuid)
+	}
+
+	n := upbit.DecimalFromInt(int64(layout.SellCells))
+	share := order.ExecutedVolume.Div(n, volumePlaces)
+	cost := order.SettledFunds().Add(order.PaidFee).Div(n, volumePlaces)
+	for i := range g.Cells {
+		c := &g.Cells[i]
+		if c.BuyPrice.LessThan(layout.CurrentPrice) {
+			continue
+		}
+		c.Side = "ask"
+		c.Held = share
+		c.Cost = cost
+	}
+	return nil
+}
+
+// stop 그리드를 중지 상태로 바꾼다. note는 주문 취소 실패와 함께 Note에 남긴다
+func (g *Grid) stop(note string) {
+	now := time.Now()
+	g.State = StateStopped
+	g.StoppedAt = &now
+	g.UpdatedAt = now
+	if note != "" && g.Note != "" {
+		note += "; " + g.Note
+	}
+	if note != "" {
+		g.Note = note
+	}
+}
+
+// cancelAll 칸의 대기 주문을 모두 취소한다. 취소하지 못한 주문은 그리드의 Note에 남긴다
+func (e *Engine) cancelAll(ctx context.Context, g *Grid) {
+	var failed []string
+	for i := range g.Cells {
+		c := &g.Cells[i]
+		if c.OrderUuid == "" {
+			continue
+		}
+		if _, err := e.trader.CancelOrder(ctx, c.OrderUuid); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", c.OrderUuid, err))
+			continue
+		}
+		c.OrderUuid = ""
+	}
+	if len(failed) > 0 {
+		g.Note = fmt.Sprintf("could not cancel orders: %v", failed)
+	}
+}
+
+// openOrders 마켓의 대기 주문 UUID 집합
+func (e *Engine) openOrders(ctx context.Context, market string) (map[string]bool, error) {
+	const pageSize = 100
+
+	open := map[string]bool{}
+	for page := 1; ; page++ {
+		res, err := e.trader.GetOpenOrders(ctx, upbit.RequestParams{Market: market, Page: page, Limit: pageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range res {
+			open[o.Uuid] = true
+		}
+		if len(res) < pageSize {
+			return open, nil
+		}
+	}
+}
+
+func (e *Engine) find(id string) *Grid {
+	for _, g := range e.grids {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+func (e *Engine) save() error {
+	return e.base.Save(stateName, e.grids)
+}
+
+// copy 잠금 밖으로 내보낼 복사본
+func (g *Grid) copy() Grid {
+	c := *g
+	c.Cells = append([]Cell(nil), g.Cells...)
+	return c
+}
+
+func (cfg Config) validate() error {
+	if cfg.Market == "" {
+		return newError(ErrInvalidGrid, "market is required")
+	}
+	if cfg.Lower.Sign() <= 0 || !cfg.Upper.GreaterThan(cfg.Lower) {
+		return newError(ErrInvalidGrid, "upper_price must be greater than lower_price, and both must be positive")
+	}
+	if cfg.Levels < 2 || cfg.Levels > MaxLevels {
+		return newError(ErrInvalidGrid, "levels must be between 2 and %d", MaxLevels)
+	}
+	if cfg.Capital.Sign() <= 0 {
+		return newError(ErrInvalidGrid, "capital must be positive")
+	}
+	return nil
+}
+
+// prices 구간을 같은 간격으로 나눈 가격을 호가 단위에 맞춘다. 맞춘 뒤 겹치는 가격이 생기면 구간이 너무 좁은 것이다
+func (cfg Config) prices() ([]upbit.Decimal, error) {
+	step := cfg.Upper.Sub(cfg.Lower).Div(upbit.DecimalFromInt(int64(cfg.Levels-1)), volumePlaces)
+	prices := make([]upbit.Decimal, cfg.Levels)
+	for i := range prices {
+		p := cfg.Lower.Add(step.Mul(upbit.DecimalFromInt(int64(i))))
+		if i == cfg.Levels-1 {
+			p = cfg.Upper
+		}
+		normalized, err := upbit.NormalizePrice(cfg.Market, p, upbit.RoundNearest)
+		if err != nil {
+			return nil, newError(ErrInvalidGrid, "%v", err)
+		}
+		if i > 0 && !normalized.GreaterThan(prices[i-1]) {
+			return nil, newError(ErrInvalidGrid, "the price range is too narrow for %d levels at the tick size of %s", cfg.Levels, cfg.Market)
+		}
+		prices[i] = normalized
+	}
+	return prices, nil
+}
@@ -0,0 +1,112 @@
+package grid_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"upbit-mcp-server/grid"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+// startGrid 현재가 1억 원에서 9천만, 1억, 1억 1천만 원 세 가격으로 그리드를 시작한다.
+// 아래 칸은 9천만 원에 매수, 위 칸은 시장가로 산 0.001 BTC를 1억 1천만 원에 매도하며 기다린다.
+func startGrid(t *testing.T) (*upbittest.Exchange, *grid.Engine, grid.Grid) {
+	t.Helper()
+
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	e, err := grid.NewEngine(ex.Client(), ex.Client(), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := e.Start(context.Background(), grid.Config{Market: "KRW-BTC", Lower: "90000000", Upper: "110000000", Levels: 3, Capital: "200000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.State != grid.StateRunning || g.Cells[0].Side != "bid" || g.Cells[1].Side != "ask" || !g.Cells[1].Held.Equal("0.001") {
+		t.Fatalf("started grid = %s, cells %+v", g.State, g.Cells)
+	}
+	return ex, e, g
+}
+
+func TestFillPlacesOppositeOrder(t *testing.T) {
+	ex, e, g := startGrid(t)
+	ctx := context.Background()
+
+	// 9천만 원에 매수가 체결되면 같은 수량을 1억 원에 매도한다
+	ex.SetPrice("KRW-BTC", "90000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.Get(g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := got.Cells[0]
+	if c.Side != "ask" || !c.Held.Equal("0.00111111") || !c.Cost.Equal("100049.89995") {
+		t.Fatalf("cell after buy = %+v", c)
+	}
+	order, ok := ex.Order(c.OrderUuid)
+	if !ok || order.Side != "ask" || !order.Price.Equal("100000000") || !order.Volume.Equal("0.00111111") || order.State != upbit.OrderStateWait {
+		t.Fatalf("sell order = %+v", order)
+	}
+
+	// 1억 원에 매도가 체결되면 수수료를 뺀 수익을 기록하고 다시 9천만 원에 매수한다
+	ex.SetPrice("KRW-BTC", "100000000")
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err = e.Get(g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = got.Cells[0]
+	// 111,111 - 수수료 55.5555 - 매수 비용 100,049.89995
+	if !got.RealizedProfit.Equal("11005.54455") || got.RoundTrips != 1 || c.RoundTrips != 1 {
+		t.Errorf("profit = %s, round trips %d/%d", got.RealizedProfit, got.RoundTrips, c.RoundTrips)
+	}
+	if c.Side != "bid" || c.Held != "" || c.Orders != 3 {
+		t.Errorf("cell after sell = %+v", c)
+	}
+	order, ok = ex.Order(c.OrderUuid)
+	if !ok || order.Side != "bid" || !order.Price.Equal("90000000") || order.Identifier != "grid-"+g.ID+"-0-2" {
+		t.Errorf("buy order = %+v", order)
+	}
+	if got.Cells[1].Side != "ask" || got.Cells[1].RoundTrips != 0 {
+		t.Errorf("upper cell = %+v", got.Cells[1])
+	}
+}
+
+func TestExternalCancelAfterPartialFill(t *testing.T) {
+	ex, e, g := startGrid(t)
+	ctx := context.Background()
+
+	// 9천만 원 매수 주문의 일부만 체결된 뒤 그리드 밖에서 취소된다
+	ex.SetOrderBook(upbit.OrderBook{Market: "KRW-BTC", OrderbookUnits: []upbit.OrderBookUnit{{AskPrice: "90000000", AskSize: "0.0005"}}})
+	ex.SetOrderBook(upbit.OrderBook{Market: "KRW-BTC"})
+	if _, err := ex.Client().CancelOrder(ctx, g.Cells[0].OrderUuid); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.Get(g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := got.Cells[0]
+	// 산 수량과 비용(45,000 + 수수료 22.5)은 칸에 남기고 그 칸의 매매는 멈춘다
+	if c.Side != "" || c.OrderUuid != "" || !c.Held.Equal("0.0005") || !c.Cost.Equal("45022.5") {
+		t.Errorf("canceled cell = %+v", c)
+	}
+	if !strings.Contains(c.Note, "canceled outside the grid") {
+		t.Errorf("note = %q", c.Note)
+	}
+	if !got.HeldVolume().Equal("0.0015") || !got.RealizedProfit.IsZero() {
+		t.Errorf("held = %s, profit %s", got.HeldVolume(), got.RealizedProfit)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 3 {
+		t.Errorf("orders placed = %d, want 3", n)
+	}
+}
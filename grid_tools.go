@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"upbit-mcp-server/grid"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// gridEngineKey는 context 내에서 그리드 엔진을 식별하기 위한 키
+type gridEngineKey struct{}
+
+type StartGridRequest struct {
+	Market     string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	LowerPrice upbit.Decimal `json:"lower_price" jsonschema:"Lowest grid price."`
+	UpperPrice upbit.Decimal `json:"upper_price" jsonschema:"Highest grid price."`
+	Levels     int           `json:"levels" jsonschema:"Number of evenly spaced grid prices from lower_price to upper_price (2-100). Each pair of neighbouring prices is one cell that buys at the lower and sells at the upper price."`
+	Capital    upbit.Decimal `json:"capital" jsonschema:"Total amount in the quote currency to commit, split evenly between the levels-1 cells. Each cell's amount must meet the minimum order total."`
+	DryRun     bool          `json:"dry_run,omitempty" jsonschema:"Optional. Only return the grid layout for the current price without placing orders."`
+}
+
+type GridIDRequest struct {
+	ID string `json:"id" jsonschema:"Grid ID."`
+}
+
+// GridResult 그리드 상태와 요약
+type GridResult struct {
+	grid.Grid
+	HeldVolume upbit.Decimal `json:"held_volume" jsonschema:"Volume bought by the grid and not yet sold."`
+	OpenOrders int           `json:"open_orders" jsonschema:"Number of cells with an open order."`
+}
+
+// GridSummary 그리드 목록의 한 항목
+type GridSummary struct {
+	ID string `json:"id"`
+	grid.Config
+	State          string        `json:"state"`
+	RealizedProfit upbit.Decimal `json:"realized_profit"`
+	RoundTrips     int           `json:"round_trips"`
+	HeldVolume     upbit.Decimal `json:"held_volume"`
+	OpenOrders     int           `json:"open_orders"`
+}
+
+type StartGridResult struct {
+	Layout grid.Layout `json:"layout"`
+	Grid   *GridResult `json:"grid,omitempty" jsonschema:"The started grid. Absent for a dry run."`
+}
+
+type GetGridsResult struct {
+	Grids []GridSummary `json:"grids"`
+}
+
+func StartGrid(ctx context.Context, req *mcp.CallToolRequest, params *StartGridRequest) (
+	*mcp.CallToolResult,
+	*StartGridResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(gridEngineKey{}).(*grid.Engine)
+	if !ok {
+		return nil, nil, missingDependency("grid engine")
+	}
+
+	cfg := grid.Config{
+		Market:  params.Market,
+		Lower:   params.LowerPrice,
+		Upper:   params.UpperPrice,
+		Levels:  params.Levels,
+		Capital: params.Capital,
+	}
+	layout, err := engine.Preview(ctx, cfg)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	if params.DryRun {
+		return &res, &StartGridResult{Layout: layout}, nil
+	}
+
+	if err := confirmGrid(ctx, req, cfg, layout); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	g, err := engine.Start(ctx, cfg)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &StartGridResult{Layout: layout, Grid: gridResult(g)}, nil
+}
+
+func GetGrids(ctx context.Context, req *mcp.CallToolRequest, params any) (
+	*mcp.CallToolResult,
+	*GetGridsResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(gridEngineKey{}).(*grid.Engine)
+	if !ok {
+		return nil, nil, missingDependency("grid engine")
+	}
+
+	result := &GetGridsResult{Grids: []GridSummary{}}
+	for _, g := range engine.List() {
+		r := gridResult(g)
+		result.Grids = append(result.Grids, GridSummary{
+			ID:             g.ID,
+			Config:         g.Config,
+			State:          g.State,
+			RealizedProfit: g.RealizedProfit,
+			RoundTrips:     g.RoundTrips,
+			HeldVolume:     r.HeldVolume,
+			OpenOrders:     r.OpenOrders,
+		})
+	}
+
+	return &res, result, nil
+}
+
+func GetGrid(ctx context.Context, req *mcp.CallToolRequest, params *GridIDRequest) (
+	*mcp.CallToolResult,
+	*GridResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(gridEngineKey{}).(*grid.Engine)
+	if !ok {
+		return nil, nil, missingDependency("grid engine")
+	}
+
+	g, err := engine.Get(params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, gridResult(g), nil
+}
+
+func StopGrid(ctx context.Context, req *mcp.CallToolRequest, params *GridIDRequest) (
+	*mcp.CallToolResult,
+	*GridResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(gridEngineKey{}).(*grid.Engine)
+	if !ok {
+		return nil, nil, missingDependency("grid engine")
+	}
+
+	g, err := engine.Stop(ctx, params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, gridResult(g), nil
+}
+
+func gridResult(g grid.Grid) *GridResult {
+	r := &GridResult{Grid: g, HeldVolume: g.HeldVolume()}
+	for _, c := range g.Cells {
+		if c.OrderUuid != "" {
+			r.OpenOrders++
+		}
+	}
+	return r
+}
+
+// confirmGrid 그리드가 낼 주문을 한 번에 확인받는다.
+// 시작할 때 시장가로 매수하거나 투입 금액이 지정가 확인 기준을 넘으면 확인 대상이다.
+func confirmGrid(ctx context.Context, req *mcp.CallToolRequest, cfg grid.Config, layout grid.Layout) error {
+	policy, ok := ctx.Value(confirmPolicyKey{}).(*confirmPolicy)
+	if !ok || (layout.InitialBuy.IsZero() && !cfg.Capital.GreaterThan(policy.LimitAbove)) {
+		return nil
+	}
+
+	quote, _, _ := strings.Cut(cfg.Market, "-")
+	var b strings.Builder
+	fmt.Fprintf(&b, "Confirm Upbit grid\n\nmarket: %s\nprice range: %s - %s %s (%d levels)\ncapital: %s %s (%s %s per cell)\n",
+		cfg.Market, cfg.Lower, cfg.Upper, quote, cfg.Levels, cfg.Capital, quote, layout.CellAmount, quote)
+	fmt.Fprintf(&b, "current price: %s %s\nlimit buy orders: %d\nlimit sell orders: %d\n", layout.CurrentPrice, quote, layout.BuyCells, layout.SellCells)
+	if !layout.InitialBuy.IsZero() {
+		fmt.Fprintf(&b, "market buy now: %s %s (sold by the sell orders)\n", layout.InitialBuy, quote)
+	}
+	return elicitConfirmation(ctx, req, policy, b.String())
+}
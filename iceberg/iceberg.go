@@ -0,0 +1,513 @@
+// Package iceberg 큰 지정가 주문을 작은 주문 하나만 호가창에 보이게 나누어 내고, 체결될 때마다 숨겨 둔 나머지로 다시 채우는 빙산 주문을 실행한다.
+package iceberg
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/engine"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// DefaultInterval 보이는 주문의 체결을 확인하는 기본 주기
+const DefaultInterval = 5 * time.Second
+
+// MaxOrders 빙산 주문 하나를 나눌 수 있는 최대 주문 수
+const MaxOrders = 200
+
+// IdentifierPrefix 빙산 주문이 낸 주문의 identifier 접두사. 뒤에 빙산 주문 ID와 주문 순번이 붙는다
+const IdentifierPrefix = "ice-"
+
+// stateName 빙산 주문을 저장하는 파일 이름
+const stateName = "iceberg"
+
+// volumePlaces 주문 수량의 소수점 자릿수
+const volumePlaces = 8
+
+// 빙산 주문 상태
+const (
+	StateStarting  = "starting" // 저장했고 첫 주문을 내는 중
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateCanceled  = "canceled"
+	StateFailed    = "failed"
+)
+
+// 빙산 주문을 시작하거나 취소하지 못한 이유
+const (
+	ErrInvalidIceberg  = "invalid_iceberg"
+	ErrIcebergNotFound = "iceberg_not_found"
+	ErrNotRunning      = "iceberg_not_running"
+)
+
+// Error 빙산 주문 요청이 거절된 이유
+type Error = engine.Error
+
+func newError(name, format string, args ...any) error {
+	return engine.NewError("iceberg", name, format, args...)
+}
+
+// Config 빙산 주문 설정
+type Config struct {
+	Market        string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Side          string        `json:"side" jsonschema:"bid or ask."`
+	Price         upbit.Decimal `json:"price" jsonschema:"Limit price of every child order, aligned to the tick size."`
+	Volume        upbit.Decimal `json:"volume" jsonschema:"Total volume to buy or sell."`
+	VisibleVolume upbit.Decimal `json:"visible_volume" jsonschema:"Volume of the child order shown in the order book at a time."`
+}
+
+// Child 빙산 주문이 낸 지정가 주문 하나
+type Child struct {
+	OrderUuid      string        `json:"order_uuid"`
+	Volume         upbit.Decimal `json:"volume"`
+	State          string        `json:"state" jsonschema:"wait while the order is live, then done or cancel."`
+	ExecutedVolume upbit.Decimal `json:"executed_volume"`
+	ExecutedFunds  upbit.Decimal `json:"executed_funds"`
+	PaidFee        upbit.Decimal `json:"paid_fee"`
+}
+
+// Iceberg 실행 중이거나 끝난 빙산 주문
+type Iceberg struct {
+	ID string `json:"id"`
+	Config
+	State      string     `json:"state" jsonschema:"starting, running, completed, canceled or failed."`
+	Children   []Child    `json:"child_orders"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	ExecutedVolume upbit.Decimal `json:"executed_volume" jsonschema:"Volume filled so far, including partial fills of the live child order."`
+	ExecutedFunds  upbit.Decimal `json:"executed_funds" jsonschema:"Executed amount in the quote currency, fees excluded."`
+	PaidFee        upbit.Decimal `json:"paid_fee"`
+	AvgPrice       upbit.Decimal `json:"avg_price"`
+	HiddenVolume   upbit.Decimal `json:"hidden_volume" jsonschema:"Volume not ordered yet, used to refill the visible child order."`
+	Note           string        `json:"note,omitempty"`
+}
+
+// Live 호가창에 올라가 있는 주문. 없으면 nil
+func (ib *Iceberg) Live() *Child {
+	if n := len(ib.Children); n > 0 && ib.Children[n-1].State == upbit.OrderStateWait {
+		return &ib.Children[n-1]
+	}
+	return nil
+}
+
+// Engine 빙산 주문을 보관하고 보이는 주문이 체결되면 다음 주문을 낸다
+type Engine struct {
+	trader upbit.Trader
+	base   engine.Base
+
+	// checkMu 체결 확인과 취소가 겹쳐 같은 주문을 두 번 내지 않도록 한다
+	checkMu sync.Mutex
+
+	// mu 빙산 주문 목록을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu       sync.Mutex
+	icebergs []*Iceberg
+}
+
+// NewEngine 빙산 주문 엔진 생성. st가 nil이면 빙산 주문을 메모리에만 보관한다.
+func NewEngine(trader upbit.Trader, st *store.Store, interval time.Duration) (*Engine, error) {
+	e := &Engine{
+		trader: trader,
+		base:   engine.NewBase(st, interval, DefaultInterval),
+	}
+	if err := e.base.Load(stateName, &e.icebergs); err != nil {
+		return nil, err
+	}
+	for _, ib := range e.icebergs {
+		if ib.State == StateStarting {
+			ib.finish(StateFailed, fmt.Sprintf("interrupted by a server restart while the first order (identifier %s%s-0) was placed", IdentifierPrefix, ib.ID))
+		}
+	}
+	return e, nil
+}
+
+// Start 빙산 주문을 starting 상태로 저장한 뒤 첫 주문을 내고 시작한다. 첫 주문이 거절되면 빙산 주문을 만들지 않고,
+// 주문한 뒤 저장하지 못하면 첫 주문을 취소하고 failed로 남긴다.
+func (e *Engine) Start(ctx context.Context, cfg Config) (Iceberg, error) {
+	if err := cfg.validate(); err != nil {
+		return Iceberg{}, err
+	}
+	chance, err := e.trader.GetChance(ctx, cfg.Market)
+	if err != nil {
+		return Iceberg{}, err
+	}
+	// 마지막 주문은 보이는 수량보다 작아지지 않으므로 보이는 주문 하나만 최소 주문 금액을 넘으면 된다
+	minTotal := chance.Market.Bid.MinTotal
+	if cfg.Side == "ask" {
+		minTotal = chance.Market.Ask.MinTotal
+	}
+	if total := cfg.VisibleVolume.Mul(cfg.Price); total.LessThan(minTotal) {
+		return Iceberg{}, newError(ErrInvalidIceberg, "visible order total %s is below the minimum order total %s of %s", total, minTotal, cfg.Market)
+	}
+
+	now := time.Now()
+	ib := &Iceberg{
+		ID:        uuid.NewString(),
+		Config:    cfg,
+		State:     StateStarting,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	ib.report()
+
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	e.icebergs = append(e.icebergs, ib)
+	if err := e.save(); err != nil {
+		e.icebergs = e.icebergs[:len(e.icebergs)-1]
+		e.mu.Unlock()
+		return Iceberg{}, err
+	}
+	work := ib.copy()
+	e.mu.Unlock()
+
+	// 시작 중인 빙산 주문은 확인과 취소의 대상이 아니므로 복사본을 그대로 반영한다
+	if err := e.place(ctx, &work); err != nil {
+		e.mu.Lock()
+		e.remove(ib.ID)
+		if saveErr := e.save(); saveErr != nil {
+			log.Printf("iceberg %s: could not save the rejected start: %v", ib.ID, saveErr)
+		}
+		e.mu.Unlock()
+		return Iceberg{}, err
+	}
+	work.State = StateRunning
+	work.report()
+
+	e.mu.Lock()
+	*ib = work.copy()
+	err = e.save()
+	e.mu.Unlock()
+	if err == nil {
+		return work, nil
+	}
+
+	e.rollback(ctx, &work)
+	work.finish(StateFailed, fmt.Sprintf("could not be saved, the first order was canceled: %v", err))
+	e.mu.Lock()
+	*ib = work
+	e.mu.Unlock()
+	return Iceberg{}, err
+}
+
+// rollback 시작을 저장하지 못한 빙산 주문의 보이는 주문을 취소하고 취소되기 전의 체결을 반영한다
+func (e *Engine) rollback(ctx context.Context, ib *Iceberg) {
+	c := ib.Live()
+	if c == nil {
+		return
+	}
+	if _, err := e.trader.CancelOrder(ctx, c.OrderUuid); err != nil {
+		log.Printf("iceberg %s: could not cancel order %s: %v", ib.ID, c.OrderUuid, err)
+	}
+	if order, err := e.trader.GetOrder(ctx, c.OrderUuid); err != nil {
+		log.Printf("iceberg %s: could not get canceled order %s: %v", ib.ID, c.OrderUuid, err)
+	} else {
+		settle(c, order)
+	}
+}
+
+// List 빙산 주문 목록
+func (e *Engine) List() []Iceberg {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	res := make([]Iceberg, 0, len(e.icebergs))
+	for _, ib := range e.icebergs {
+		res = append(res, ib.copy())
+	}
+	return res
+}
+
+// Get 빙산 주문 하나
+func (e *Engine) Get(id string) (Iceberg, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ib := e.find(id)
+	if ib == nil {
+		return Iceberg{}, newError(ErrIcebergNotFound, "iceberg %s not found", id)
+	}
+	return ib.copy(), nil
+}
+
+// Cancel 보이는 주문을 취소하고 빙산 주문을 끝낸다. 이미 체결된 수량은 그대로 남는다
+func (e *Engine) Cancel(ctx context.Context, id string) (Iceberg, error) {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	found := e.find(id)
+	if found == nil {
+		e.mu.Unlock()
+		return Iceberg{}, newError(ErrIcebergNotFound, "iceberg %s not found", id)
+	}
+	if found.State != StateRunning {
+		e.mu.Unlock()
+		return Iceberg{}, newError(ErrNotRunning, "iceberg %s is already %s", id, found.State)
+	}
+	work := found.copy()
+	e.mu.Unlock()
+
+	ib := &work
+
+	note := "canceled by the user"
+	if c := ib.Live(); c != nil {
+		if _, cancelErr := e.trader.CancelOrder(ctx, c.OrderUuid); cancelErr != nil {
+			// 취소하기 전에 전부 체결되었으면 체결을 반영하고 숨겨 둔 나머지만 거두어들인다
+			order, err := e.trader.GetOrder(ctx, c.OrderUuid)
+			if err != nil || order.IsOpen() {
+				return Iceberg{}, cancelErr
+			}
+			settle(c, order)
+		} else if order, err := e.trader.GetOrder(ctx, c.OrderUuid); err != nil {
+			// 취소 응답에는 마지막 체결이 빠질 수 있으므로 다시 조회한다
+			log.Printf("iceberg %s: could not get canceled order %s: %v", ib.ID, c.OrderUuid, err)
+			c.State = upbit.OrderStateCancel
+		} else {
+			settle(c, order)
+		}
+	}
+	ib.report()
+	if !ib.ExecutedVolume.LessThan(ib.Volume) {
+		ib.finish(StateCompleted, "")
+	} else {
+		ib.finish(StateCanceled, note)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if found := e.find(id); found != nil {
+		*found = ib.copy()
+	}
+	if err := e.save(); err != nil {
+		return Iceberg{}, err
+	}
+	return ib.copy(), nil
+}
+
+// Run ctx가 끝날 때까지 주기적으로 체결을 확인한다
+func (e *Engine) Run(ctx context.Context) {
+	e.base.Run(ctx, "icebergs", e.Check)
+}
+
+// Check 실행 중인 빙산 주문의 보이는 주문을 조회해 체결되었으면 숨겨 둔 나머지로 다음 주문을 낸다.
+// 잠금을 잡고 복사본을 만든 뒤 조회와 주문은 잠금 밖에서 하고 바뀐 복사본만 다시 잠금을 잡고 반영한다.
+func (e *Engine) Check(ctx context.Context) error {
+	e.checkMu.Lock()
+	defer e.checkMu.Unlock()
+
+	e.mu.Lock()
+	var work []Iceberg
+	for _, ib := range e.icebergs {
+		if ib.State == StateRunning {
+			work = append(work, ib.copy())
+		}
+	}
+	e.mu.Unlock()
+
+	var changed []Iceberg
+	for i := range work {
+		ib := &work[i]
+		updated := ib.UpdatedAt
+		if err := e.sync(ctx, ib); err != nil {
+			log.Printf("iceberg %s: %v", ib.ID, err)
+		}
+		if !ib.UpdatedAt.Equal(updated) {
+			changed = append(changed, *ib)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, w := range changed {
+		if ib := e.find(w.ID); ib != nil {
+			*ib = w
+		}
+	}
+	return e.save()
+}
+
+// sync 빙산 주문 하나를 진행한다. 일시적인 오류로 내지 못한 주문은 다음 확인에서 다시 낸다
+func (e *Engine) sync(ctx context.Context, ib *Iceberg) error {
+	if c := ib.Live(); c != nil {
+		order, err := e.trader.GetOrder(ctx, c.OrderUuid)
+		if err != nil {
+			return err
+		}
+		executed := c.ExecutedVolume
+		settle(c, order)
+		if order.IsOpen() {
+			if !c.ExecutedVolume.Equal(executed) {
+				ib.report()
+				ib.UpdatedAt = time.Now()
+			}
+			return nil
+		}
+		ib.report()
+		ib.UpdatedAt = time.Now()
+		if order.State != upbit.OrderStateDone {
+			ib.finish(StateCanceled, fmt.Sprintf("order %s was canceled outside the iceberg", order.Uuid))
+			return nil
+		}
+	}
+
+	if ib.HiddenVolume.Sign() <= 0 {
+		ib.finish(StateCompleted, "")
+		return nil
+	}
+	if err := e.place(ctx, ib); err != nil {
+		if !upbit.IsTemporary(err) {
+			ib.finish(StateFailed, fmt.Sprintf("order %d was rejected: %v", len(ib.Children), err))
+			return nil
+		}
+		return err
+	}
+	ib.report()
+	return nil
+}
+
+// place 숨겨 둔 나머지에서 보이는 수량만큼 지정가 주문을 낸다.
+// 남은 수량이 보이는 수량의 두 배보다 작으면 최소 주문 금액보다 작은 주문이 남지 않도록 한 번에 낸다
+func (e *Engine) place(ctx context.Context, ib *Iceberg) error {
+	hidden := ib.Volume
+	for _, c := range ib.Children {
+		hidden = hidden.Sub(c.ExecutedVolume)
+	}
+	volume := ib.VisibleVolume
+	if hidden.LessThan(volume.Add(volume)) {
+		volume = hidden
+	}
+
+	order, err := e.trader.PlaceOrder(ctx, upbit.RequestParams{
+		Market:     ib.Market,
+		Side:       ib.Side,
+		OrdType:    upbit.OrdTypeLimit,
+		Price:      ib.Price,
+		Volume:     volume,
+		SmpType:    upbit.SmpCancelMaker,
+		Identifier: fmt.Sprintf("%s%s-%d", IdentifierPrefix, ib.ID, len(ib.Children)),
+	})
+	if err != nil {
+		return err
+	}
+	ib.Children = append(ib.Children, Child{
+		OrderUuid:      order.Uuid,
+		Volume:         volume,
+		State:          upbit.OrderStateWait,
+		ExecutedVolume: "0",
+		ExecutedFunds:  "0",
+		PaidFee:        "0",
+	})
+	ib.UpdatedAt = time.Now()
+	return nil
+}
+
+// settle 주문 조회 결과를 주문 기록에 반영한다
+func settle(c *Child, order upbit.Order) {
+	c.State = order.State
+	c.ExecutedVolume = order.ExecutedVolume
+	c.ExecutedFunds = order.SettledFunds()
+	c.PaidFee = order.PaidFee
+}
+
+// report 주문 기록을 합산해 진행 상황을 계산한다
+func (ib *Iceberg) report() {
+	var volume, funds, fee, ordered upbit.Decimal = "0", "0", "0", "0"
+	for _, c := range ib.Children {
+		volume = volume.Add(c.ExecutedVolume)
+		funds = funds.Add(c.ExecutedFunds)
+		fee = fee.Add(c.PaidFee)
+		if c.State == upbit.OrderStateWait {
+			ordered = ordered.Add(c.Volume)
+		} else {
+			ordered = ordered.Add(c.ExecutedVolume)
+		}
+	}
+	ib.ExecutedVolume, ib.ExecutedFunds, ib.PaidFee = volume, funds, fee
+	ib.HiddenVolume = ib.Volume.Sub(ordered)
+	ib.AvgPrice = "0"
+	if volume.Sign() > 0 {
+		ib.AvgPrice = funds.Div(volume, volumePlaces)
+	}
+}
+
+func (ib *Iceberg) finish(state, note string) {
+	now := time.Now()
+	ib.State = state
+	ib.Note = note
+	ib.UpdatedAt = now
+	ib.FinishedAt = &now
+	ib.report()
+}
+
+// copy 잠금 밖으로 내보낼 복사본
+func (ib *Iceberg) copy() Iceberg {
+	c := *ib
+	c.Children = append([]Child(nil), ib.Children...)
+	return c
+}
+
+func (e *Engine) find(id string) *Iceberg {
+	for _, ib := range e.icebergs {
+		if ib.ID == id {
+			return ib
+		}
+	}
+	return nil
+}
+
+// remove id 빙산 주문을 목록에서 뺀다
+func (e *Engine) remove(id string) {
+	for i, ib := range e.icebergs {
+		if ib.ID == id {
+			e.icebergs = append(e.icebergs[:i], e.icebergs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *Engine) save() error {
+	return e.base.Save(stateName, e.icebergs)
+}
+
+func (cfg Config) validate() error {
+	if cfg.Market == "" {
+		return newError(ErrInvalidIceberg, "market is required")
+	}
+	if cfg.Side != "bid" && cfg.Side != "ask" {
+		return newError(ErrInvalidIceberg, "side must be bid or ask")
+	}
+	if cfg.Price.Sign() <= 0 {
+		return newError(ErrInvalidIceberg, "price must be positive")
+	}
+	normalized, err := upbit.NormalizePrice(cfg.Market, cfg.Price, upbit.RoundNearest)
+	if err != nil {
+		return newError(ErrInvalidIceberg, "%v", err)
+	}
+	if !normalized.Equal(cfg.Price) {
+		return newError(ErrInvalidIceberg, "price %s is not aligned to the tick size of %s (nearest: %s)", cfg.Price, cfg.Market, normalized)
+	}
+	if cfg.Volume.Sign() <= 0 || cfg.VisibleVolume.Sign() <= 0 {
+		return newError(ErrInvalidIceberg, "volume and visible_volume must be positive")
+	}
+	if cfg.VisibleVolume.GreaterThan(cfg.Volume) {
+		return newError(ErrInvalidIceberg, "visible_volume must not be greater than volume")
+	}
+	if cfg.Volume.GreaterThan(cfg.VisibleVolume.Mul(upbit.DecimalFromInt(MaxOrders))) {
+		return newError(ErrInvalidIceberg, "visible_volume is too small: volume would be split into more than %d orders", MaxOrders)
+	}
+	return nil
+}
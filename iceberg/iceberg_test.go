@@ -0,0 +1,130 @@
+package iceberg_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"upbit-mcp-server/iceberg"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+func TestStartUnderMinTotal(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "10000000"})
+	e, err := iceberg.NewEngine(ex.Client(), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 보이는 주문 4,500원은 최소 주문 금액 5,000원보다 작다
+	_, err = e.Start(context.Background(), iceberg.Config{Market: "KRW-BTC", Side: "bid", Price: "90000000", Volume: "0.001", VisibleVolume: "0.00005"})
+	var ibErr *iceberg.Error
+	if !errors.As(err, &ibErr) || ibErr.Name != iceberg.ErrInvalidIceberg {
+		t.Fatalf("Start = %v, want %s", err, iceberg.ErrInvalidIceberg)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 0 {
+		t.Errorf("orders placed = %d, want 0", n)
+	}
+}
+
+func TestCancelAfterVisibleFill(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "10000000"})
+	client := ex.Client()
+	e, err := iceberg.NewEngine(ex.FillBefore(client, "KRW-BTC", "90000000"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	ib, err := e.Start(ctx, iceberg.Config{Market: "KRW-BTC", Side: "bid", Price: "90000000", Volume: "0.003", VisibleVolume: "0.001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 취소하기 전에 보이는 주문이 체결되면 오류 대신 체결을 반영하고 나머지만 거두어들인다
+	got, err := e.Cancel(ctx, ib.ID)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if got.State != iceberg.StateCanceled || !got.ExecutedVolume.Equal("0.001") || !got.HiddenVolume.Equal("0.002") {
+		t.Errorf("iceberg = %s, executed %s, hidden %s", got.State, got.ExecutedVolume, got.HiddenVolume)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+}
+
+// removeDirOnPlace 주문을 보내기 전에 상태 디렉터리를 지워 이후의 저장이 실패하게 하는 Trader
+type removeDirOnPlace struct {
+	upbit.Trader
+	dir string
+}
+
+func (r *removeDirOnPlace) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	if err := os.RemoveAll(r.dir); err != nil {
+		return upbit.Order{}, err
+	}
+	return r.Trader.PlaceOrder(ctx, params)
+}
+
+func TestStartCancelsOrderWhenSaveFails(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "10000000"})
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := iceberg.NewEngine(&removeDirOnPlace{Trader: ex.Client(), dir: dir}, st, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := e.Start(ctx, iceberg.Config{Market: "KRW-BTC", Side: "bid", Price: "90000000", Volume: "0.003", VisibleVolume: "0.001"}); err == nil {
+		t.Fatal("Start succeeded without saving the iceberg")
+	}
+
+	// 시작에 실패한 빙산 주문은 첫 주문을 취소하고 다시 주문하지 않는다
+	list := e.List()
+	if len(list) != 1 || list[0].State != iceberg.StateFailed || list[0].Note == "" {
+		t.Fatalf("icebergs = %+v", list)
+	}
+	order, ok := ex.Order(list[0].Children[0].OrderUuid)
+	if !ok || order.State != upbit.OrderStateCancel {
+		t.Fatalf("first order = %+v", order)
+	}
+	if err := e.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+}
+
+func TestStartingIcebergFailsOnRestart(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "10000000"})
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 첫 주문을 내는 도중에 서버가 종료된 상태
+	saved := []iceberg.Iceberg{{ID: "a", Config: iceberg.Config{Market: "KRW-BTC", Side: "bid", Price: "90000000", Volume: "0.003", VisibleVolume: "0.001"}, State: iceberg.StateStarting}}
+	if err := st.Save("iceberg", saved); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := iceberg.NewEngine(ex.Client(), st, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != iceberg.StateFailed || !strings.Contains(got.Note, iceberg.IdentifierPrefix+"a-0") {
+		t.Errorf("iceberg = %s (%s)", got.State, got.Note)
+	}
+}
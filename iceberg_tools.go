@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"time"
+	"upbit-mcp-server/iceberg"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// icebergEngineKey는 context 내에서 빙산 주문 엔진을 식별하기 위한 키
+type icebergEngineKey struct{}
+
+type StartIcebergRequest struct {
+	Market        string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Side          string        `json:"side" jsonschema:"Allowed: 'bid' (buy), 'ask' (sell)."`
+	Price         upbit.Decimal `json:"price" jsonschema:"Limit price of every child order."`
+	Volume        upbit.Decimal `json:"volume" jsonschema:"Total volume to buy or sell."`
+	VisibleVolume upbit.Decimal `json:"visible_volume" jsonschema:"Volume shown in the order book at a time. When the visible order fills, a new one is placed from the hidden remainder. The total is split into at most 200 orders and each must meet the minimum order total."`
+	PriceRounding string        `json:"price_rounding,omitempty" jsonschema:"Optional. Align the price to the Upbit tick size (price unit) of the market. Allowed: 'down', 'up', 'nearest'. If omitted, prices not aligned to the tick size are rejected."`
+}
+
+type IcebergIDRequest struct {
+	ID string `json:"id" jsonschema:"Iceberg order ID."`
+}
+
+// IcebergSummary 빙산 주문 목록의 한 항목
+type IcebergSummary struct {
+	ID string `json:"id"`
+	iceberg.Config
+	State          string        `json:"state"`
+	LiveOrderUuid  string        `json:"live_order_uuid,omitempty" jsonschema:"UUID of the child order currently in the order book."`
+	Orders         int           `json:"orders" jsonschema:"Number of child orders placed."`
+	ExecutedVolume upbit.Decimal `json:"executed_volume"`
+	AvgPrice       upbit.Decimal `json:"avg_price"`
+	HiddenVolume   upbit.Decimal `json:"hidden_volume"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type GetIcebergsResult struct {
+	Icebergs []IcebergSummary `json:"icebergs"`
+}
+
+func StartIceberg(ctx context.Context, req *mcp.CallToolRequest, params *StartIcebergRequest) (
+	*mcp.CallToolResult,
+	*iceberg.Iceberg,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(icebergEngineKey{}).(*iceberg.Engine)
+	if !ok {
+		return nil, nil, missingDependency("iceberg engine")
+	}
+
+	price, err := normalizeLimitPrice(params.Market, params.Price, params.PriceRounding)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	// 다시 채우는 주문은 확인할 사용자가 없을 때 나가므로 전체 주문을 시작할 때 확인한다
+	if err := confirmOrder(ctx, req, upbit.RequestParams{
+		Market:  params.Market,
+		Side:    params.Side,
+		OrdType: upbit.OrdTypeLimit,
+		Price:   price,
+		Volume:  params.Volume,
+	}); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	ib, err := engine.Start(ctx, iceberg.Config{
+		Market:        params.Market,
+		Side:          params.Side,
+		Price:         price,
+		Volume:        params.Volume,
+		VisibleVolume: params.VisibleVolume,
+	})
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ib, nil
+}
+
+func GetIcebergs(ctx context.Context, req *mcp.CallToolRequest, params any) (
+	*mcp.CallToolResult,
+	*GetIcebergsResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(icebergEngineKey{}).(*iceberg.Engine)
+	if !ok {
+		return nil, nil, missingDependency("iceberg engine")
+	}
+
+	result := &GetIcebergsResult{Icebergs: []IcebergSummary{}}
+	for _, ib := range engine.List() {
+		s := IcebergSummary{
+			ID:             ib.ID,
+			Config:         ib.Config,
+			State:          ib.State,
+			Orders:         len(ib.Children),
+			ExecutedVolume: ib.ExecutedVolume,
+			AvgPrice:       ib.AvgPrice,
+			HiddenVolume:   ib.HiddenVolume,
+			CreatedAt:      ib.CreatedAt,
+		}
+		if c := ib.Live(); c != nil {
+			s.LiveOrderUuid = c.OrderUuid
+		}
+		result.Icebergs = append(result.Icebergs, s)
+	}
+
+	return &res, result, nil
+}
+
+func GetIceberg(ctx context.Context, req *mcp.CallToolRequest, params *IcebergIDRequest) (
+	*mcp.CallToolResult,
+	*iceberg.Iceberg,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(icebergEngineKey{}).(*iceberg.Engine)
+	if !ok {
+		return nil, nil, missingDependency("iceberg engine")
+	}
+
+	ib, err := engine.Get(params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ib, nil
+}
+
+func CancelIceberg(ctx context.Context, req *mcp.CallToolRequest, params *IcebergIDRequest) (
+	*mcp.CallToolResult,
+	*iceberg.Iceberg,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	engine, ok := ctx.Value(icebergEngineKey{}).(*iceberg.Engine)
+	if !ok {
+		return nil, nil, missingDependency("iceberg engine")
+	}
+
+	ib, err := engine.Cancel(ctx, params.ID)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &ib, nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"upbit-mcp-server/indicators"
 	"upbit-mcp-server/upbit"
 
@@ -69,15 +68,15 @@ type GetOBVResult struct {
 func GetMovingAverage(ctx context.Context, req *mcp.CallToolRequest, params *GetMovingAverageRequest) (*mcp.CallToolResult, *GetMovingAverageResult, error) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetDayCandles(upbit.RequestParams{
+	candles, err := client.GetDayCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	sma := indicators.CalculateSMA(candles, params.Period)
@@ -89,15 +88,15 @@ func GetMovingAverage(ctx context.Context, req *mcp.CallToolRequest, params *Get
 func GetMACD(ctx context.Context, req *mcp.CallToolRequest, params *GetMACDRequest) (*mcp.CallToolResult, *GetMACDResult, error) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetDayCandles(upbit.RequestParams{
+	candles, err := client.GetDayCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	macd, signal, histogram := indicators.CalculateMACD(candles, params.ShortPeriod, params.LongPeriod, params.SignalPeriod)
@@ -108,15 +107,15 @@ func GetMACD(ctx context.Context, req *mcp.CallToolRequest, params *GetMACDReque
 func GetBollingerBands(ctx context.Context, req *mcp.CallToolRequest, params *GetBollingerBandsRequest) (*mcp.CallToolResult, *GetBollingerBandsResult, error) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetDayCandles(upbit.RequestParams{
+	candles, err := client.GetDayCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	sma, upper, lower := indicators.CalculateBollingerBands(candles, params.Period, params.StdDev)
@@ -127,15 +126,15 @@ func GetBollingerBands(ctx context.Context, req *mcp.CallToolRequest, params *Ge
 func GetRSI(ctx context.Context, req *mcp.CallToolRequest, params *GetRSIRequest) (*mcp.CallToolResult, *GetRSIResult, error) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetDayCandles(upbit.RequestParams{
+	candles, err := client.GetDayCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	rsi := indicators.CalculateRSI(candles, params.Period)
@@ -146,15 +145,15 @@ func GetRSI(ctx context.Context, req *mcp.CallToolRequest, params *GetRSIRequest
 func GetOBV(ctx context.Context, req *mcp.CallToolRequest, params *GetOBVRequest) (*mcp.CallToolResult, *GetOBVResult, error) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetDayCandles(upbit.RequestParams{
+	candles, err := client.GetDayCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	obv := indicators.CalculateOBV(candles)
@@ -5,16 +5,31 @@ import (
 	"upbit-mcp-server/upbit"
 )
 
+// closingPrices extracts the closing prices of the candles.
+// Indicators are statistical approximations, so they work on float64 values
+// instead of the exact upbit.Decimal prices.
+func closingPrices(candles []*upbit.Candle) []float64 {
+	prices := make([]float64, len(candles))
+	for i, c := range candles {
+		prices[i] = c.TradePrice.Float64()
+	}
+	return prices
+}
+
 // CalculateSMA calculates the Simple Moving Average (SMA) for a given period.
 func CalculateSMA(candles []*upbit.Candle, period int) []float64 {
-	if len(candles) < period {
+	return sma(closingPrices(candles), period)
+}
+
+func sma(values []float64, period int) []float64 {
+	if len(values) < period {
 		return []float64{}
 	}
 	var smaValues []float64
-	for i := period - 1; i < len(candles); i++ {
+	for i := period - 1; i < len(values); i++ {
 		sum := 0.0
 		for j := i; j > i-period; j-- {
-			sum += candles[j].TradePrice
+			sum += values[j]
 		}
 		smaValues = append(smaValues, sum/float64(period))
 	}
@@ -23,7 +38,11 @@ func CalculateSMA(candles []*upbit.Candle, period int) []float64 {
 
 // CalculateEMA calculates the Exponential Moving Average (EMA) for a given period.
 func CalculateEMA(candles []*upbit.Candle, period int) []float64 {
-	if len(candles) < period {
+	return ema(closingPrices(candles), period)
+}
+
+func ema(values []float64, period int) []float64 {
+	if len(values) < period {
 		return []float64{}
 	}
 	var emaValues []float64
@@ -32,13 +51,13 @@ func CalculateEMA(candles []*upbit.Candle, period int) []float64 {
 	// Calculate initial SMA for the first EMA value
 	sum := 0.0
 	for i := 0; i < period; i++ {
-		sum += candles[i].TradePrice
+		sum += values[i]
 	}
 	emaValues = append(emaValues, sum/float64(period))
 
 	// Calculate subsequent EMA values
-	for i := period; i < len(candles); i++ {
-		ema := (candles[i].TradePrice-emaValues[len(emaValues)-1])*multiplier + emaValues[len(emaValues)-1]
+	for i := period; i < len(values); i++ {
+		ema := (values[i]-emaValues[len(emaValues)-1])*multiplier + emaValues[len(emaValues)-1]
 		emaValues = append(emaValues, ema)
 	}
 
@@ -51,8 +70,9 @@ func CalculateMACD(candles []*upbit.Candle, shortPeriod, longPeriod, signalPerio
 		return []float64{}, []float64{}, []float64{}
 	}
 
-	emaShort := CalculateEMA(candles, shortPeriod)
-	emaLong := CalculateEMA(candles, longPeriod)
+	prices := closingPrices(candles)
+	emaShort := ema(prices, shortPeriod)
+	emaLong := ema(prices, longPeriod)
 
 	// Align EMA slices
 	emaShort = emaShort[longPeriod-shortPeriod:]
@@ -62,13 +82,7 @@ func CalculateMACD(candles []*upbit.Candle, shortPeriod, longPeriod, signalPerio
 		macdLine = append(macdLine, emaShort[i]-emaLong[i])
 	}
 
-	// Create a temporary slice of "candles" for the MACD line to calculate the signal line
-	var macdCandles []*upbit.Candle
-	for _, v := range macdLine {
-		macdCandles = append(macdCandles, &upbit.Candle{TradePrice: v})
-	}
-
-	signalLine := CalculateEMA(macdCandles, signalPeriod)
+	signalLine := ema(macdLine, signalPeriod)
 
 	// Align signal line with macd line
 	macdLine = macdLine[len(macdLine)-len(signalLine):]
@@ -87,25 +101,26 @@ func CalculateBollingerBands(candles []*upbit.Candle, period int, stdDev float64
 		return nil, nil, nil
 	}
 
-	sma := CalculateSMA(candles, period)
+	prices := closingPrices(candles)
+	middle := sma(prices, period)
 	var upperBand, lowerBand []float64
 
-	for i := period - 1; i < len(candles); i++ {
+	for i := period - 1; i < len(prices); i++ {
 		sum := 0.0
 		for j := i; j > i-period; j-- {
-			sum += candles[j].TradePrice
+			sum += prices[j]
 		}
 		mean := sum / float64(period)
 		sd := 0.0
 		for j := i; j > i-period; j-- {
-			sd += math.Pow(candles[j].TradePrice-mean, 2)
+			sd += math.Pow(prices[j]-mean, 2)
 		}
 		sd = math.Sqrt(sd / float64(period))
 		upperBand = append(upperBand, mean+sd*stdDev)
 		lowerBand = append(lowerBand, mean-sd*stdDev)
 	}
 
-	return sma, upperBand, lowerBand
+	return middle, upperBand, lowerBand
 }
 
 // CalculateRSI calculates the Relative Strength Index (RSI).
@@ -114,11 +129,12 @@ func CalculateRSI(candles []*upbit.Candle, period int) []float64 {
 		return []float64{}
 	}
 
+	prices := closingPrices(candles)
 	var rsiValues []float64
 	var gains, losses []float64
 
-	for i := 1; i < len(candles); i++ {
-		change := candles[i].TradePrice - candles[i-1].TradePrice
+	for i := 1; i < len(prices); i++ {
+		change := prices[i] - prices[i-1]
 		if change > 0 {
 			gains = append(gains, change)
 			losses = append(losses, 0)
@@ -163,13 +179,14 @@ func CalculateOBV(candles []*upbit.Candle) []float64 {
 	}
 
 	obvValues := make([]float64, len(candles))
-	obvValues[0] = candles[0].CandleAccTradeVolume
+	obvValues[0] = candles[0].CandleAccTradeVolume.Float64()
 
 	for i := 1; i < len(candles); i++ {
-		if candles[i].TradePrice > candles[i-1].TradePrice {
-			obvValues[i] = obvValues[i-1] + candles[i].CandleAccTradeVolume
-		} else if candles[i].TradePrice < candles[i-1].TradePrice {
-			obvValues[i] = obvValues[i-1] - candles[i].CandleAccTradeVolume
+		volume := candles[i].CandleAccTradeVolume.Float64()
+		if candles[i].TradePrice.GreaterThan(candles[i-1].TradePrice) {
+			obvValues[i] = obvValues[i-1] + volume
+		} else if candles[i].TradePrice.LessThan(candles[i-1].TradePrice) {
+			obvValues[i] = obvValues[i-1] - volume
 		} else {
 			obvValues[i] = obvValues[i-1]
 		}
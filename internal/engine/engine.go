@@ -0,0 +1,73 @@
+// Package engine 조건부 주문, 적립식 매수, 그리드, 분할 실행, 빙산 주문 엔진이 함께 쓰는 오류와 실행 주기, 상태 저장
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"upbit-mcp-server/store"
+)
+
+// Error 엔진이 요청을 거절한 이유. Kind는 엔진 종류로 메시지에만 쓰이고 에이전트에게는 Name과 Message가 전달된다
+type Error struct {
+	Kind    string
+	Name    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Kind, e.Name, e.Message)
+}
+
+// NewError kind 엔진의 요청 거절 오류 생성
+func NewError(kind, name, format string, args ...any) error {
+	return &Error{Kind: kind, Name: name, Message: fmt.Sprintf(format, args...)}
+}
+
+// Base 엔진의 확인 주기와 상태 저장소. 엔진은 Base를 두고 한 번의 확인(check)만 구현한다
+type Base struct {
+	store    *store.Store
+	interval time.Duration
+}
+
+// NewBase interval이 0 이하이면 defaultInterval을 사용한다. st가 nil이면 상태를 메모리에만 보관한다.
+func NewBase(st *store.Store, interval, defaultInterval time.Duration) Base {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return Base{store: st, interval: interval}
+}
+
+// Load name에 저장된 상태를 v로 읽는다. 저장소가 없거나 저장된 적이 없으면 v를 그대로 둔다
+func (b Base) Load(name string, v any) error {
+	if b.store == nil {
+		return nil
+	}
+	_, err := b.store.Load(name, v)
+	return err
+}
+
+// Save v를 name에 저장한다. 저장소가 없으면 아무것도 하지 않는다
+func (b Base) Save(name string, v any) error {
+	if b.store == nil {
+		return nil
+	}
+	return b.store.Save(name, v)
+}
+
+// Run ctx가 끝날 때까지 주기적으로 check를 호출하고 오류는 label과 함께 기록한다
+func (b Base) Run(ctx context.Context, label string, check func(context.Context) error) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := check(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("%s: %v", label, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Package ledger 모의 거래(paper)와 테스트 거래소(upbittest)가 함께 쓰는 가상 원장과 주문 매칭.
+//
+// 화폐별 잔고와 주문을 보관하고, 상대편 호가를 받아 업비트와 같은 규칙으로 주문을 체결한다.
+// 호가를 어디서 가져오는지(실제 호가창, 설정한 호가, 현재가)와 수수료율은 호출하는 쪽이 정한다.
+// 잠금을 잡지 않으므로 호출하는 쪽에서 하나의 잠금 안에서 사용해야 한다.
+package ledger
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// VolumePlaces 시장가 매수 체결 수량 등 나눗셈 결과의 소수점 자리수
+const VolumePlaces = 8
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+// Ledger 가상 계좌와 주문 원장
+type Ledger struct {
+	wallets  map[string]*Wallet
+	orders   map[string]*Order
+	sequence []*Order
+}
+
+// Wallet 화폐 하나의 잔고
+type Wallet struct {
+	Balance      upbit.Decimal
+	Locked       upbit.Decimal
+	AvgBuyPrice  upbit.Decimal
+	UnitCurrency string
+}
+
+// Order 원장에 기록된 주문
+type Order struct {
+	upbit.Order
+	// Funds 금액으로 주문한 매수(시장가, 최유리)에서 아직 사용하지 않은 주문 금액
+	Funds upbit.Decimal
+}
+
+// Level 체결에 사용할 상대편 호가 한 단계
+type Level struct {
+	Price upbit.Decimal
+	Size  upbit.Decimal
+	// Unlimited 수량 제한 없이 체결되는 호가. 호가창 없이 현재가로 체결할 때 사용한다
+	Unlimited bool
+}
+
+// New 빈 원장 생성
+func New() *Ledger {
+	return &Ledger{
+		wallets: map[string]*Wallet{},
+		orders:  map[string]*Order{},
+	}
+}
+
+// Wallet 화폐의 잔고. 처음 조회하면 0으로 만든다
+func (l *Ledger) Wallet(currency string) *Wallet {
+	w, ok := l.wallets[currency]
+	if !ok {
+		w = &Wallet{Balance: "0", Locked: "0", AvgBuyPrice: "0", UnitCurrency: "KRW"}
+		l.wallets[currency] = w
+	}
+	return w
+}
+
+// Account 업비트 계좌 조회 응답 형식
+func (w *Wallet) Account(currency string) upbit.Account {
+	return upbit.Account{
+		Currency:     currency,
+		Balance:      w.Balance,
+		Locked:       w.Locked,
+		AvgBuyPrice:  w.AvgBuyPrice,
+		UnitCurrency: w.UnitCurrency,
+	}
+}
+
+// Accounts 잔고가 있는 계좌를 화폐 이름 순으로 반환
+func (l *Ledger) Accounts() []upbit.Account {
+	currencies := make([]string, 0, len(l.wallets))
+	for currency, w := range l.wallets {
+		if !w.Balance.IsZero() || !w.Locked.IsZero() {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+
+	res := make([]upbit.Account, 0, len(currencies))
+	for _, currency := range currencies {
+		res = append(res, l.wallets[currency].Account(currency))
+	}
+	return res
+}
+
+// NewOrder 검증을 마친 주문 파라미터로 대기 상태의 주문을 만든다. 원장에는 Place로 등록한다
+func NewOrder(params upbit.RequestParams, createdAt time.Time) *Order {
+	o := &Order{Order: upbit.Order{
+		Uuid:            uuid.NewString(),
+		Identifier:      params.Identifier,
+		Side:            params.Side,
+		OrdType:         params.OrdType,
+		TimeInForce:     params.TimeInForce,
+		SmpType:         params.SmpType,
+		State:           upbit.OrderStateWait,
+		Market:          params.Market,
+		CreatedAt:       createdAt.In(kst).Format("2006-01-02T15:04:05-07:00"),
+		ExecutedVolume:  "0",
+		RemainingVolume: "0",
+		PaidFee:         "0",
+		ReservedFee:     "0",
+		RemainingFee:    "0",
+		Locked:          "0",
+	}}
+
+	amount := upbit.IsAmountOrder(params.OrdType, params.Side)
+	if params.OrdType == upbit.OrdTypeLimit || amount {
+		o.Price = params.Price
+	}
+	if amount {
+		o.Funds = params.Price
+	} else {
+		o.Volume = params.Volume
+		o.RemainingVolume = params.Volume
+	}
+	return o
+}
+
+// Place 주문에 필요한 잔고를 묶고 원장에 등록한다. 매수는 bidFee로 계산한 수수료까지 묶는다
+func (l *Ledger) Place(o *Order, bidFee upbit.Decimal) error {
+	base, quote := SplitMarket(o.Market)
+	currency := base
+	if o.Side == "bid" {
+		total := o.Price.Mul(o.Volume)
+		if upbit.IsAmountOrder(o.OrdType, o.Side) {
+			total = o.Price
+		}
+		o.ReservedFee = total.Mul(bidFee)
+		o.RemainingFee = o.ReservedFee
+		o.Locked = total.Add(o.ReservedFee)
+		currency = quote
+	} else {
+		o.Locked = o.Volume
+	}
+
+	w := l.Wallet(currency)
+	if w.Balance.LessThan(o.Locked) {
+		name := upbit.ErrInsufficientFundsAsk
+		if o.Side == "bid" {
+			name = upbit.ErrInsufficientFundsBid
+		}
+		return &upbit.APIError{StatusCode: 400, Name: name, Message: fmt.Sprintf("주문가능한 금액(%s)이 부족합니다.", currency)}
+	}
+	w.Balance = w.Balance.Sub(o.Locked)
+	w.Locked = w.Locked.Add(o.Locked)
+
+	l.orders[o.Uuid] = o
+	l.sequence = append(l.sequence, o)
+	return nil
+}
+
+// Order uuid로 주문 조회
+func (l *Ledger) Order(id string) (*Order, bool) {
+	o, ok := l.orders[id]
+	return o, ok
+}
+
+// ByIdentifier identifier로 주문 조회
+func (l *Ledger) ByIdentifier(identifier string) *Order {
+	for _, o := range l.sequence {
+		if o.Identifier == identifier {
+			return o
+		}
+	}
+	return nil
+}
+
+// Waiting 체결 대기 중인 주문을 접수 순서대로 반환. market이 비어있으면 모든 마켓
+func (l *Ledger) Waiting(market string) []*Order {
+	var res []*Order
+	for _, o := range l.sequence {
+		if o.State == upbit.OrderStateWait && (market == "" || o.Market == market) {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// Cancel 대기 중인 주문을 취소하고 묶여있던 잔고를 돌려준다. 대기 중이 아니면 false
+func (l *Ledger) Cancel(o *Order) bool {
+	if o.State != upbit.OrderStateWait {
+		return false
+	}
+	o.State = upbit.OrderStateCancel
+	l.release(o)
+	return true
+}
+
+// Filter 조건에 맞는 주문을 접수 순서대로 모은다 (기본값: 최신순)
+func (l *Ledger) Filter(market, orderBy string, match func(o *Order) bool) []upbit.Order {
+	res := []upbit.Order{}
+	for _, o := range l.sequence {
+		if (market == "" || o.Market == market) && match(o) {
+			res = append(res, o.Snapshot())
+		}
+	}
+	if orderBy != "asc" {
+		slices.Reverse(res)
+	}
+	return res
+}
+
+// BookLevels side 주문이 체결될 상대편 호가를 유리한 가격 순으로 나열
+func BookLevels(book upbit.OrderBook, side string) []Level {
+	levels := make([]Level, 0, len(book.OrderbookUnits))
+	for _, u := range book.OrderbookUnits {
+		if side == "bid" {
+			levels = append(levels, Level{Price: u.AskPrice, Size: u.AskSize})
+		} else {
+			levels = append(levels, Level{Price: u.BidPrice, Size: u.BidSize})
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		if side == "bid" {
+			return levels[i].Price.LessThan(levels[j].Price)
+		}
+		return levels[i].Price.GreaterThan(levels[j].Price)
+	})
+	return levels
+}
+
+// Match 호가를 소모하며 주문을 체결한다. 체결한 수량만큼 levels의 Size를 줄이므로
+// 같은 levels로 여러 주문을 매칭하면 앞 주문이 가져간 호가는 뒤 주문이 쓸 수 없다.
+// maker이면 대기하던 주문이 체결되는 것으로 보고 호가 대신 지정가로 체결한다.
+// 시장가, 최유리, ioc 주문은 호가가 부족해 남은 수량을 취소하고, fok 주문은 전량 체결할 수 없으면 체결하지 않고 취소한다.
+func (l *Ledger) Match(o *Order, levels []Level, feeRate upbit.Decimal, maker bool) {
+	amount := upbit.IsAmountOrder(o.OrdType, o.Side)
+	if o.TimeInForce == upbit.TimeInForceFOK && !o.fillable(levels) {
+		l.Cancel(o)
+		return
+	}
+
+	// filled 남은 수량(금액으로 주문한 매수는 남은 금액으로 살 수 있는 수량)이 0이 되었는지 여부
+	filled := !amount && o.RemainingVolume.IsZero()
+	for i := range levels {
+		lv := &levels[i]
+		if filled || !o.crosses(lv.Price) {
+			break
+		}
+		if !lv.Unlimited && lv.Size.Sign() <= 0 {
+			continue
+		}
+
+		price := lv.Price
+		if maker {
+			price = o.Price
+		}
+
+		want := o.RemainingVolume
+		if amount {
+			want = o.Funds.Div(price, VolumePlaces)
+		}
+		if want.Sign() <= 0 {
+			filled = true
+			break
+		}
+
+		volume := want
+		if !lv.Unlimited {
+			volume = upbit.MinDecimal(want, lv.Size)
+			lv.Size = lv.Size.Sub(volume)
+		}
+		l.fill(o, price, volume, feeRate)
+		filled = o.RemainingVolume.IsZero() && (!amount || o.Funds.Div(price, VolumePlaces).IsZero())
+	}
+
+	switch {
+	case filled:
+		o.State = upbit.OrderStateDone
+		l.release(o)
+	case o.OrdType != upbit.OrdTypeLimit || o.TimeInForce != "":
+		l.Cancel(o)
+	}
+}
+
+// fillable 주문 전체를 levels로 바로 체결할 수 있는지 여부
+func (o *Order) fillable(levels []Level) bool {
+	amount := upbit.IsAmountOrder(o.OrdType, o.Side)
+	want := o.RemainingVolume
+	if amount {
+		want = o.Funds
+	}
+	for _, lv := range levels {
+		if !o.crosses(lv.Price) {
+			return false
+		}
+		if lv.Unlimited {
+			return true
+		}
+		if amount {
+			want = want.Sub(lv.Price.Mul(lv.Size))
+		} else {
+			want = want.Sub(lv.Size)
+		}
+		if want.Sign() <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// crosses 상대 호가 price에 체결할 수 있는지 여부. 지정가 주문만 가격 제한이 있다
+func (o *Order) crosses(price upbit.Decimal) bool {
+	if o.OrdType != upbit.OrdTypeLimit {
+		return true
+	}
+	if o.Side == "bid" {
+		return !price.GreaterThan(o.Price)
+	}
+	return !price.LessThan(o.Price)
+}
+
+// fill 체결 하나를 주문과 계좌에 반영
+func (l *Ledger) fill(o *Order, price, volume, feeRate upbit.Decimal) {
+	base, quote := SplitMarket(o.Market)
+	funds := price.Mul(volume)
+	fee := funds.Mul(feeRate)
+
+	if o.Side == "bid" {
+		qw := l.Wallet(quote)
+		qw.Locked = qw.Locked.Sub(funds.Add(fee))
+		o.Locked = o.Locked.Sub(funds.Add(fee))
+
+		bw := l.Wallet(base)
+		holding := bw.Balance.Add(bw.Locked)
+		bw.AvgBuyPrice = bw.AvgBuyPrice.Mul(holding).Add(funds).Div(holding.Add(volume), VolumePlaces)
+		bw.UnitCurrency = quote
+		bw.Balance = bw.Balance.Add(volume)
+	} else {
+		bw := l.Wallet(base)
+		bw.Locked = bw.Locked.Sub(volume)
+		o.Locked = o.Locked.Sub(volume)
+
+		qw := l.Wallet(quote)
+		qw.Balance = qw.Balance.Add(funds.Sub(fee))
+	}
+
+	if upbit.IsAmountOrder(o.OrdType, o.Side) {
+		o.Funds = o.Funds.Sub(funds)
+	} else {
+		o.RemainingVolume = o.RemainingVolume.Sub(volume)
+	}
+	o.ExecutedVolume = o.ExecutedVolume.Add(volume)
+	o.PaidFee = o.PaidFee.Add(fee)
+	o.RemainingFee = upbit.MaxDecimal("0", o.RemainingFee.Sub(fee))
+	o.Trades = append(o.Trades, upbit.Trade{
+		Market: o.Market,
+		Uuid:   uuid.NewString(),
+		Price:  price,
+		Volume: volume,
+		Funds:  funds,
+		Side:   o.Side,
+	})
+	o.TradesCount = len(o.Trades)
+}
+
+// release 주문에 묶여있던 잔고를 돌려준다
+func (l *Ledger) release(o *Order) {
+	base, quote := SplitMarket(o.Market)
+	currency := base
+	if o.Side == "bid" {
+		currency = quote
+	}
+	w := l.Wallet(currency)
+	w.Locked = w.Locked.Sub(o.Locked)
+	w.Balance = w.Balance.Add(o.Locked)
+	o.Locked = "0"
+	o.RemainingFee = "0"
+}
+
+// Snapshot 체결 내역을 제외한 주문 정보 (업비트 목록 조회 응답과 같은 형태)
+func (o *Order) Snapshot() upbit.Order {
+	res := o.Order
+	res.Trades = nil
+	return res
+}
+
+// Detail 체결 내역을 포함한 주문 정보 (개별 주문 조회 응답과 같은 형태)
+func (o *Order) Detail() upbit.Order {
+	res := o.Order
+	res.Trades = slices.Clone(o.Trades)
+	return res
+}
+
+// SplitMarket "KRW-BTC" 형식의 마켓 코드를 (BTC, KRW)로 분리
+func SplitMarket(market string) (base, quote string) {
+	quote, base, _ = strings.Cut(market, "-")
+	return base, quote
+}
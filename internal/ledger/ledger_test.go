@@ -0,0 +1,168 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+	"upbit-mcp-server/upbit"
+)
+
+func TestMatch(t *testing.T) {
+	// 매수 주문이 체결될 매도 호가
+	asks := func() []Level {
+		return []Level{{Price: "100", Size: "1"}, {Price: "101", Size: "2"}}
+	}
+
+	tests := []struct {
+		name       string
+		params     upbit.RequestParams
+		levels     []Level
+		maker      bool
+		wantState  string
+		wantVolume upbit.Decimal
+		wantKRW    upbit.Decimal
+		wantBTC    upbit.Decimal
+	}{
+		{
+			name:      "지정가 일부 체결 후 대기",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "1.5"},
+			levels:    asks(),
+			wantState: upbit.OrderStateWait, wantVolume: "1",
+			// 주문 금액과 수수료 150.15가 묶이고, 체결되지 않은 만큼은 대기 중이므로 묶인 채 남는다
+			wantKRW: "9849.85", wantBTC: "1",
+		},
+		{
+			name:      "지정가 여러 호가 체결",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "101", Volume: "2"},
+			levels:    asks(),
+			wantState: upbit.OrderStateDone, wantVolume: "2",
+			// 100 + 101 체결, 수수료 0.1% 0.201, 남은 예약분은 돌려받는다
+			wantKRW: "9798.799", wantBTC: "2",
+		},
+		{
+			name:      "금액 시장가 매수",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypePrice, Price: "150"},
+			levels:    asks(),
+			wantState: upbit.OrderStateDone, wantVolume: "1.4950495",
+			// 101원 호가에서 0.4950495개를 사고 남은 0.0000005원은 돌려받는다
+			wantKRW: "9849.8500005005", wantBTC: "1.4950495",
+		},
+		{
+			name:      "호가 부족한 ioc는 남은 수량 취소",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "3", TimeInForce: upbit.TimeInForceIOC},
+			levels:    asks(),
+			wantState: upbit.OrderStateCancel, wantVolume: "1",
+			wantKRW: "9899.9", wantBTC: "1",
+		},
+		{
+			name:      "전량 체결할 수 없는 fok는 체결 없이 취소",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "3", TimeInForce: upbit.TimeInForceFOK},
+			levels:    asks(),
+			wantState: upbit.OrderStateCancel, wantVolume: "0",
+			wantKRW: "10000", wantBTC: "0",
+		},
+		{
+			name:      "대기 주문은 지정가로 체결",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "102", Volume: "1"},
+			levels:    asks(),
+			maker:     true,
+			wantState: upbit.OrderStateDone, wantVolume: "1",
+			wantKRW: "9897.898", wantBTC: "1",
+		},
+		{
+			name:      "무제한 호가",
+			params:    upbit.RequestParams{Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "50"},
+			levels:    []Level{{Price: "100", Unlimited: true}},
+			wantState: upbit.OrderStateDone, wantVolume: "50",
+			wantKRW: "4995", wantBTC: "50",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New()
+			l.Wallet("KRW").Balance = "10000"
+
+			tt.params.Market = "KRW-BTC"
+			o := NewOrder(tt.params, time.Now())
+			if err := l.Place(o, "0.001"); err != nil {
+				t.Fatal(err)
+			}
+			l.Match(o, tt.levels, "0.001", tt.maker)
+
+			if o.State != tt.wantState || !o.ExecutedVolume.Equal(tt.wantVolume) {
+				t.Errorf("state = %s, executed = %s, want %s, %s", o.State, o.ExecutedVolume, tt.wantState, tt.wantVolume)
+			}
+			if krw := l.Wallet("KRW"); !krw.Balance.Equal(tt.wantKRW) {
+				t.Errorf("KRW balance = %s, want %s", krw.Balance, tt.wantKRW)
+			}
+			if btc := l.Wallet("BTC"); !btc.Balance.Equal(tt.wantBTC) {
+				t.Errorf("BTC balance = %s, want %s", btc.Balance, tt.wantBTC)
+			}
+			// 대기 중이 아니면 묶인 잔고가 남지 않는다
+			if o.State != upbit.OrderStateWait && !l.Wallet("KRW").Locked.IsZero() {
+				t.Errorf("KRW locked = %s after %s", l.Wallet("KRW").Locked, o.State)
+			}
+		})
+	}
+}
+
+func TestMatchSharesLevels(t *testing.T) {
+	l := New()
+	l.Wallet("BTC").Balance = "3"
+
+	// 같은 호가로 매칭하면 앞 주문이 가져간 수량은 뒤 주문이 쓸 수 없다
+	bids := []Level{{Price: "100", Size: "1"}}
+	first := NewOrder(upbit.RequestParams{Market: "KRW-BTC", Side: "ask", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "1"}, time.Now())
+	second := NewOrder(upbit.RequestParams{Market: "KRW-BTC", Side: "ask", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "1"}, time.Now())
+	for _, o := range []*Order{first, second} {
+		if err := l.Place(o, "0"); err != nil {
+			t.Fatal(err)
+		}
+		l.Match(o, bids, "0.001", true)
+	}
+
+	if first.State != upbit.OrderStateDone || second.State != upbit.OrderStateWait {
+		t.Errorf("states = %s, %s, want done, wait", first.State, second.State)
+	}
+	if got := l.Waiting("KRW-BTC"); len(got) != 1 || got[0] != second {
+		t.Errorf("waiting = %v", got)
+	}
+	if krw := l.Wallet("KRW"); !krw.Balance.Equal("99.9") {
+		t.Errorf("KRW balance = %s, want 99.9", krw.Balance)
+	}
+}
+
+func TestPlaceInsufficientFunds(t *testing.T) {
+	l := New()
+	l.Wallet("KRW").Balance = "100"
+
+	o := NewOrder(upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypePrice, Price: "100"}, time.Now())
+	err := l.Place(o, "0.0005")
+	if apiErr, ok := err.(*upbit.APIError); !ok || apiErr.Name != upbit.ErrInsufficientFundsBid {
+		t.Fatalf("Place = %v, want %s", err, upbit.ErrInsufficientFundsBid)
+	}
+	if _, ok := l.Order(o.Uuid); ok || !l.Wallet("KRW").Locked.IsZero() {
+		t.Error("rejected order was recorded")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	l := New()
+	l.Wallet("KRW").Balance = "10000"
+
+	o := NewOrder(upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "100", Volume: "10", Identifier: "c-1"}, time.Now())
+	if err := l.Place(o, "0.0005"); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.ByIdentifier("c-1"); got != o {
+		t.Fatalf("ByIdentifier = %v", got)
+	}
+	if !l.Cancel(o) || l.Cancel(o) {
+		t.Error("Cancel should succeed once")
+	}
+	if krw := l.Wallet("KRW"); !krw.Balance.Equal("10000") || !krw.Locked.IsZero() {
+		t.Errorf("KRW = %+v after cancel", krw)
+	}
+	if got := l.Filter("", "", func(o *Order) bool { return o.State == upbit.OrderStateCancel }); len(got) != 1 {
+		t.Errorf("Filter = %v", got)
+	}
+}
@@ -0,0 +1,261 @@
+// Package journal 주문마다 클라이언트 주문 식별자(identifier)를 붙이고
+// identifier와 주문 UUID의 대응을 로컬에 저장해 같은 identifier로 다시 주문해도 중복 주문되지 않게 한다.
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+
+	"github.com/google/uuid"
+)
+
+// stateName identifier와 주문의 대응을 저장하는 파일 이름
+const stateName = "orders"
+
+// IdentifierPrefix 자동으로 만든 identifier의 접두사
+const IdentifierPrefix = "mcp-"
+
+// Retention 끝난 주문의 기록을 보관하는 기간. 지난 기록은 저장할 때 지운다.
+// 지운 identifier로 다시 주문해도 거래소가 duplicate_identifier로 거절하므로 기존 주문을 찾을 수 있다.
+const Retention = 30 * 24 * time.Hour
+
+// Entry identifier로 접수된 주문
+type Entry struct {
+	Uuid      string        `json:"uuid"`
+	Market    string        `json:"market"`
+	Side      string        `json:"side"`
+	OrdType   string        `json:"ord_type"`
+	Price     upbit.Decimal `json:"price,omitempty"`
+	Volume    upbit.Decimal `json:"volume,omitempty"`
+	State     string        `json:"state,omitempty"` // 저널을 거쳐 마지막으로 확인한 주문 상태
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// Journal 모든 주문에 identifier를 붙이고 접수된 주문을 기록하는 upbit.Trader.
+// 이미 접수된 identifier로 같은 주문을 다시 보내면 새로 주문하지 않고 기존 주문을 반환한다.
+type Journal struct {
+	upbit.Trader
+
+	store *store.Store
+
+	// mu 기록을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu      sync.Mutex
+	entries map[string]Entry
+	// pending 거래소 응답을 기다리는 identifier. 같은 identifier의 주문은 응답이 올 때까지 기다린다
+	pending map[string]chan struct{}
+}
+
+// New trader 앞에 주문 기록 계층을 둔다. st가 nil이면 기록을 메모리에만 보관한다.
+func New(trader upbit.Trader, st *store.Store) (*Journal, error) {
+	j := &Journal{
+		Trader:  trader,
+		store:   st,
+		entries: map[string]Entry{},
+		pending: map[string]chan struct{}{},
+	}
+	if st != nil {
+		if _, err := st.Load(stateName, &j.entries); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// NewIdentifier 새 identifier 생성
+func NewIdentifier() string {
+	return IdentifierPrefix + uuid.NewString()
+}
+
+// Lookup identifier로 접수된 주문 기록
+func (j *Journal) Lookup(identifier string) (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	e, ok := j.entries[identifier]
+	return e, ok
+}
+
+// PlaceOrder identifier를 붙여 주문한다. identifier가 없으면 만들어 붙인다.
+// 이미 접수된 identifier이면 마켓, 방향, 주문 종류, 가격, 수량이 같을 때 기존 주문을 반환하고 다르면 duplicate_identifier 오류를 반환한다.
+// 응답을 받지 못해 다시 보낸 주문도 거래소가 duplicate_identifier로 거절하므로 같은 방식으로 기존 주문을 찾는다.
+func (j *Journal) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	if params.Identifier == "" {
+		params.Identifier = NewIdentifier()
+	}
+
+	e, ok, err := j.claim(ctx, params.Identifier)
+	if err != nil {
+		return upbit.Order{}, err
+	}
+	if ok {
+		if !e.matches(params) {
+			return upbit.Order{}, duplicate(params.Identifier, e.Market, e.Side, e.OrdType)
+		}
+		return j.GetOrder(ctx, e.Uuid)
+	}
+
+	order, err := j.place(ctx, params)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	close(j.pending[params.Identifier])
+	delete(j.pending, params.Identifier)
+	if err != nil {
+		return order, err
+	}
+
+	j.entries[params.Identifier] = entryOf(order)
+	if err := j.save(); err != nil {
+		return order, fmt.Errorf("order %s was placed but its identifier %s could not be saved: %w", order.Uuid, params.Identifier, err)
+	}
+	return order, nil
+}
+
+// claim identifier의 기록을 찾는다. 기록이 없으면 identifier를 응답 대기 중으로 표시하고,
+// 같은 identifier의 주문이 응답을 기다리고 있으면 끝날 때까지 기다린다.
+func (j *Journal) claim(ctx context.Context, identifier string) (Entry, bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for {
+		if e, ok := j.entries[identifier]; ok {
+			return e, true, nil
+		}
+		wait, ok := j.pending[identifier]
+		if !ok {
+			j.pending[identifier] = make(chan struct{})
+			return Entry{}, false, nil
+		}
+
+		j.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			j.mu.Lock()
+			return Entry{}, false, ctx.Err()
+		}
+		j.mu.Lock()
+	}
+}
+
+// place 주문을 보내고, 이미 사용된 identifier라며 거절되면 그 identifier의 주문이 같은 주문인지 확인해 반환한다
+func (j *Journal) place(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	order, err := j.Trader.PlaceOrder(ctx, params)
+	if err == nil {
+		return order, nil
+	}
+	var apiErr *upbit.APIError
+	if !errors.As(err, &apiErr) || apiErr.Name != upbit.ErrDuplicateIdentifier {
+		return order, err
+	}
+	existing, lookupErr := j.Trader.GetOrderByIdentifier(ctx, params.Identifier)
+	if lookupErr != nil {
+		return order, err
+	}
+	if !entryOf(existing).matches(params) {
+		return upbit.Order{}, duplicate(params.Identifier, existing.Market, existing.Side, existing.OrdType)
+	}
+	return existing, nil
+}
+
+// GetOrder 주문 조회. 기록된 주문이면 상태를 기록해 두어 끝난 주문의 기록을 정리할 수 있게 한다
+func (j *Journal) GetOrder(ctx context.Context, uuid string) (upbit.Order, error) {
+	order, err := j.Trader.GetOrder(ctx, uuid)
+	if err == nil {
+		j.observe(order)
+	}
+	return order, err
+}
+
+// GetOrderByIdentifier identifier로 주문 조회. 기록된 identifier이면 UUID로 조회한다
+func (j *Journal) GetOrderByIdentifier(ctx context.Context, identifier string) (upbit.Order, error) {
+	if e, ok := j.Lookup(identifier); ok {
+		return j.GetOrder(ctx, e.Uuid)
+	}
+	return j.Trader.GetOrderByIdentifier(ctx, identifier)
+}
+
+// CancelOrderByIdentifier identifier로 주문 취소. 기록된 identifier이면 UUID로 취소한다
+func (j *Journal) CancelOrderByIdentifier(ctx context.Context, identifier string) (bool, error) {
+	if e, ok := j.Lookup(identifier); ok {
+		return j.Trader.CancelOrder(ctx, e.Uuid)
+	}
+	return j.Trader.CancelOrderByIdentifier(ctx, identifier)
+}
+
+// observe 조회한 주문의 상태를 기록에 반영한다. 상태가 바뀌었을 때만 저장한다
+func (j *Journal) observe(order upbit.Order) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e, ok := j.entries[order.Identifier]
+	if !ok || e.Uuid != order.Uuid || e.State == order.State {
+		return
+	}
+	e.State = order.State
+	j.entries[order.Identifier] = e
+	if err := j.save(); err != nil {
+		log.Printf("journal: could not save the state of order %s: %v", order.Uuid, err)
+	}
+}
+
+// save 보관 기간이 지난 끝난 주문의 기록을 지우고 저장한다
+func (j *Journal) save() error {
+	j.prune(time.Now())
+	if j.store == nil {
+		return nil
+	}
+	return j.store.Save(stateName, j.entries)
+}
+
+// prune 끝난 것으로 확인된 주문 중 Retention보다 오래된 기록을 지운다. 대기 중이거나 상태를 모르는 주문은 남긴다
+func (j *Journal) prune(now time.Time) {
+	for identifier, e := range j.entries {
+		closed := e.State == upbit.OrderStateDone || e.State == upbit.OrderStateCancel
+		if closed && now.Sub(e.CreatedAt) > Retention {
+			delete(j.entries, identifier)
+		}
+	}
+}
+
+// matches 같은 주문을 다시 보낸 것인지 여부. 가격과 수량은 기록된 값이 있을 때만 비교한다
+func (e Entry) matches(params upbit.RequestParams) bool {
+	if e.Market != params.Market || e.Side != params.Side || e.OrdType != params.OrdType {
+		return false
+	}
+	if e.Price != "" && !e.Price.Equal(params.Price) {
+		return false
+	}
+	return e.Volume == "" || e.Volume.Equal(params.Volume)
+}
+
+func entryOf(o upbit.Order) Entry {
+	createdAt, err := time.Parse(time.RFC3339, o.CreatedAt)
+	if err != nil {
+		createdAt = time.Now()
+	}
+	return Entry{
+		Uuid:      o.Uuid,
+		Market:    o.Market,
+		Side:      o.Side,
+		OrdType:   o.OrdType,
+		Price:     o.Price,
+		Volume:    o.Volume,
+		State:     o.State,
+		CreatedAt: createdAt,
+	}
+}
+
+func duplicate(identifier, market, side, ordType string) error {
+	return &upbit.APIError{
+		StatusCode: 400,
+		Name:       upbit.ErrDuplicateIdentifier,
+		Message:    fmt.Sprintf("identifier %s is already used by a %s %s order on %s", identifier, side, ordType, market),
+	}
+}
@@ -0,0 +1,87 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+func newJournal(t *testing.T) (*Journal, *upbittest.Exchange) {
+	t.Helper()
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+
+	j, err := New(ex.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j, ex
+}
+
+func TestPlaceOrderSameIdentifierConcurrently(t *testing.T) {
+	j, ex := newJournal(t)
+	params := upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "90000000", Volume: "0.001", Identifier: "same-1"}
+
+	var wg sync.WaitGroup
+	uuids := make([]string, 4)
+	for i := range uuids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order, err := j.PlaceOrder(context.Background(), params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			uuids[i] = order.Uuid
+		}()
+	}
+	wg.Wait()
+
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+	for _, u := range uuids {
+		if u != uuids[0] {
+			t.Errorf("uuids = %v, want the same order", uuids)
+			break
+		}
+	}
+}
+
+func TestPlaceOrderDifferentPrice(t *testing.T) {
+	j, _ := newJournal(t)
+	ctx := context.Background()
+	params := upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "90000000", Volume: "0.001", Identifier: "price-1"}
+	if _, err := j.PlaceOrder(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	// 가격이 다른 주문은 같은 identifier를 쓸 수 없다
+	params.Price = "91000000"
+	_, err := j.PlaceOrder(ctx, params)
+	var apiErr *upbit.APIError
+	if !errors.As(err, &apiErr) || apiErr.Name != upbit.ErrDuplicateIdentifier {
+		t.Errorf("PlaceOrder = %v, want %s", err, upbit.ErrDuplicateIdentifier)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	j, _ := newJournal(t)
+	now := time.Now()
+	old := now.Add(-Retention - time.Hour)
+	j.entries = map[string]Entry{
+		"old-done": {State: upbit.OrderStateDone, CreatedAt: old},
+		"old-wait": {State: upbit.OrderStateWait, CreatedAt: old},
+		"old-none": {CreatedAt: old},
+		"new-done": {State: upbit.OrderStateDone, CreatedAt: now},
+	}
+
+	j.prune(now)
+	if _, ok := j.entries["old-done"]; ok || len(j.entries) != 3 {
+		t.Errorf("entries = %v", j.entries)
+	}
+}
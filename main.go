@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 	"upbit-mcp-server/upbit"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+const paperInstructions = `This server is running in paper trading mode.
+Orders are simulated against live Upbit order books and never sent to the exchange.
+Accounts, open orders, order history and cancellations reflect the virtual paper account.`
+
 func main() {
 	getAvailableOrderInfoDescription := `Retrieves the order availability information for the specified pair. 
 		The response doesn't include current trading pair prices 
@@ -20,34 +23,126 @@ func main() {
 				The response doesn't include current trading pair prices 
 				you should consider the current price if you want to decide whether to buy or sell.`
 
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var serverOpts mcp.ServerOptions
+	if cfg.Paper {
+		serverOpts.Instructions = paperInstructions
+	}
+
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "greeter",
 		Version: "v1.0.0",
-	}, nil)
+	}, &serverOpts)
 
 	server.AddReceivingMiddleware(createLoggingMiddleware())
 
-	accessKey := os.Getenv("UPBIT_ACCESS_KEY")
-	secretKey := os.Getenv("UPBIT_SECRET_KEY")
-	if accessKey == "" || secretKey == "" {
-		log.Fatal("UPBIT_ACCESS_KEY and UPBIT_SECRET_KEY must be set")
+	clientOpts, err := cfg.clientOptions()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	client := upbit.NewClient(accessKey, secretKey)
+	client := upbit.NewClient(cfg.AccessKey, cfg.SecretKey, clientOpts...)
 	ctx := context.WithValue(context.Background(), upbitClientKey{}, client)
 
+	st, err := cfg.openStore()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	trader, err := cfg.trader(client, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, upbitTraderKey{}, trader)
+
+	engine, err := cfg.conditionalEngine(trader, client, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, conditionalEngineKey{}, engine)
+	go engine.Run(ctx)
+
+	scheduler, err := cfg.dcaScheduler(trader, client, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, dcaSchedulerKey{}, scheduler)
+	go scheduler.Run(ctx)
+
+	grids, err := cfg.gridEngine(trader, client, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, gridEngineKey{}, grids)
+	go grids.Run(ctx)
+
+	executions, err := cfg.executionEngine(trader, client, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, executionEngineKey{}, executions)
+	go executions.Run(ctx)
+
+	icebergs, err := cfg.icebergEngine(trader, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, icebergEngineKey{}, icebergs)
+	go icebergs.Run(ctx)
+
+	if policy := cfg.confirmPolicy(); policy != nil {
+		ctx = context.WithValue(ctx, confirmPolicyKey{}, policy)
+	}
+	if cfg.Paper {
+		log.Printf("paper trading mode: orders are simulated with an initial balance of %s KRW", cfg.PaperBalance)
+	}
+
 	// Add MCP tools
 	mcp.AddTool(server, &mcp.Tool{Name: "GetAccounts", Description: "전체 계좌 조회"}, GetAccounts)
 	mcp.AddTool(server, &mcp.Tool{Name: "PlaceBuyOrderByLimit", Description: "지정가 매수 주문하기"}, PlaceBuyOrderByLimit)
 	mcp.AddTool(server, &mcp.Tool{Name: "PlaceBuyOrderByMarket", Description: "시장가 매수 주문하기"}, PlaceBuyOrderByMarket)
 	mcp.AddTool(server, &mcp.Tool{Name: "PlaceSellOrderByLimit", Description: "지정가 매도 주문하기"}, PlaceSellOrderByLimit)
 	mcp.AddTool(server, &mcp.Tool{Name: "PlaceSellOrderByMarket", Description: "시장가 매도 주문하기"}, PlaceSellOrderByMarket)
+	mcp.AddTool(server, &mcp.Tool{Name: "PlaceBuyOrderByBest", Description: "최유리 매수 주문하기 (Buy at the best ask prices for a total amount, with time_in_force ioc or fok)"}, PlaceBuyOrderByBest)
+	mcp.AddTool(server, &mcp.Tool{Name: "PlaceSellOrderByBest", Description: "최유리 매도 주문하기 (Sell a volume at the best bid prices, with time_in_force ioc or fok)"}, PlaceSellOrderByBest)
 	mcp.AddTool(server, &mcp.Tool{Name: "CancelOrder", Description: "주문 취소하기"}, CancelOrder)
+	mcp.AddTool(server, &mcp.Tool{Name: "ReplaceOrder", Description: "Move an open limit order to a new price: cancel it, wait until the cancellation is final, and place a new limit order for the remaining (unfilled) volume. Reports both the canceled and the new order"}, ReplaceOrder)
+	mcp.AddTool(server, &mcp.Tool{Name: "CancelAllOrders", Description: "Cancel all open orders matching the filters (market, side, age, price range) and report the result for each order. Cancels every open order if no filter is given"}, CancelAllOrders)
+	mcp.AddTool(server, &mcp.Tool{Name: "CreateConditionalOrder", Description: "Register a server-side stop-loss or take-profit order that sells a held position when the current price reaches trigger_price. The server checks the price periodically and places the sell order without further confirmation"}, CreateConditionalOrder)
+	mcp.AddTool(server, &mcp.Tool{Name: "CreateOCOOrder", Description: "Register a stop-loss and a take-profit order on the same position as a one-cancels-the-other bracket. When one leg triggers, the other is canceled"}, CreateOCOOrder)
+	mcp.AddTool(server, &mcp.Tool{Name: "CreateTrailingStopOrder", Description: "Register a server-side trailing stop that follows the highest price since creation and sells a held volume at market price when the price falls by trail_percent or trail_amount from that high"}, CreateTrailingStopOrder)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetConditionalOrders", Description: "List conditional (stop-loss, take-profit, trailing stop) orders with their state, trigger price and the order placed when triggered. For trailing stops, high_price is the highest price observed so far and trigger_price is the current trail level"}, GetConditionalOrders)
+	mcp.AddTool(server, &mcp.Tool{Name: "CancelConditionalOrder", Description: "Cancel a pending conditional order. Canceling one leg of an OCO bracket cancels the other leg too"}, CancelConditionalOrder)
+	mcp.AddTool(server, &mcp.Tool{Name: "CreateRecurringBuy", Description: "Create a recurring (dollar-cost averaging) buy plan that buys a fixed amount at market price on a cron schedule in KST, optionally skipping runs while the price is above a ceiling"}, CreateRecurringBuy)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetRecurringBuys", Description: "List recurring buy plans with their schedule, next run and the result of the last run"}, GetRecurringBuys)
+	mcp.AddTool(server, &mcp.Tool{Name: "PauseRecurringBuy", Description: "Pause a recurring buy plan"}, PauseRecurringBuy)
+	mcp.AddTool(server, &mcp.Tool{Name: "ResumeRecurringBuy", Description: "Resume a paused recurring buy plan from its next scheduled time"}, ResumeRecurringBuy)
+	mcp.AddTool(server, &mcp.Tool{Name: "DeleteRecurringBuy", Description: "Delete a recurring buy plan. Its execution history is kept"}, DeleteRecurringBuy)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetRecurringBuyHistory", Description: "Get the execution history of recurring buy plans, most recent first"}, GetRecurringBuyHistory)
+	mcp.AddTool(server, &mcp.Tool{Name: "StartGrid", Description: "Start a grid trading bot: split a price range into evenly spaced levels, place limit buy orders below the current price and limit sell orders above it (buying their volume at market price first), and re-place the opposite order whenever an order fills. Use dry_run to preview the layout"}, StartGrid)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetGrids", Description: "List grid trading bots with their realized profit, completed round trips, held volume and open orders"}, GetGrids)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetGrid", Description: "Get a grid trading bot with the state of every cell"}, GetGrid)
+	mcp.AddTool(server, &mcp.Tool{Name: "StopGrid", Description: "Stop a grid trading bot and cancel its open orders. The volume it bought is kept in the account"}, StopGrid)
+	mcp.AddTool(server, &mcp.Tool{Name: "StartExecution", Description: "Execute a large market order over a time window by splitting it into child market orders: twap sends equal slices at equal intervals, vwap sizes slices by the historical minute volume profile of the same window. Reports the average fill price against the arrival price"}, StartExecution)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetExecutions", Description: "List TWAP/VWAP executions with their progress, average price and slippage against the arrival price"}, GetExecutions)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetExecution", Description: "Get a TWAP/VWAP execution with the schedule and fills of every child order"}, GetExecution)
+	mcp.AddTool(server, &mcp.Tool{Name: "AbortExecution", Description: "Abort a running TWAP/VWAP execution. Child orders already filled are kept and no further child orders are sent"}, AbortExecution)
+	mcp.AddTool(server, &mcp.Tool{Name: "StartIceberg", Description: "Place a large limit order as an iceberg: only visible_volume is shown in the order book at a time, and the server refills it from the hidden remainder at the same price whenever the visible order fills"}, StartIceberg)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetIcebergs", Description: "List iceberg orders with their filled and hidden volume"}, GetIcebergs)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetIceberg", Description: "Get an iceberg order with every child order it placed"}, GetIceberg)
+	mcp.AddTool(server, &mcp.Tool{Name: "CancelIceberg", Description: "Cancel an iceberg order: cancel the visible child order and stop refilling. Volume already filled is kept"}, CancelIceberg)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetOrder", Description: "Get a single order with its individual trades, average fill price, filled percentage and total fees paid"}, GetOrder)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "GetAvailableOrderInfo", Description: getAvailableOrderInfoDescription}, GetAvailableOrderInfo)
+	mcp.AddTool(server, &mcp.Tool{Name: "EstimateMarketOrder", Description: "Estimate a market order without placing it: walk the current order book to get the expected average fill price, slippage and price impact against the mid price, taker fees, and whether the visible depth can absorb the requested amount or volume"}, EstimateMarketOrder)
 	mcp.AddTool(server, &mcp.Tool{Name: "GetClosedOrderHistory", Description: getClosedOrderHistoryDescription}, GetClosedOrderHistory)
 	mcp.AddTool(server, &mcp.Tool{Name: "GetOpenOrders", Description: getOpenOrdersDescription}, GetOpenOrders)
 	mcp.AddTool(server, &mcp.Tool{Name: "GetMarketSummary", Description: "Summarized multiple market information. If given market is unavailable in Upbit, then the return value doesn't include it"}, GetMarketSummary)
+	mcp.AddTool(server, &mcp.Tool{Name: "GetOrderBook", Description: "Get the order books of multiple markets, optionally grouped by a price unit (level), with spread in bps, mid price, bid/ask imbalance of the best levels and cumulative depth within a percentage of the mid price"}, GetOrderBook)
 	mcp.AddTool(server, &mcp.Tool{Name: "GetMarketTrends", Description: "Get market trends, top 10 market by volume, top 10 gainers and top 10 losers"}, GetMarketTrends)
 	mcp.AddTool(server, &mcp.Tool{Name: "GetDayCandles", Description: "Get daily candles"}, GetDayCandles)
 	mcp.AddTool(server, &mcp.Tool{Name: "GetWeekCandles", Description: "Get weekly candles"}, GetWeekCandles)
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 	"upbit-mcp-server/upbit"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -12,6 +14,10 @@ import (
 // upbitClientKey는 context 내에서 Upbit 클라이언트를 식별하기 위한 키
 type upbitClientKey struct{}
 
+// upbitTraderKey는 context 내에서 계좌 조회와 주문을 처리할 upbit.Trader를 식별하기 위한 키.
+// 모의 거래 모드에서는 실제 클라이언트 대신 가상 거래소가 들어간다.
+type upbitTraderKey struct{}
+
 type GetAccountsResult struct {
 	Accounts []upbit.Account `json:"accounts"`
 }
@@ -47,35 +53,117 @@ type GetOpenOrderHistoryResult struct {
 }
 
 type PlaceBuyOrderByLimitRequest struct {
-	Market string `json:"market" jsonschema:"Trading pair code representing the market."`
-	Price  string `json:"price" jsonschema:"Order price based on the quote currency. e.g. when buying 1 BTC at 100,000,000 KRW per BTC in the KRW-BTC market, enter 100000000."`
-	Volume string `json:"volume" jsonschema:"Order quantity. e.g. to buy 0.1 BTC in the KRW-BTC market, enter 0.1"`
+	Market        string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Price         upbit.Decimal `json:"price" jsonschema:"Order price based on the quote currency. e.g. when buying 1 BTC at 100,000,000 KRW per BTC in the KRW-BTC market, enter 100000000."`
+	Volume        upbit.Decimal `json:"volume" jsonschema:"Order quantity. e.g. to buy 0.1 BTC in the KRW-BTC market, enter 0.1"`
+	PriceRounding string        `json:"price_rounding,omitempty" jsonschema:"Optional. Align the price to the Upbit tick size (price unit) of the market before placing the order. Allowed: 'down', 'up', 'nearest'. If omitted, the price is sent as is and Upbit rejects prices that are not aligned to the tick size."`
+	Validate      bool          `json:"validate,omitempty" jsonschema:"Optional. If true, check the order against the market's order availability info (market state, supported order type, min/max order total, available balance including fees) and reject it with a reason without sending it to Upbit."`
+	Identifier    string        `json:"identifier,omitempty" jsonschema:"Optional client order id, unique per order. Generated automatically when omitted and returned in the result. Sending the same order again with the same identifier (e.g. after a timeout) returns the existing order instead of placing a new one. Use it to look up or cancel the order later."`
+	TimeInForce   string        `json:"time_in_force,omitempty" jsonschema:"Optional. 'ioc' fills what can be filled immediately and cancels the rest, 'fok' fills the whole volume immediately or cancels the whole order. If omitted, the unfilled volume stays on the order book."`
+	SmpType       string        `json:"smp_type,omitempty" jsonschema:"Optional self-match prevention mode, applied when this order would trade with your own order. 'cancel_maker' cancels the resting order (default), 'cancel_taker' cancels this order, 'reduce' reduces both orders by the overlapping volume."`
+}
+
+// PlaceLimitOrderResult 지정가 주문 결과. 호가 단위에 맞춰 가격을 조정했다면 요청한 가격을 함께 알려준다.
+type PlaceLimitOrderResult struct {
+	upbit.Order
+	RequestedPrice upbit.Decimal `json:"requested_price" jsonschema:"Price requested by the caller before tick size adjustment"`
+	PriceAdjusted  bool          `json:"price_adjusted" jsonschema:"Whether the price was changed to align with the tick size"`
 }
 
 type PlaceBuyOrderByMarketRequest struct {
-	Market string `json:"market" jsonschema:"Trading pair code representing the market."`
-	Price  string `json:"price" jsonschema:"Total order amount based on the quote currency. For example, entering 100000000 in the KRW-BTC pair will buy BTC worth 100,000,000 KRW at market price."`
+	Market     string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Price      upbit.Decimal `json:"price" jsonschema:"Total order amount based on the quote currency. For example, entering 100000000 in the KRW-BTC pair will buy BTC worth 100,000,000 KRW at market price."`
+	Validate   bool          `json:"validate,omitempty" jsonschema:"Optional. If true, check the order against the market's order availability info (market state, supported order type, min/max order total, available balance including fees) and reject it with a reason without sending it to Upbit."`
+	Identifier string        `json:"identifier,omitempty" jsonschema:"Optional client order id, unique per order. Generated automatically when omitted and returned in the result. Sending the same order again with the same identifier (e.g. after a timeout) returns the existing order instead of placing a new one. Use it to look up or cancel the order later."`
+	SmpType    string        `json:"smp_type,omitempty" jsonschema:"Optional self-match prevention mode, applied when this order would trade with your own order. 'cancel_maker' cancels the resting order (default), 'cancel_taker' cancels this order, 'reduce' reduces both orders by the overlapping volume."`
 }
 
 type PlaceSellOrderByLimitRequest struct {
-	Market string `json:"market" jsonschema:"Trading pair code representing the market."`
-	Price  string `json:"price" jsonschema:"Order price based on the quote currency. For example, when selling 1 BTC at 100,000,000 KRW per BTC in the KRW-BTC market, enter 100000000."`
-	Volume string `json:"volume" jsonschema:"Order quantity e.g. to sell 0.1 BTC in the KRW-BTC market, enter 0.1"`
+	Market        string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Price         upbit.Decimal `json:"price" jsonschema:"Order price based on the quote currency. For example, when selling 1 BTC at 100,000,000 KRW per BTC in the KRW-BTC market, enter 100000000."`
+	Volume        upbit.Decimal `json:"volume" jsonschema:"Order quantity e.g. to sell 0.1 BTC in the KRW-BTC market, enter 0.1"`
+	PriceRounding string        `json:"price_rounding,omitempty" jsonschema:"Optional. Align the price to the Upbit tick size (price unit) of the market before placing the order. Allowed: 'down', 'up', 'nearest'. If omitted, the price is sent as is and Upbit rejects prices that are not aligned to the tick size."`
+	Validate      bool          `json:"validate,omitempty" jsonschema:"Optional. If true, check the order against the market's order availability info (market state, supported order type, min/max order total, available balance including fees) and reject it with a reason without sending it to Upbit."`
+	Identifier    string        `json:"identifier,omitempty" jsonschema:"Optional client order id, unique per order. Generated automatically when omitted and returned in the result. Sending the same order again with the same identifier (e.g. after a timeout) returns the existing order instead of placing a new one. Use it to look up or cancel the order later."`
+	TimeInForce   string        `json:"time_in_force,omitempty" jsonschema:"Optional. 'ioc' fills what can be filled immediately and cancels the rest, 'fok' fills the whole volume immediately or cancels the whole order. If omitted, the unfilled volume stays on the order book."`
+	SmpType       string        `json:"smp_type,omitempty" jsonschema:"Optional self-match prevention mode, applied when this order would trade with your own order. 'cancel_maker' cancels the resting order (default), 'cancel_taker' cancels this order, 'reduce' reduces both orders by the overlapping volume."`
 }
 
 type PlaceSellOrderByMarketRequest struct {
-	Market string `json:"market" jsonschema:"Trading pair code representing the market."`
-	Volume string `json:"volume" jsonschema:"Sell order quantity. For example, entering 0.1 in the KRW-BTC pair will sell 0.1 BTC at market price"`
+	Market     string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Volume     upbit.Decimal `json:"volume" jsonschema:"Sell order quantity. For example, entering 0.1 in the KRW-BTC pair will sell 0.1 BTC at market price"`
+	Validate   bool          `json:"validate,omitempty" jsonschema:"Optional. If true, check the order against the market's order availability info (market state, supported order type, min/max order total, available balance including fees) and reject it with a reason without sending it to Upbit."`
+	Identifier string        `json:"identifier,omitempty" jsonschema:"Optional client order id, unique per order. Generated automatically when omitted and returned in the result. Sending the same order again with the same identifier (e.g. after a timeout) returns the existing order instead of placing a new one. Use it to look up or cancel the order later."`
+	SmpType    string        `json:"smp_type,omitempty" jsonschema:"Optional self-match prevention mode, applied when this order would trade with your own order. 'cancel_maker' cancels the resting order (default), 'cancel_taker' cancels this order, 'reduce' reduces both orders by the overlapping volume."`
+}
+
+type PlaceBuyOrderByBestRequest struct {
+	Market      string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Price       upbit.Decimal `json:"price" jsonschema:"Total order amount based on the quote currency. The order buys at the best ask prices until this amount is spent."`
+	TimeInForce string        `json:"time_in_force" jsonschema:"Required for best price orders. 'ioc' fills what can be filled immediately and cancels the rest, 'fok' fills the whole order immediately or cancels it. Allowed: 'ioc', 'fok'."`
+	SmpType     string        `json:"smp_type,omitempty" jsonschema:"Optional self-match prevention mode, applied when this order would trade with your own order. 'cancel_maker' cancels the resting order (default), 'cancel_taker' cancels this order, 'reduce' reduces both orders by the overlapping volume."`
+	Validate    bool          `json:"validate,omitempty" jsonschema:"Optional. If true, check the order against the market's order availability info (market state, supported order type, min/max order total, available balance including fees) and reject it with a reason without sending it to Upbit."`
+	Identifier  string        `json:"identifier,omitempty" jsonschema:"Optional client order id, unique per order. Generated automatically when omitted and returned in the result. Sending the same order again with the same identifier (e.g. after a timeout) returns the existing order instead of placing a new one. Use it to look up or cancel the order later."`
+}
+
+type PlaceSellOrderByBestRequest struct {
+	Market      string        `json:"market" jsonschema:"Trading pair code representing the market."`
+	Volume      upbit.Decimal `json:"volume" jsonschema:"Sell order quantity. The order sells at the best bid prices."`
+	TimeInForce string        `json:"time_in_force" jsonschema:"Required for best price orders. 'ioc' fills what can be filled immediately and cancels the rest, 'fok' fills the whole order immediately or cancels it. Allowed: 'ioc', 'fok'."`
+	SmpType     string        `json:"smp_type,omitempty" jsonschema:"Optional self-match prevention mode, applied when this order would trade with your own order. 'cancel_maker' cancels the resting order (default), 'cancel_taker' cancels this order, 'reduce' reduces both orders by the overlapping volume."`
+	Validate    bool          `json:"validate,omitempty" jsonschema:"Optional. If true, check the order against the market's order availability info (market state, supported order type, min/max order total, available balance including fees) and reject it with a reason without sending it to Upbit."`
+	Identifier  string        `json:"identifier,omitempty" jsonschema:"Optional client order id, unique per order. Generated automatically when omitted and returned in the result. Sending the same order again with the same identifier (e.g. after a timeout) returns the existing order instead of placing a new one. Use it to look up or cancel the order later."`
 }
 
 type CancelOrderRequest struct {
-	UUID string `json:"uuid" jsonschema:"Unique identifier (UUID) for the order to cancel."`
+	UUID       string `json:"uuid,omitempty" jsonschema:"Unique identifier (UUID) for the order to cancel. Either uuid or identifier is required."`
+	Identifier string `json:"identifier,omitempty" jsonschema:"Client order id given when the order was placed. Either uuid or identifier is required."`
 }
 
 type CancelOrderResult struct {
 	Canceled bool `json:"canceled" jsonschema:"Whether the order is canceled or not"`
 }
 
+type CancelAllOrdersRequest struct {
+	Market    string        `json:"market,omitempty" jsonschema:"Optional. Only cancel open orders of this market (e.g. KRW-BTC). All markets if omitted."`
+	Side      string        `json:"side,omitempty" jsonschema:"Optional. Only cancel orders of this side. Allowed: 'bid' (buy), 'ask' (sell)."`
+	OlderThan string        `json:"older_than,omitempty" jsonschema:"Optional. Only cancel orders created longer ago than this duration, e.g. '30m', '2h'."`
+	MinPrice  upbit.Decimal `json:"min_price,omitempty" jsonschema:"Optional. Only cancel orders whose price is greater than or equal to this price."`
+	MaxPrice  upbit.Decimal `json:"max_price,omitempty" jsonschema:"Optional. Only cancel orders whose price is less than or equal to this price."`
+}
+
+// CancelAllOrdersResult 일괄 취소 결과. 주문별 성공/실패를 함께 알려준다
+type CancelAllOrdersResult struct {
+	Matched  int                 `json:"matched" jsonschema:"Number of open orders that matched the filters."`
+	Canceled int                 `json:"canceled" jsonschema:"Number of orders that were canceled."`
+	Failed   int                 `json:"failed" jsonschema:"Number of orders that could not be canceled."`
+	Orders   []CancelOrderReport `json:"orders" jsonschema:"Result for each matched order."`
+}
+
+type CancelOrderReport struct {
+	Uuid       string            `json:"uuid"`
+	Identifier string            `json:"identifier,omitempty"`
+	Market     string            `json:"market"`
+	Side       string            `json:"side"`
+	Price      upbit.Decimal     `json:"price"`
+	Canceled   bool              `json:"canceled"`
+	Error      *toolErrorPayload `json:"error,omitempty" jsonschema:"Why the order could not be canceled."`
+}
+
+type GetOrderRequest struct {
+	UUID       string `json:"uuid,omitempty" jsonschema:"Unique identifier (UUID) of the order to look up. Either uuid or identifier is required."`
+	Identifier string `json:"identifier,omitempty" jsonschema:"Client order id given when the order was placed. Either uuid or identifier is required."`
+}
+
+// GetOrderResult 주문 상세 정보와 체결 내역으로 계산한 값
+type GetOrderResult struct {
+	upbit.Order
+	AvgFillPrice  upbit.Decimal `json:"avg_fill_price" jsonschema:"Volume weighted average price of the executed trades. 0 if nothing is filled."`
+	ExecutedFunds upbit.Decimal `json:"executed_funds" jsonschema:"Total executed amount in the quote currency, excluding fees."`
+	FilledPercent float64       `json:"filled_percent" jsonschema:"Percentage of the order that is filled. For market buy orders, the percentage of the order amount that is spent."`
+	TotalFee      upbit.Decimal `json:"total_fee" jsonschema:"Total fees paid for the executed trades."`
+}
+
 type GetTradingPairRequest struct {
 	Markets        []string `json:"markets" jsonschema:"If you leave this as empty string, then it returns all market events otherwise it gives you only given markets"`
 	OnlyKrwMarkets bool     `json:"only_krw_markets" jsonschema:"If this enabled, then it returns only KRW(won) markets."`
@@ -111,16 +199,16 @@ func GetAccounts(ctx context.Context, req *mcp.CallToolRequest, params any) (
 	*GetAccountsResult,
 	error,
 ) {
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
 	var res mcp.CallToolResult
 
-	accounts, err := client.GetAccounts()
+	accounts, err := trader.GetAccounts(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &res, &GetAccountsResult{Accounts: accounts}, nil
@@ -128,29 +216,56 @@ func GetAccounts(ctx context.Context, req *mcp.CallToolRequest, params any) (
 
 func PlaceBuyOrderByLimit(ctx context.Context, req *mcp.CallToolRequest, params *PlaceBuyOrderByLimitRequest) (
 	*mcp.CallToolResult,
-	*upbit.Order,
+	*PlaceLimitOrderResult,
 	error,
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	orderResult, err := client.PlaceOrder(upbit.RequestParams{
-		Market:  params.Market,
-		Side:    "bid",
-		OrdType: "limit",
-		Price:   params.Price,
-		Volume:  params.Volume,
-		SmpType: "cancel_maker",
-	})
+	price, err := normalizeLimitPrice(params.Market, params.Price, params.PriceRounding)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	smpType, err := orderOptions(params.TimeInForce, params.SmpType, false)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return &res, &orderResult, nil
+	orderParams := upbit.RequestParams{
+		Market:      params.Market,
+		Side:        "bid",
+		OrdType:     "limit",
+		Price:       price,
+		Volume:      params.Volume,
+		SmpType:     smpType,
+		TimeInForce: params.TimeInForce,
+		Identifier:  params.Identifier,
+	}
+	if params.Validate {
+		if err := checkOrder(ctx, trader, orderParams); err != nil {
+			return nil, nil, toolError(err)
+		}
+	}
+
+	if err := confirmOrder(ctx, req, orderParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	orderResult, err := trader.PlaceOrder(ctx, orderParams)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &PlaceLimitOrderResult{
+		Order:          orderResult,
+		RequestedPrice: params.Price,
+		PriceAdjusted:  !price.Equal(params.Price),
+	}, nil
 }
 
 func PlaceBuyOrderByMarket(ctx context.Context, req *mcp.CallToolRequest, params *PlaceBuyOrderByMarketRequest) (
@@ -160,75 +275,224 @@ func PlaceBuyOrderByMarket(ctx context.Context, req *mcp.CallToolRequest, params
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	orderResult, err := client.PlaceOrder(upbit.RequestParams{
-		Market:  params.Market,
-		Side:    "bid",
-		OrdType: "price",
-		Price:   params.Price,
-		SmpType: "cancel_maker",
-	})
+	smpType, err := orderOptions("", params.SmpType, false)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	orderParams := upbit.RequestParams{
+		Market:     params.Market,
+		Side:       "bid",
+		OrdType:    "price",
+		Price:      params.Price,
+		SmpType:    smpType,
+		Identifier: params.Identifier,
+	}
+	if params.Validate {
+		if err := checkOrder(ctx, trader, orderParams); err != nil {
+			return nil, nil, toolError(err)
+		}
+	}
+
+	if err := confirmOrder(ctx, req, orderParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	orderResult, err := trader.PlaceOrder(ctx, orderParams)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
 	return &res, &orderResult, nil
 }
 
 func PlaceSellOrderByLimit(ctx context.Context, req *mcp.CallToolRequest, params *PlaceSellOrderByLimitRequest) (
+	*mcp.CallToolResult,
+	*PlaceLimitOrderResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return nil, nil, missingDependency("Upbit trader")
+	}
+
+	price, err := normalizeLimitPrice(params.Market, params.Price, params.PriceRounding)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	smpType, err := orderOptions(params.TimeInForce, params.SmpType, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orderParams := upbit.RequestParams{
+		Market:      params.Market,
+		Side:        "ask",
+		OrdType:     "limit",
+		Price:       price,
+		Volume:      params.Volume,
+		SmpType:     smpType,
+		TimeInForce: params.TimeInForce,
+		Identifier:  params.Identifier,
+	}
+	if params.Validate {
+		if err := checkOrder(ctx, trader, orderParams); err != nil {
+			return nil, nil, toolError(err)
+		}
+	}
+
+	if err := confirmOrder(ctx, req, orderParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	orderResult, err := trader.PlaceOrder(ctx, orderParams)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &PlaceLimitOrderResult{
+		Order:          orderResult,
+		RequestedPrice: params.Price,
+		PriceAdjusted:  !price.Equal(params.Price),
+	}, nil
+}
+
+func PlaceSellOrderByMarket(ctx context.Context, req *mcp.CallToolRequest, params *PlaceSellOrderByMarketRequest) (
 	*mcp.CallToolResult,
 	*upbit.Order,
 	error,
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	orderResult, err := client.PlaceOrder(upbit.RequestParams{
-		Market:  params.Market,
-		Side:    "ask",
-		OrdType: "limit",
-		Price:   params.Price,
-		Volume:  params.Volume,
-		SmpType: "cancel_maker",
-	})
+	smpType, err := orderOptions("", params.SmpType, false)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	orderParams := upbit.RequestParams{
+		Market:     params.Market,
+		Side:       "ask",
+		OrdType:    "market",
+		Volume:     params.Volume,
+		SmpType:    smpType,
+		Identifier: params.Identifier,
+	}
+	if params.Validate {
+		if err := checkOrder(ctx, trader, orderParams); err != nil {
+			return nil, nil, toolError(err)
+		}
+	}
+
+	if err := confirmOrder(ctx, req, orderParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	orderResult, err := trader.PlaceOrder(ctx, orderParams)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
 	return &res, &orderResult, nil
 }
 
-func PlaceSellOrderByMarket(ctx context.Context, req *mcp.CallToolRequest, params *PlaceSellOrderByMarketRequest) (
+func PlaceBuyOrderByBest(ctx context.Context, req *mcp.CallToolRequest, params *PlaceBuyOrderByBestRequest) (
 	*mcp.CallToolResult,
 	*upbit.Order,
 	error,
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	orderResult, err := client.PlaceOrder(upbit.RequestParams{
-		Market:  params.Market,
-		Side:    "ask",
-		OrdType: "market",
-		Volume:  params.Volume,
-		SmpType: "cancel_maker",
-	})
+	smpType, err := orderOptions(params.TimeInForce, params.SmpType, true)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	orderParams := upbit.RequestParams{
+		Market:      params.Market,
+		Side:        "bid",
+		OrdType:     upbit.OrdTypeBest,
+		Price:       params.Price,
+		SmpType:     smpType,
+		TimeInForce: params.TimeInForce,
+		Identifier:  params.Identifier,
+	}
+	if params.Validate {
+		if err := checkOrder(ctx, trader, orderParams); err != nil {
+			return nil, nil, toolError(err)
+		}
+	}
+
+	if err := confirmOrder(ctx, req, orderParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	orderResult, err := trader.PlaceOrder(ctx, orderParams)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &orderResult, nil
+}
+
+func PlaceSellOrderByBest(ctx context.Context, req *mcp.CallToolRequest, params *PlaceSellOrderByBestRequest) (
+	*mcp.CallToolResult,
+	*upbit.Order,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return nil, nil, missingDependency("Upbit trader")
+	}
+
+	smpType, err := orderOptions(params.TimeInForce, params.SmpType, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orderParams := upbit.RequestParams{
+		Market:      params.Market,
+		Side:        "ask",
+		OrdType:     upbit.OrdTypeBest,
+		Volume:      params.Volume,
+		SmpType:     smpType,
+		TimeInForce: params.TimeInForce,
+		Identifier:  params.Identifier,
+	}
+	if params.Validate {
+		if err := checkOrder(ctx, trader, orderParams); err != nil {
+			return nil, nil, toolError(err)
+		}
+	}
+
+	if err := confirmOrder(ctx, req, orderParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	orderResult, err := trader.PlaceOrder(ctx, orderParams)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
 	return &res, &orderResult, nil
 }
 
@@ -239,19 +503,102 @@ func CancelOrder(ctx context.Context, req *mcp.CallToolRequest, params *CancelOr
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	canceled, err := client.CancelOrder(params.UUID)
-	if err != nil {
+	if err := requireOneOf(params.UUID, params.Identifier); err != nil {
 		return nil, nil, err
 	}
 
+	var canceled bool
+	var err error
+	if params.UUID != "" {
+		canceled, err = trader.CancelOrder(ctx, params.UUID)
+	} else {
+		canceled, err = trader.CancelOrderByIdentifier(ctx, params.Identifier)
+	}
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
 	return &res, &CancelOrderResult{Canceled: canceled}, nil
 }
 
+func CancelAllOrders(ctx context.Context, req *mcp.CallToolRequest, params *CancelAllOrdersRequest) (
+	*mcp.CallToolResult,
+	*CancelAllOrdersResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return nil, nil, missingDependency("Upbit trader")
+	}
+
+	match, err := openOrderFilter(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orders, err := listOpenOrders(ctx, trader, params.Market)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	orders = slices.DeleteFunc(orders, func(o upbit.Order) bool { return !match(o) })
+
+	result := CancelAllOrdersResult{
+		Matched: len(orders),
+		Orders:  cancelOrders(ctx, trader, orders),
+	}
+	for _, r := range result.Orders {
+		if r.Canceled {
+			result.Canceled++
+		} else {
+			result.Failed++
+		}
+	}
+	return &res, &result, nil
+}
+
+func GetOrder(ctx context.Context, req *mcp.CallToolRequest, params *GetOrderRequest) (
+	*mcp.CallToolResult,
+	*GetOrderResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return nil, nil, missingDependency("Upbit trader")
+	}
+
+	if err := requireOneOf(params.UUID, params.Identifier); err != nil {
+		return nil, nil, err
+	}
+
+	var order upbit.Order
+	var err error
+	if params.UUID != "" {
+		order, err = trader.GetOrder(ctx, params.UUID)
+	} else {
+		order, err = trader.GetOrderByIdentifier(ctx, params.Identifier)
+	}
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	return &res, &GetOrderResult{
+		Order:         order,
+		AvgFillPrice:  order.AvgFillPrice(),
+		ExecutedFunds: order.ExecutedFunds(),
+		FilledPercent: order.FilledPercent(),
+		TotalFee:      order.PaidFee,
+	}, nil
+}
+
 func GetAvailableOrderInfo(ctx context.Context, req *mcp.CallToolRequest, params *GetAvailableOrderInfoRequest) (
 	*mcp.CallToolResult,
 	*upbit.Chance,
@@ -259,14 +606,14 @@ func GetAvailableOrderInfo(ctx context.Context, req *mcp.CallToolRequest, params
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	chance, err := client.GetChance(params.Market)
+	chance, err := trader.GetChance(ctx, params.Market)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &res, &chance, nil
@@ -280,19 +627,19 @@ func GetClosedOrderHistory(ctx context.Context, req *mcp.CallToolRequest, params
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	orderHistory, err := client.GetOrderHistory(upbit.RequestParams{
+	orderHistory, err := trader.GetOrderHistory(ctx, upbit.RequestParams{
 		Market:  params.Market,
 		State:   params.State,
 		OrderBy: params.OrderBy,
 		Limit:   params.Limit,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &res, &GetClosedOrderHistoryResult{Orders: orderHistory}, nil
@@ -305,19 +652,19 @@ func GetOpenOrders(ctx context.Context, req *mcp.CallToolRequest, params *GetOpe
 ) {
 	var res mcp.CallToolResult
 
-	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit trader")
 	}
 
-	orderHistory, err := client.GetOpenOrders(upbit.RequestParams{
+	orderHistory, err := trader.GetOpenOrders(ctx, upbit.RequestParams{
 		Market:  params.Market,
 		Page:    params.Page,
 		Limit:   params.Limit,
 		OrderBy: params.OrderBy,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &res, &GetOpenOrderHistoryResult{Orders: orderHistory}, nil
@@ -332,12 +679,12 @@ func GetMarketSummary(ctx context.Context, req *mcp.CallToolRequest, params *Get
 
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	allAvailableMarkets, err := client.GetMarkets()
+	allAvailableMarkets, err := client.GetMarkets(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	marketCodes := make(map[string]bool)
@@ -353,12 +700,12 @@ func GetMarketSummary(ctx context.Context, req *mcp.CallToolRequest, params *Get
 	}
 
 	if len(markets) == 0 {
-		return nil, nil, fmt.Errorf("no valid markets provided")
+		return nil, nil, validationError("no valid markets provided")
 	}
 
-	ticker, err := client.GetTicker(strings.Join(markets, ","))
+	ticker, err := client.GetTicker(ctx, strings.Join(markets, ","))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &res, &GetMarketSummaryResult{Ticker: ticker}, nil
@@ -371,14 +718,14 @@ func GetMarketTrends(ctx context.Context, req *mcp.CallToolRequest, params any)
 ) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
 	var res mcp.CallToolResult
 
-	marketTrends, err := client.GetMarketTrends(10)
+	marketTrends, err := client.GetMarketTrends(ctx, 10)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &res, marketTrends, nil
@@ -391,16 +738,16 @@ func GetDayCandles(ctx context.Context, req *mcp.CallToolRequest, params *GetCan
 ) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetDayCandles(upbit.RequestParams{
+	candles, err := client.GetDayCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		To:     params.To,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &mcp.CallToolResult{}, &GetCandlesResult{Candles: candles}, nil
@@ -413,16 +760,16 @@ func GetWeekCandles(ctx context.Context, req *mcp.CallToolRequest, params *GetCa
 ) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetWeekCandles(upbit.RequestParams{
+	candles, err := client.GetWeekCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		To:     params.To,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &mcp.CallToolResult{}, &GetCandlesResult{Candles: candles}, nil
@@ -435,16 +782,16 @@ func GetMonthCandles(ctx context.Context, req *mcp.CallToolRequest, params *GetC
 ) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetMonthCandles(upbit.RequestParams{
+	candles, err := client.GetMonthCandles(ctx, upbit.RequestParams{
 		Market: params.Market,
 		To:     params.To,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &mcp.CallToolResult{}, &GetCandlesResult{Candles: candles}, nil
@@ -457,17 +804,177 @@ func GetMinuteCandles(ctx context.Context, req *mcp.CallToolRequest, params *Get
 ) {
 	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
 	if !ok {
-		return nil, nil, fmt.Errorf("Upbit client not found in context")
+		return nil, nil, missingDependency("Upbit client")
 	}
 
-	candles, err := client.GetMinuteCandles(params.Unit, upbit.RequestParams{
+	candles, err := client.GetMinuteCandles(ctx, params.Unit, upbit.RequestParams{
 		Market: params.Market,
 		To:     params.To,
 		Count:  params.Count,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, toolError(err)
 	}
 
 	return &mcp.CallToolResult{}, &GetCandlesResult{Candles: candles}, nil
 }
+
+// normalizeLimitPrice rounding이 지정되면 지정가 주문 가격을 호가 단위에 맞춘다
+func normalizeLimitPrice(market string, price upbit.Decimal, rounding string) (upbit.Decimal, error) {
+	if rounding == "" {
+		return price, nil
+	}
+
+	mode, err := upbit.ParseRoundingMode(rounding)
+	if err != nil {
+		return "", validationError("%v", err)
+	}
+	normalized, err := upbit.NormalizePrice(market, price, mode)
+	if err != nil {
+		return "", validationError("%v", err)
+	}
+	return normalized, nil
+}
+
+// checkOrder 주문 가능 정보를 조회해 주문을 보내기 전에 검증한다
+func checkOrder(ctx context.Context, trader upbit.Trader, params upbit.RequestParams) error {
+	chance, err := trader.GetChance(ctx, params.Market)
+	if err != nil {
+		return err
+	}
+
+	// 시장가/최유리 매도는 주문 금액이 정해져 있지 않으므로 현재가로 추정한다
+	var refPrice upbit.Decimal
+	if params.OrdType != upbit.OrdTypeLimit && !upbit.IsAmountOrder(params.OrdType, params.Side) {
+		client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+		if !ok {
+			return missingDependency("Upbit client")
+		}
+		tickers, err := client.GetTicker(ctx, params.Market)
+		if err != nil {
+			return err
+		}
+		if len(tickers) > 0 {
+			refPrice = tickers[0].TradePrice
+		}
+	}
+
+	return chance.CheckOrder(params, refPrice)
+}
+
+// requireOneOf 주문을 uuid와 identifier 중 정확히 하나로 지정했는지 확인
+func requireOneOf(uuid, identifier string) error {
+	if (uuid == "") == (identifier == "") {
+		return validationError("exactly one of uuid or identifier is required")
+	}
+	return nil
+}
+
+// openOrderFilter 일괄 취소 조건을 확인하는 함수
+func openOrderFilter(params *CancelAllOrdersRequest) (func(upbit.Order) bool, error) {
+	if params.Side != "" && params.Side != "bid" && params.Side != "ask" {
+		return nil, validationError("invalid side %q, allowed: bid, ask", params.Side)
+	}
+	var olderThan time.Duration
+	if params.OlderThan != "" {
+		d, err := time.ParseDuration(params.OlderThan)
+		if err != nil || d < 0 {
+			return nil, validationError("invalid older_than %q, use a duration like 30m or 2h", params.OlderThan)
+		}
+		olderThan = d
+	}
+	if params.MinPrice != "" && params.MaxPrice != "" && params.MinPrice.GreaterThan(params.MaxPrice) {
+		return nil, validationError("min_price %s is greater than max_price %s", params.MinPrice, params.MaxPrice)
+	}
+
+	now := time.Now()
+	return func(o upbit.Order) bool {
+		if params.Side != "" && o.Side != params.Side {
+			return false
+		}
+		if params.MinPrice != "" && o.Price.LessThan(params.MinPrice) {
+			return false
+		}
+		if params.MaxPrice != "" && o.Price.GreaterThan(params.MaxPrice) {
+			return false
+		}
+		if olderThan > 0 {
+			createdAt, err := time.Parse(time.RFC3339, o.CreatedAt)
+			if err != nil || now.Sub(createdAt) < olderThan {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// listOpenOrders 모든 페이지의 대기 주문
+func listOpenOrders(ctx context.Context, trader upbit.Trader, market string) ([]upbit.Order, error) {
+	const pageSize = 100
+
+	var orders []upbit.Order
+	for page := 1; ; page++ {
+		res, err := trader.GetOpenOrders(ctx, upbit.RequestParams{Market: market, Page: page, Limit: pageSize})
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, res...)
+		if len(res) < pageSize {
+			return orders, nil
+		}
+	}
+}
+
+// cancelWorkers 동시에 보내는 취소 요청 수. 요청 속도는 클라이언트의 RateLimiter가 조절한다
+const cancelWorkers = 4
+
+// cancelOrders 주문을 동시에 취소하고 주문 순서대로 결과를 반환한다
+func cancelOrders(ctx context.Context, trader upbit.Trader, orders []upbit.Order) []CancelOrderReport {
+	reports := make([]CancelOrderReport, len(orders))
+	sem := make(chan struct{}, cancelWorkers)
+	var wg sync.WaitGroup
+	for i, o := range orders {
+		reports[i] = CancelOrderReport{
+			Uuid:       o.Uuid,
+			Identifier: o.Identifier,
+			Market:     o.Market,
+			Side:       o.Side,
+			Price:      o.Price,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			canceled, err := trader.CancelOrder(ctx, o.Uuid)
+			reports[i].Canceled = canceled
+			if err != nil {
+				reports[i].Error = errorPayload(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return reports
+}
+
+// orderOptions 주문 조건(time_in_force)을 확인하고 자전거래 방지 모드를 반환한다. 지정하지 않으면 cancel_maker
+func orderOptions(timeInForce, smpType string, requireTimeInForce bool) (string, error) {
+	switch timeInForce {
+	case upbit.TimeInForceIOC, upbit.TimeInForceFOK:
+	case "":
+		if requireTimeInForce {
+			return "", validationError("time_in_force is required, allowed: ioc, fok")
+		}
+	default:
+		return "", validationError("invalid time_in_force %q, allowed: ioc, fok", timeInForce)
+	}
+
+	switch smpType {
+	case "":
+		return upbit.SmpCancelMaker, nil
+	case upbit.SmpCancelMaker, upbit.SmpCancelTaker, upbit.SmpReduce:
+		return smpType, nil
+	}
+	return "", validationError("invalid smp_type %q, allowed: cancel_maker, cancel_taker, reduce", smpType)
+}
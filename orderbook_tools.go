@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bookPlaces 호가창으로 계산한 수량과 가격의 소수점 자릿수
+const bookPlaces = 8
+
+// 호가창 분석 기본값
+const (
+	defaultImbalanceLevels = 5
+	defaultDepthPercent    = 1.0
+)
+
+type GetOrderBookRequest struct {
+	Markets         []string      `json:"markets" jsonschema:"Trading pair codes representing the markets (e.g. KRW-BTC, KRW-ETH ...)."`
+	Level           upbit.Decimal `json:"level,omitempty" jsonschema:"Optional. Group the order book by this price unit (e.g. 10000 groups KRW-BTC prices into 10,000 KRW buckets). Only supported for KRW markets. If omitted, the order book is not grouped."`
+	ImbalanceLevels int           `json:"imbalance_levels,omitempty" jsonschema:"Optional. Number of best levels on each side used for the bid/ask imbalance. Default 5."`
+	DepthPercent    float64       `json:"depth_percent,omitempty" jsonschema:"Optional. Measure the cumulative depth within this percentage above and below the mid price. Default 1."`
+}
+
+// OrderBookAnalytics 호가창에서 계산한 지표
+type OrderBookAnalytics struct {
+	BestAsk         upbit.Decimal `json:"best_ask"`
+	BestBid         upbit.Decimal `json:"best_bid"`
+	MidPrice        upbit.Decimal `json:"mid_price" jsonschema:"Average of the best ask and the best bid."`
+	Spread          upbit.Decimal `json:"spread" jsonschema:"Best ask minus best bid."`
+	SpreadBps       float64       `json:"spread_bps" jsonschema:"Spread relative to the mid price in basis points."`
+	ImbalanceLevels int           `json:"imbalance_levels" jsonschema:"Number of best levels on each side used for the imbalance."`
+	BidVolume       upbit.Decimal `json:"bid_volume" jsonschema:"Bid volume of the best imbalance_levels levels."`
+	AskVolume       upbit.Decimal `json:"ask_volume" jsonschema:"Ask volume of the best imbalance_levels levels."`
+	Imbalance       float64       `json:"imbalance" jsonschema:"(bid_volume - ask_volume) / (bid_volume + ask_volume), from -1 (only asks) to 1 (only bids)."`
+	DepthPercent    float64       `json:"depth_percent" jsonschema:"Percentage around the mid price used for the depth."`
+	BidDepthVolume  upbit.Decimal `json:"bid_depth_volume" jsonschema:"Bid volume priced at or above mid_price * (1 - depth_percent/100)."`
+	BidDepthAmount  upbit.Decimal `json:"bid_depth_amount" jsonschema:"Bid amount in the quote currency within the same range."`
+	AskDepthVolume  upbit.Decimal `json:"ask_depth_volume" jsonschema:"Ask volume priced at or below mid_price * (1 + depth_percent/100)."`
+	AskDepthAmount  upbit.Decimal `json:"ask_depth_amount" jsonschema:"Ask amount in the quote currency within the same range."`
+	// 업비트는 일부 호가만 보여주므로 범위가 보이는 호가 밖까지 이어지면 누적 잔량이 실제보다 작다
+	BidDepthTruncated bool `json:"bid_depth_truncated" jsonschema:"True when the range extends below the lowest visible bid, so the bid depth only covers the visible levels."`
+	AskDepthTruncated bool `json:"ask_depth_truncated" jsonschema:"True when the range extends above the highest visible ask, so the ask depth only covers the visible levels."`
+}
+
+// OrderBookResult 호가창과 지표
+type OrderBookResult struct {
+	upbit.OrderBook
+	Analytics OrderBookAnalytics `json:"analytics"`
+}
+
+type GetOrderBookResult struct {
+	OrderBooks []OrderBookResult `json:"orderbooks"`
+}
+
+type EstimateMarketOrderRequest struct {
+	Market string        `json:"market" jsonschema:"Trading pair code representing the market (e.g. KRW-BTC)."`
+	Side   string        `json:"side" jsonschema:"Allowed: 'bid' (buy, walks the asks from the lowest price), 'ask' (sell, walks the bids from the highest price)."`
+	Amount upbit.Decimal `json:"amount,omitempty" jsonschema:"Amount in the quote currency to spend (bid) or receive (ask), fees excluded. Set either amount or volume."`
+	Volume upbit.Decimal `json:"volume,omitempty" jsonschema:"Volume to buy or sell. Set either amount or volume."`
+}
+
+// EstimateMarketOrderResult 현재 호가창에서 시장가 주문이 체결될 모습
+type EstimateMarketOrderResult struct {
+	Market         string        `json:"market"`
+	Side           string        `json:"side"`
+	MidPrice       upbit.Decimal `json:"mid_price" jsonschema:"Average of the best ask and the best bid."`
+	BestPrice      upbit.Decimal `json:"best_price" jsonschema:"Best price on the side the order trades with (lowest ask for bid, highest bid for ask)."`
+	AvgPrice       upbit.Decimal `json:"avg_price" jsonschema:"Expected average fill price. 0 if nothing can be filled."`
+	WorstPrice     upbit.Decimal `json:"worst_price" jsonschema:"Price of the last order book level the order reaches."`
+	LevelsConsumed int           `json:"levels_consumed" jsonschema:"Number of order book levels the order trades with."`
+	FilledVolume   upbit.Decimal `json:"filled_volume"`
+	FilledAmount   upbit.Decimal `json:"filled_amount" jsonschema:"Filled amount in the quote currency, fees excluded."`
+	SlippageBps    float64       `json:"slippage_bps" jsonschema:"Average fill price vs. mid price in basis points. Positive means worse than mid (paying more when buying, receiving less when selling)."`
+	ImpactBps      float64       `json:"impact_bps" jsonschema:"Worst price vs. mid price in basis points, the price move needed to fill the order."`
+	FeeRate        upbit.Decimal `json:"fee_rate" jsonschema:"Taker fee rate of the market for the side."`
+	Fee            upbit.Decimal `json:"fee" jsonschema:"Expected fee in the quote currency."`
+	NetAmount      upbit.Decimal `json:"net_amount" jsonschema:"Total paid including fees (bid) or received after fees (ask), in the quote currency."`
+	Absorbed       bool          `json:"absorbed" jsonschema:"Whether the visible order book depth can fill the whole order."`
+	Unfilled       upbit.Decimal `json:"unfilled,omitempty" jsonschema:"Amount or volume (same unit as requested) left over when the visible depth is not enough."`
+	BookDepth      upbit.Decimal `json:"book_depth" jsonschema:"Total amount (if amount was requested) or volume (if volume was requested) available on the traded side of the visible order book."`
+	Placeable      bool          `json:"placeable" jsonschema:"Whether Upbit accepts this request as a market order. Market buys take an amount and market sells take a volume; other shapes can only be estimated."`
+	Note           string        `json:"note,omitempty"`
+}
+
+func GetOrderBook(ctx context.Context, req *mcp.CallToolRequest, params *GetOrderBookRequest) (
+	*mcp.CallToolResult,
+	*GetOrderBookResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	if !ok {
+		return nil, nil, missingDependency("Upbit client")
+	}
+
+	if len(params.Markets) == 0 {
+		return nil, nil, validationError("at least one market is required")
+	}
+	if params.Level.Sign() < 0 {
+		return nil, nil, validationError("level must not be negative")
+	}
+	n := params.ImbalanceLevels
+	if n < 0 {
+		return nil, nil, validationError("imbalance_levels must not be negative")
+	}
+	if n == 0 {
+		n = defaultImbalanceLevels
+	}
+	pct := params.DepthPercent
+	if pct < 0 || pct > 100 {
+		return nil, nil, validationError("depth_percent must be between 0 and 100")
+	}
+	if pct == 0 {
+		pct = defaultDepthPercent
+	}
+
+	books, err := client.GetOrderBooksByLevel(ctx, strings.Join(params.Markets, ","), params.Level)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	result := &GetOrderBookResult{OrderBooks: []OrderBookResult{}}
+	for _, book := range books {
+		result.OrderBooks = append(result.OrderBooks, OrderBookResult{
+			OrderBook: book,
+			Analytics: analyzeOrderBook(book, n, pct),
+		})
+	}
+
+	return &res, result, nil
+}
+
+func EstimateMarketOrder(ctx context.Context, req *mcp.CallToolRequest, params *EstimateMarketOrderRequest) (
+	*mcp.CallToolResult,
+	*EstimateMarketOrderResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	client, ok := ctx.Value(upbitClientKey{}).(*upbit.Client)
+	if !ok {
+		return nil, nil, missingDependency("Upbit client")
+	}
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return nil, nil, missingDependency("Upbit trader")
+	}
+
+	if params.Side != "bid" && params.Side != "ask" {
+		return nil, nil, validationError("side must be bid or ask")
+	}
+	if (params.Amount == "") == (params.Volume == "") {
+		return nil, nil, validationError("set either amount or volume")
+	}
+	for name, v := range map[string]upbit.Decimal{"amount": params.Amount, "volume": params.Volume} {
+		if _, err := upbit.ParseDecimal(string(v)); v != "" && err != nil {
+			return nil, nil, validationError("%s %q is not a valid number", name, v)
+		}
+	}
+	if params.Amount.Sign() < 0 || params.Volume.Sign() < 0 || (params.Amount.IsZero() && params.Volume.IsZero()) {
+		return nil, nil, validationError("amount or volume must be positive")
+	}
+
+	books, err := client.GetOrderBooks(ctx, params.Market)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	if len(books) == 0 {
+		return nil, nil, validationError("orderbook for %s not found", params.Market)
+	}
+	chance, err := trader.GetChance(ctx, params.Market)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	asks, bids := bookSide(books[0], "ask"), bookSide(books[0], "bid")
+	levels := asks
+	if params.Side == "ask" {
+		levels = bids
+	}
+	if len(levels) == 0 {
+		return nil, nil, validationError("the orderbook of %s has no %s orders", params.Market, oppositeSide(params.Side))
+	}
+
+	result := &EstimateMarketOrderResult{
+		Market:    params.Market,
+		Side:      params.Side,
+		MidPrice:  midPrice(asks, bids),
+		BestPrice: levels[0].Price,
+		AvgPrice:  "0",
+		FeeRate:   chance.BidFee,
+		BookDepth: "0",
+	}
+	if params.Side == "ask" {
+		result.FeeRate = chance.AskFee
+	}
+	// 업비트 시장가 매수는 금액으로, 시장가 매도는 수량으로만 주문할 수 있다
+	result.Placeable = (params.Side == "bid") == (params.Amount != "")
+	if !result.Placeable {
+		if params.Side == "bid" {
+			result.Note = "market buys on Upbit take an amount, not a volume; place a limit order at worst_price or estimate by amount"
+		} else {
+			result.Note = "market sells on Upbit take a volume, not an amount; place a limit order at worst_price or estimate by volume"
+		}
+	}
+
+	// 금액 기준이면 남은 금액을, 수량 기준이면 남은 수량을 줄여 가며 좋은 가격부터 체결한다
+	remaining := params.Volume
+	if params.Amount != "" {
+		remaining = params.Amount
+	}
+	var volume, funds upbit.Decimal = "0", "0"
+	for _, lv := range levels {
+		levelFunds := lv.Price.Mul(lv.Size)
+		if params.Amount != "" {
+			result.BookDepth = result.BookDepth.Add(levelFunds)
+		} else {
+			result.BookDepth = result.BookDepth.Add(lv.Size)
+		}
+		if remaining.Sign() <= 0 {
+			continue
+		}
+
+		take := lv.Size
+		if params.Amount != "" {
+			if remaining.LessThan(levelFunds) {
+				take = remaining.Div(lv.Price, bookPlaces)
+			}
+			remaining = remaining.Sub(upbit.MinDecimal(remaining, levelFunds))
+		} else {
+			take = upbit.MinDecimal(remaining, lv.Size)
+			remaining = remaining.Sub(take)
+		}
+		if take.Sign() <= 0 {
+			continue
+		}
+		volume = volume.Add(take)
+		funds = funds.Add(take.Mul(lv.Price))
+		result.WorstPrice = lv.Price
+		result.LevelsConsumed++
+	}
+
+	result.FilledVolume = volume
+	result.FilledAmount = funds
+	result.Fee = funds.Mul(result.FeeRate)
+	result.NetAmount = funds.Add(result.Fee)
+	if params.Side == "ask" {
+		result.NetAmount = funds.Sub(result.Fee)
+	}
+	result.Absorbed = remaining.Sign() <= 0
+	if !result.Absorbed {
+		result.Unfilled = remaining
+	}
+	if volume.Sign() > 0 {
+		result.AvgPrice = funds.Div(volume, bookPlaces)
+		result.SlippageBps = priceBps(params.Side, result.AvgPrice, result.MidPrice)
+		result.ImpactBps = priceBps(params.Side, result.WorstPrice, result.MidPrice)
+	}
+
+	return &res, result, nil
+}
+
+// bookLevel 호가 한 단계
+type bookLevel struct {
+	Price upbit.Decimal
+	Size  upbit.Decimal
+}
+
+// bookSide 호가창의 side쪽 호가(ask: 매도 호가, bid: 매수 호가)를 최우선 호가부터 나열.
+// 매도 호가는 낮은 가격부터, 매수 호가는 높은 가격부터 온다
+func bookSide(book upbit.OrderBook, side string) []bookLevel {
+	levels := make([]bookLevel, 0, len(book.OrderbookUnits))
+	for _, u := range book.OrderbookUnits {
+		lv := bookLevel{Price: u.AskPrice, Size: u.AskSize}
+		if side == "bid" {
+			lv = bookLevel{Price: u.BidPrice, Size: u.BidSize}
+		}
+		if lv.Price.Sign() > 0 && lv.Size.Sign() > 0 {
+			levels = append(levels, lv)
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		if side == "bid" {
+			return levels[i].Price.GreaterThan(levels[j].Price)
+		}
+		return levels[i].Price.LessThan(levels[j].Price)
+	})
+	return levels
+}
+
+// analyzeOrderBook 스프레드, 상위 n단계의 매수/매도 잔량 불균형, 중간 가격 ±pct% 안의 누적 잔량을 계산한다
+func analyzeOrderBook(book upbit.OrderBook, n int, pct float64) OrderBookAnalytics {
+	asks, bids := bookSide(book, "ask"), bookSide(book, "bid")
+	a := OrderBookAnalytics{
+		MidPrice:        midPrice(asks, bids),
+		ImbalanceLevels: n,
+		DepthPercent:    pct,
+	}
+	if len(asks) > 0 {
+		a.BestAsk = asks[0].Price
+	}
+	if len(bids) > 0 {
+		a.BestBid = bids[0].Price
+	}
+	if len(asks) > 0 && len(bids) > 0 {
+		a.Spread = a.BestAsk.Sub(a.BestBid)
+		a.SpreadBps = a.Spread.Float64() / a.MidPrice.Float64() * 10000
+	}
+
+	a.AskVolume = sumSize(asks[:min(n, len(asks))])
+	a.BidVolume = sumSize(bids[:min(n, len(bids))])
+	if total := a.BidVolume.Add(a.AskVolume); total.Sign() > 0 {
+		a.Imbalance = a.BidVolume.Sub(a.AskVolume).Float64() / total.Float64()
+	}
+
+	ratio := upbit.DecimalFromFloat(pct / 100)
+	upper := a.MidPrice.Add(a.MidPrice.Mul(ratio))
+	lower := a.MidPrice.Sub(a.MidPrice.Mul(ratio))
+	a.AskDepthVolume, a.AskDepthAmount = "0", "0"
+	a.AskDepthTruncated = true
+	for _, lv := range asks {
+		if lv.Price.GreaterThan(upper) {
+			a.AskDepthTruncated = false
+			break
+		}
+		a.AskDepthVolume = a.AskDepthVolume.Add(lv.Size)
+		a.AskDepthAmount = a.AskDepthAmount.Add(lv.Price.Mul(lv.Size))
+	}
+	a.BidDepthVolume, a.BidDepthAmount = "0", "0"
+	a.BidDepthTruncated = true
+	for _, lv := range bids {
+		if lv.Price.LessThan(lower) {
+			a.BidDepthTruncated = false
+			break
+		}
+		a.BidDepthVolume = a.BidDepthVolume.Add(lv.Size)
+		a.BidDepthAmount = a.BidDepthAmount.Add(lv.Price.Mul(lv.Size))
+	}
+	// 마지막 호가가 정확히 범위 끝에 있으면 그 너머의 잔량은 범위 밖이다
+	if n := len(asks); n > 0 && asks[n-1].Price.Equal(upper) {
+		a.AskDepthTruncated = false
+	}
+	if n := len(bids); n > 0 && bids[n-1].Price.Equal(lower) {
+		a.BidDepthTruncated = false
+	}
+	return a
+}
+
+func sumSize(levels []bookLevel) upbit.Decimal {
+	var sum upbit.Decimal = "0"
+	for _, lv := range levels {
+		sum = sum.Add(lv.Size)
+	}
+	return sum
+}
+
+// midPrice 유리한 가격 순으로 나열한 매도 호가와 매수 호가의 중간 가격. 한쪽 호가가 없으면 있는 쪽의 최우선 호가
+func midPrice(asks, bids []bookLevel) upbit.Decimal {
+	switch {
+	case len(asks) > 0 && len(bids) > 0:
+		return asks[0].Price.Add(bids[0].Price).Div("2", bookPlaces)
+	case len(asks) > 0:
+		return asks[0].Price
+	case len(bids) > 0:
+		return bids[0].Price
+	}
+	return "0"
+}
+
+// priceBps 기준 가격 대비 가격 차이(bp). 매수는 비싸게, 매도는 싸게 체결될수록 양수
+func priceBps(side string, price, ref upbit.Decimal) float64 {
+	if ref.Sign() <= 0 {
+		return 0
+	}
+	diff := price.Sub(ref)
+	if side == "ask" {
+		diff = diff.Neg()
+	}
+	return diff.Float64() / ref.Float64() * 10000
+}
+
+func oppositeSide(side string) string {
+	if side == "bid" {
+		return "ask"
+	}
+	return "bid"
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+// testBook 매도 호가 1000(10), 1010(20), 매수 호가 990(10), 980(20). 중간 가격은 995
+var testBook = upbit.OrderBook{
+	Market: "KRW-XRP",
+	OrderbookUnits: []upbit.OrderBookUnit{
+		{AskPrice: "1000", AskSize: "10", BidPrice: "990", BidSize: "10"},
+		{AskPrice: "1010", AskSize: "20", BidPrice: "980", BidSize: "20"},
+	},
+}
+
+// asksOnly 매수 호가가 없는 호가창
+var asksOnly = upbit.OrderBook{
+	Market: "KRW-XRP",
+	OrderbookUnits: []upbit.OrderBookUnit{
+		{AskPrice: "1000", AskSize: "10", BidPrice: "0", BidSize: "0"},
+		{AskPrice: "1010", AskSize: "20", BidPrice: "0", BidSize: "0"},
+	},
+}
+
+func TestEstimateMarketOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		book    upbit.OrderBook
+		params  EstimateMarketOrderRequest
+		want    EstimateMarketOrderResult
+		wantErr bool
+	}{
+		{
+			name:   "buy amount within the best level",
+			book:   testBook,
+			params: EstimateMarketOrderRequest{Side: "bid", Amount: "5000"},
+			want: EstimateMarketOrderResult{MidPrice: "995", AvgPrice: "1000", WorstPrice: "1000", LevelsConsumed: 1,
+				FilledVolume: "5", FilledAmount: "5000", Fee: "2.5", NetAmount: "5002.5", Absorbed: true, BookDepth: "30200", Placeable: true},
+		},
+		{
+			name:   "buy volume across levels",
+			book:   testBook,
+			params: EstimateMarketOrderRequest{Side: "bid", Volume: "15"},
+			want: EstimateMarketOrderResult{MidPrice: "995", AvgPrice: "1003.33333333", WorstPrice: "1010", LevelsConsumed: 2,
+				FilledVolume: "15", FilledAmount: "15050", Fee: "7.525", NetAmount: "15057.525", Absorbed: true, BookDepth: "30"},
+		},
+		{
+			name:   "sell volume",
+			book:   testBook,
+			params: EstimateMarketOrderRequest{Side: "ask", Volume: "15"},
+			want: EstimateMarketOrderResult{MidPrice: "995", AvgPrice: "986.66666666", WorstPrice: "980", LevelsConsumed: 2,
+				FilledVolume: "15", FilledAmount: "14800", Fee: "7.4", NetAmount: "14792.6", Absorbed: true, BookDepth: "30", Placeable: true},
+		},
+		{
+			name:   "sell amount",
+			book:   testBook,
+			params: EstimateMarketOrderRequest{Side: "ask", Amount: "9900"},
+			want: EstimateMarketOrderResult{MidPrice: "995", AvgPrice: "990", WorstPrice: "990", LevelsConsumed: 1,
+				FilledVolume: "10", FilledAmount: "9900", Fee: "4.95", NetAmount: "9895.05", Absorbed: true, BookDepth: "29500"},
+		},
+		{
+			name:   "book cannot absorb the volume",
+			book:   testBook,
+			params: EstimateMarketOrderRequest{Side: "bid", Volume: "40"},
+			want: EstimateMarketOrderResult{MidPrice: "995", AvgPrice: "1006.66666666", WorstPrice: "1010", LevelsConsumed: 2,
+				FilledVolume: "30", FilledAmount: "30200", Fee: "15.1", NetAmount: "30215.1", Unfilled: "10", BookDepth: "30"},
+		},
+		{
+			name:   "book cannot absorb the amount",
+			book:   testBook,
+			params: EstimateMarketOrderRequest{Side: "ask", Amount: "40000"},
+			want: EstimateMarketOrderResult{MidPrice: "995", AvgPrice: "983.33333333", WorstPrice: "980", LevelsConsumed: 2,
+				FilledVolume: "30", FilledAmount: "29500", Fee: "14.75", NetAmount: "29485.25", Unfilled: "10500", BookDepth: "29500"},
+		},
+		{
+			name:   "buy from a book without bids",
+			book:   asksOnly,
+			params: EstimateMarketOrderRequest{Side: "bid", Amount: "5000"},
+			want: EstimateMarketOrderResult{MidPrice: "1000", AvgPrice: "1000", WorstPrice: "1000", LevelsConsumed: 1,
+				FilledVolume: "5", FilledAmount: "5000", Fee: "2.5", NetAmount: "5002.5", Absorbed: true, BookDepth: "30200", Placeable: true},
+		},
+		{
+			name:    "sell into a book without bids",
+			book:    asksOnly,
+			params:  EstimateMarketOrderRequest{Side: "ask", Volume: "1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := upbittest.New(t, "KRW-XRP", "1000", nil)
+			ex.SetOrderBook(tt.book)
+			client := ex.Client()
+			ctx := context.WithValue(context.Background(), upbitClientKey{}, client)
+			ctx = context.WithValue(ctx, upbitTraderKey{}, upbit.Trader(client))
+
+			params := tt.params
+			params.Market = "KRW-XRP"
+			_, got, err := EstimateMarketOrder(ctx, nil, &params)
+			if tt.wantErr {
+				var te *toolErr
+				if !errors.As(err, &te) || te.payload.Name != upbit.ErrValidation {
+					t.Fatalf("EstimateMarketOrder = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := tt.want
+			if !got.MidPrice.Equal(w.MidPrice) || !got.BestPrice.Equal(bestOf(tt.book, params.Side)) || !got.AvgPrice.Equal(w.AvgPrice) || !got.WorstPrice.Equal(w.WorstPrice) {
+				t.Errorf("prices: mid %s, best %s, avg %s, worst %s", got.MidPrice, got.BestPrice, got.AvgPrice, got.WorstPrice)
+			}
+			if got.LevelsConsumed != w.LevelsConsumed || !got.FilledVolume.Equal(w.FilledVolume) || !got.FilledAmount.Equal(w.FilledAmount) {
+				t.Errorf("filled: %d levels, volume %s, amount %s", got.LevelsConsumed, got.FilledVolume, got.FilledAmount)
+			}
+			if !got.FeeRate.Equal(upbittest.DefaultFee) || !got.Fee.Equal(w.Fee) || !got.NetAmount.Equal(w.NetAmount) {
+				t.Errorf("fee rate %s, fee %s, net amount %s", got.FeeRate, got.Fee, got.NetAmount)
+			}
+			if got.Absorbed != w.Absorbed || !got.Unfilled.Equal(w.Unfilled) || !got.BookDepth.Equal(w.BookDepth) {
+				t.Errorf("absorbed %v, unfilled %s, book depth %s", got.Absorbed, got.Unfilled, got.BookDepth)
+			}
+			if got.Placeable != w.Placeable || (got.Note == "") != w.Placeable {
+				t.Errorf("placeable %v, note %q", got.Placeable, got.Note)
+			}
+		})
+	}
+}
+
+// bestOf side 주문이 처음 체결될 호가
+func bestOf(book upbit.OrderBook, side string) upbit.Decimal {
+	if side == "bid" {
+		return bookSide(book, "ask")[0].Price
+	}
+	return bookSide(book, "bid")[0].Price
+}
+
+func TestAnalyzeOrderBook(t *testing.T) {
+	// 매도 호가 1010(10), 1020(20), 매수 호가 990(30), 980(20). 중간 가격은 1000
+	book := upbit.OrderBook{
+		Market: "KRW-XRP",
+		OrderbookUnits: []upbit.OrderBookUnit{
+			{AskPrice: "1010", AskSize: "10", BidPrice: "990", BidSize: "30"},
+			{AskPrice: "1020", AskSize: "20", BidPrice: "980", BidSize: "20"},
+		},
+	}
+	oneSided := upbit.OrderBook{
+		Market: "KRW-XRP",
+		OrderbookUnits: []upbit.OrderBookUnit{
+			{AskPrice: "1010", AskSize: "10", BidPrice: "0", BidSize: "0"},
+			{AskPrice: "1020", AskSize: "20", BidPrice: "0", BidSize: "0"},
+		},
+	}
+
+	tests := []struct {
+		name                       string
+		book                       upbit.OrderBook
+		n                          int
+		pct                        float64
+		mid, spread                upbit.Decimal
+		imbalance                  float64
+		askDepth, bidDepth         upbit.Decimal
+		askTruncated, bidTruncated bool
+	}{
+		{name: "best level only", book: book, n: 1, pct: 0.5, mid: "1000", spread: "20", imbalance: 0.5, askDepth: "0", bidDepth: "0"},
+		{name: "range ends between levels", book: book, n: 5, pct: 1.5, mid: "1000", spread: "20", imbalance: 0.25, askDepth: "10", bidDepth: "30"},
+		// 마지막 호가가 정확히 범위 끝에 있으면 범위 안의 잔량을 모두 본 것이다
+		{name: "range ends at the last level", book: book, n: 5, pct: 2, mid: "1000", spread: "20", imbalance: 0.25, askDepth: "30", bidDepth: "50"},
+		{name: "range extends past the last level", book: book, n: 5, pct: 3, mid: "1000", spread: "20", imbalance: 0.25, askDepth: "30", bidDepth: "50", askTruncated: true, bidTruncated: true},
+		{name: "no bids", book: oneSided, n: 5, pct: 1, mid: "1010", spread: "", imbalance: -1, askDepth: "30", bidDepth: "0", askTruncated: true, bidTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := analyzeOrderBook(tt.book, tt.n, tt.pct)
+			if !a.MidPrice.Equal(tt.mid) || !a.Spread.Equal(tt.spread) || a.Imbalance != tt.imbalance {
+				t.Errorf("mid %s, spread %s, imbalance %v", a.MidPrice, a.Spread, a.Imbalance)
+			}
+			if !a.AskDepthVolume.Equal(tt.askDepth) || a.AskDepthTruncated != tt.askTruncated {
+				t.Errorf("ask depth %s, truncated %v", a.AskDepthVolume, a.AskDepthTruncated)
+			}
+			if !a.BidDepthVolume.Equal(tt.bidDepth) || a.BidDepthTruncated != tt.bidTruncated {
+				t.Errorf("bid depth %s, truncated %v", a.BidDepthVolume, a.BidDepthTruncated)
+			}
+		})
+	}
+}
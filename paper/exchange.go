@@ -0,0 +1,332 @@
+// Package paper 실제 시세와 호가를 사용하되 주문은 가상 원장에서만 체결하는 모의 거래 구현.
+//
+// 시장가 주문과 즉시 체결 가능한 지정가 주문은 업비트 호가창을 따라 내려가며 체결하고,
+// 체결되지 않은 지정가 주문은 이후 계좌나 주문을 조회할 때 호가가 지정가에 닿으면 지정가로 체결한다.
+// 수수료율과 최소/최대 주문 금액은 업비트 주문 가능 정보(orders/chance)를 그대로 사용한다.
+package paper
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/ledger"
+	"upbit-mcp-server/upbit"
+)
+
+// DefaultBalance 모의 계좌의 기본 KRW 잔고
+const DefaultBalance upbit.Decimal = "10000000"
+
+// Exchange 가상 원장으로 주문을 처리하는 upbit.Trader 구현.
+// 호가, 현재가, 주문 가능 정보는 잠금 밖에서 조회하고 원장 변경과 매칭만 잠금 안에서 순서대로 처리한다.
+type Exchange struct {
+	client *upbit.Client
+
+	// mu 원장과 주문 가능 정보 캐시를 보호한다. 시세 조회 중에는 잡지 않는다
+	mu      sync.Mutex
+	ledger  *ledger.Ledger
+	chances map[string]upbit.Chance
+}
+
+var _ upbit.Trader = (*Exchange)(nil)
+
+// NewExchange 시세 조회에 client를 사용하는 모의 거래소 생성. balances는 화폐별 초기 잔고
+func NewExchange(client *upbit.Client, balances map[string]upbit.Decimal) *Exchange {
+	e := &Exchange{
+		client:  client,
+		ledger:  ledger.New(),
+		chances: map[string]upbit.Chance{},
+	}
+	for currency, balance := range balances {
+		e.ledger.Wallet(currency).Balance = balance
+	}
+	return e
+}
+
+// GetAccounts 가상 계좌 조회
+func (e *Exchange) GetAccounts(ctx context.Context) ([]upbit.Account, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer e.mu.Unlock()
+	return e.ledger.Accounts(), nil
+}
+
+// GetChance 업비트 주문 가능 정보에 가상 계좌 잔고를 채워 반환
+func (e *Exchange) GetChance(ctx context.Context, market string) (upbit.Chance, error) {
+	chance, err := e.fees(ctx, market)
+	if err != nil {
+		return upbit.Chance{}, err
+	}
+	if _, err := e.lock(ctx); err != nil {
+		return upbit.Chance{}, err
+	}
+	defer e.mu.Unlock()
+
+	return e.withBalances(chance, market), nil
+}
+
+// PlaceOrder 주문을 검증하고 호가창에 맞춰 바로 체결한다
+func (e *Exchange) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	if err := validateParams(params); err != nil {
+		return upbit.Order{}, err
+	}
+	chance, err := e.fees(ctx, params.Market)
+	if err != nil {
+		return upbit.Order{}, err
+	}
+
+	var refPrice upbit.Decimal
+	if params.OrdType != upbit.OrdTypeLimit && !upbit.IsAmountOrder(params.OrdType, params.Side) {
+		tickers, err := e.client.GetTicker(ctx, params.Market)
+		if err != nil {
+			return upbit.Order{}, err
+		}
+		if len(tickers) > 0 {
+			refPrice = tickers[0].TradePrice
+		}
+	}
+
+	books, err := e.lock(ctx, params.Market)
+	if err != nil {
+		return upbit.Order{}, err
+	}
+	defer e.mu.Unlock()
+
+	if params.Identifier != "" && e.ledger.ByIdentifier(params.Identifier) != nil {
+		return upbit.Order{}, &upbit.APIError{StatusCode: 400, Name: upbit.ErrDuplicateIdentifier, Message: "이미 사용된 identifier입니다."}
+	}
+	chance = e.withBalances(chance, params.Market)
+	if err := chance.CheckOrder(params, refPrice); err != nil {
+		return upbit.Order{}, err
+	}
+	book, ok := books[params.Market]
+	if !ok {
+		return upbit.Order{}, fmt.Errorf("orderbook for %s not found", params.Market)
+	}
+
+	o := ledger.NewOrder(params, time.Now())
+	if err := e.ledger.Place(o, chance.BidFee); err != nil {
+		return upbit.Order{}, err
+	}
+
+	fee := chance.BidFee
+	if o.Side == "ask" {
+		fee = chance.AskFee
+	}
+	e.ledger.Match(o, ledger.BookLevels(book, params.Side), fee, false)
+	return o.Snapshot(), nil
+}
+
+// GetOrder 주문 조회
+func (e *Exchange) GetOrder(ctx context.Context, id string) (upbit.Order, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return upbit.Order{}, err
+	}
+	defer e.mu.Unlock()
+
+	o, ok := e.ledger.Order(id)
+	if !ok {
+		return upbit.Order{}, orderNotFound()
+	}
+	return o.Detail(), nil
+}
+
+// GetOrderByIdentifier identifier로 주문 조회
+func (e *Exchange) GetOrderByIdentifier(ctx context.Context, identifier string) (upbit.Order, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return upbit.Order{}, err
+	}
+	defer e.mu.Unlock()
+
+	o := e.ledger.ByIdentifier(identifier)
+	if o == nil {
+		return upbit.Order{}, orderNotFound()
+	}
+	return o.Detail(), nil
+}
+
+// GetOpenOrders 체결 대기 중인 주문 조회
+func (e *Exchange) GetOpenOrders(ctx context.Context, params upbit.RequestParams) ([]upbit.Order, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer e.mu.Unlock()
+
+	page, limit := max(params.Page, 1), params.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	orders := e.ledger.Filter(params.Market, params.OrderBy, func(o *ledger.Order) bool {
+		return o.State == upbit.OrderStateWait
+	})
+	start := min((page-1)*limit, len(orders))
+	end := min(start+limit, len(orders))
+	return orders[start:end], nil
+}
+
+// GetOrderHistory 완료되거나 취소된 주문 조회
+func (e *Exchange) GetOrderHistory(ctx context.Context, params upbit.RequestParams) ([]upbit.Order, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer e.mu.Unlock()
+
+	limit := params.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	orders := e.ledger.Filter(params.Market, params.OrderBy, func(o *ledger.Order) bool {
+		if params.State == "" {
+			return o.State == upbit.OrderStateDone || o.State == upbit.OrderStateCancel
+		}
+		return o.State == params.State
+	})
+	return orders[:min(limit, len(orders))], nil
+}
+
+// CancelOrder 대기 중인 주문을 취소하고 묶여있던 잔고를 돌려준다
+func (e *Exchange) CancelOrder(ctx context.Context, id string) (bool, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return false, err
+	}
+	defer e.mu.Unlock()
+
+	o, ok := e.ledger.Order(id)
+	if !ok {
+		return false, orderNotFound()
+	}
+	return e.cancel(o)
+}
+
+// CancelOrderByIdentifier identifier로 주문 취소
+func (e *Exchange) CancelOrderByIdentifier(ctx context.Context, identifier string) (bool, error) {
+	if _, err := e.lock(ctx); err != nil {
+		return false, err
+	}
+	defer e.mu.Unlock()
+
+	o := e.ledger.ByIdentifier(identifier)
+	if o == nil {
+		return false, orderNotFound()
+	}
+	return e.cancel(o)
+}
+
+func (e *Exchange) cancel(o *ledger.Order) (bool, error) {
+	if !e.ledger.Cancel(o) {
+		return false, &upbit.APIError{StatusCode: 400, Name: "order_not_wait", Message: "대기 중인 주문이 아닙니다."}
+	}
+	return true, nil
+}
+
+// lock 대기 중인 지정가 주문과 markets의 호가를 잠금 밖에서 조회한 뒤 잠금을 잡고 대기 주문을 다시 매칭한다.
+// 오류가 없으면 잠금을 잡은 채로 마켓별 호가를 반환하고, 호출한 쪽이 잠금을 푼다
+func (e *Exchange) lock(ctx context.Context, markets ...string) (map[string]upbit.OrderBook, error) {
+	e.mu.Lock()
+	for _, o := range e.ledger.Waiting("") {
+		if !slices.Contains(markets, o.Market) {
+			markets = append(markets, o.Market)
+		}
+	}
+	e.mu.Unlock()
+
+	books := map[string]upbit.OrderBook{}
+	fees := map[string]upbit.Chance{}
+	if len(markets) > 0 {
+		list, err := e.client.GetOrderBooks(ctx, strings.Join(markets, ","))
+		if err != nil {
+			return nil, err
+		}
+		for _, book := range list {
+			chance, err := e.fees(ctx, book.Market)
+			if err != nil {
+				return nil, err
+			}
+			books[book.Market] = book
+			fees[book.Market] = chance
+		}
+	}
+
+	e.mu.Lock()
+	for market, book := range books {
+		// 같은 호가를 여러 주문이 나눠 쓰도록 방향별 호가를 한 번만 만든다
+		levels := map[string][]ledger.Level{"bid": ledger.BookLevels(book, "bid"), "ask": ledger.BookLevels(book, "ask")}
+		for _, o := range e.ledger.Waiting(market) {
+			// 대기하던 주문은 메이커로 지정가에 체결된다
+			fee := fees[market].MakerBidFee
+			if o.Side == "ask" {
+				fee = fees[market].MakerAskFee
+			}
+			e.ledger.Match(o, levels[o.Side], fee, true)
+		}
+	}
+	return books, nil
+}
+
+// fees 마켓별 수수료와 주문 제한. 업비트에서 한 번 조회해 캐시하며 조회 중에는 잠금을 잡지 않는다
+func (e *Exchange) fees(ctx context.Context, market string) (upbit.Chance, error) {
+	e.mu.Lock()
+	chance, ok := e.chances[market]
+	e.mu.Unlock()
+	if ok {
+		return chance, nil
+	}
+
+	chance, err := e.client.GetChance(ctx, market)
+	if err != nil {
+		return upbit.Chance{}, err
+	}
+	e.mu.Lock()
+	e.chances[market] = chance
+	e.mu.Unlock()
+	return chance, nil
+}
+
+// withBalances chance의 잔고를 가상 계좌로 채운다. 잠금을 잡고 호출한다
+func (e *Exchange) withBalances(chance upbit.Chance, market string) upbit.Chance {
+	base, quote := ledger.SplitMarket(market)
+	chance.BidAccount = upbit.BidAccount(e.ledger.Wallet(quote).Account(quote))
+	chance.AskAccount = upbit.AskAccount(e.ledger.Wallet(base).Account(base))
+	return chance
+}
+
+// validateParams 주문 종류별 필수 파라미터 확인
+func validateParams(params upbit.RequestParams) error {
+	valid := false
+	switch {
+	case params.OrdType == upbit.OrdTypeLimit && (params.Side == "bid" || params.Side == "ask"):
+		valid = params.Price.Sign() > 0 && params.Volume.Sign() > 0
+	case (params.OrdType == upbit.OrdTypePrice || params.OrdType == upbit.OrdTypeBest) && params.Side == "bid":
+		valid = params.Price.Sign() > 0 && params.Volume.IsZero()
+	case (params.OrdType == upbit.OrdTypeMarket || params.OrdType == upbit.OrdTypeBest) && params.Side == "ask":
+		valid = params.Volume.Sign() > 0 && params.Price.IsZero()
+	}
+
+	// 최유리 주문은 time_in_force가 필요하고, 시장가 주문에는 지정할 수 없다
+	switch params.TimeInForce {
+	case "":
+		valid = valid && params.OrdType != upbit.OrdTypeBest
+	case upbit.TimeInForceIOC, upbit.TimeInForceFOK:
+		valid = valid && (params.OrdType == upbit.OrdTypeLimit || params.OrdType == upbit.OrdTypeBest)
+	default:
+		valid = false
+	}
+
+	if !valid {
+		return &upbit.APIError{
+			StatusCode: 400,
+			Name:       upbit.ErrValidation,
+			Message:    fmt.Sprintf("invalid order: side=%s ord_type=%s time_in_force=%s price=%s volume=%s", params.Side, params.OrdType, params.TimeInForce, params.Price, params.Volume),
+		}
+	}
+	return nil
+}
+
+func orderNotFound() error {
+	return &upbit.APIError{StatusCode: 404, Name: upbit.ErrOrderNotFound, Message: "주문을 찾지 못했습니다."}
+}
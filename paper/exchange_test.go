@@ -0,0 +1,111 @@
+package paper_test
+
+import (
+	"context"
+	"testing"
+	"upbit-mcp-server/paper"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+func book(bid, ask upbit.Decimal) upbit.OrderBook {
+	return upbit.OrderBook{
+		Market: "KRW-BTC",
+		OrderbookUnits: []upbit.OrderBookUnit{
+			{AskPrice: ask, AskSize: "0.01", BidPrice: bid, BidSize: "0.01"},
+		},
+	}
+}
+
+func TestPaperOrderFlow(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", nil)
+	ex.SetOrderBook(book("99999000", "100000000"))
+
+	p := paper.NewExchange(ex.Client(), map[string]upbit.Decimal{"KRW": "1000000"})
+	ctx := context.Background()
+
+	// 호가에 닿지 않는 지정가 매수는 대기한다
+	placed, err := p.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "99000000", Volume: "0.001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if placed.State != upbit.OrderStateWait {
+		t.Fatalf("state = %s, want wait", placed.State)
+	}
+
+	// 실제 거래소에는 주문이 나가지 않는다
+	if n := ex.RequestCount("POST", "orders"); n != 0 {
+		t.Errorf("paper order reached the exchange (%d requests)", n)
+	}
+
+	// 매도 호가가 지정가까지 내려오면 다음 조회 때 지정가로 체결된다
+	ex.SetOrderBook(book("98900000", "98950000"))
+	got, err := p.GetOrder(ctx, placed.Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != upbit.OrderStateDone || !got.Trades[0].Price.Equal("99000000") {
+		t.Fatalf("order = %+v", got)
+	}
+
+	accounts, err := p.GetAccounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balances := map[string]upbit.Decimal{}
+	for _, a := range accounts {
+		balances[a.Currency] = a.Balance
+	}
+	// 99,000원 + 메이커 수수료 0.05% 49.5원
+	if !balances["BTC"].Equal("0.001") || !balances["KRW"].Equal("900950.5") {
+		t.Errorf("balances = %v", balances)
+	}
+
+	// 시장가 매도는 매수 호가를 따라 체결된다
+	sold, err := p.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "ask", OrdType: upbit.OrdTypeMarket, Volume: "0.001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sold.State != upbit.OrderStateDone || !sold.ExecutedVolume.Equal("0.001") {
+		t.Errorf("sell = %+v", sold)
+	}
+
+	history, err := p.GetOrderHistory(ctx, upbit.RequestParams{Market: "KRW-BTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Uuid != sold.Uuid {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestPaperCancel(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", nil)
+	ex.SetOrderBook(book("99999000", "100000000"))
+
+	p := paper.NewExchange(ex.Client(), map[string]upbit.Decimal{"KRW": "1000000"})
+	ctx := context.Background()
+
+	placed, err := p.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "99000000", Volume: "0.001", Identifier: "paper-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "99000000", Volume: "0.001", Identifier: "paper-1"}); err == nil {
+		t.Error("duplicate identifier accepted")
+	}
+
+	if ok, err := p.CancelOrderByIdentifier(ctx, "paper-1"); !ok || err != nil {
+		t.Fatalf("cancel = %v, %v", ok, err)
+	}
+	if _, err := p.CancelOrder(ctx, placed.Uuid); err == nil {
+		t.Error("canceling a canceled order succeeded")
+	}
+
+	chance, err := p.GetChance(ctx, "KRW-BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !chance.BidAccount.Balance.Equal("1000000") || !chance.BidAccount.Locked.IsZero() {
+		t.Errorf("bid account = %+v after cancel", chance.BidAccount)
+	}
+}
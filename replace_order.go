@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"upbit-mcp-server/upbit"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// 취소 요청 후 주문이 취소(또는 체결) 상태가 될 때까지 기다리는 시간과 조회 간격
+const (
+	replaceWaitTimeout  = 5 * time.Second
+	replacePollInterval = 200 * time.Millisecond
+)
+
+type ReplaceOrderRequest struct {
+	UUID          string        `json:"uuid,omitempty" jsonschema:"Unique identifier (UUID) of the open limit order to replace. Either uuid or identifier is required."`
+	Identifier    string        `json:"identifier,omitempty" jsonschema:"Client order id of the open limit order to replace. Either uuid or identifier is required."`
+	Price         upbit.Decimal `json:"price" jsonschema:"New order price based on the quote currency."`
+	PriceRounding string        `json:"price_rounding,omitempty" jsonschema:"Optional. Align the new price to the Upbit tick size (price unit) of the market. Allowed: 'down', 'up', 'nearest'."`
+	NewIdentifier string        `json:"new_identifier,omitempty" jsonschema:"Optional client order id for the new order. Generated automatically when omitted."`
+}
+
+// ReplaceOrderResult 취소한 주문과 남은 수량으로 새로 낸 주문
+type ReplaceOrderResult struct {
+	CanceledOrder  upbit.Order   `json:"canceled_order" jsonschema:"The original order after cancellation. Its executed_volume includes fills that happened before the cancellation. Its state is done when the order was completely filled before it could be canceled."`
+	NewOrder       *upbit.Order  `json:"new_order,omitempty" jsonschema:"The new order for the remaining volume. Absent when the original order was completely filled before it could be canceled."`
+	Replaced       bool          `json:"replaced" jsonschema:"Whether a new order was placed."`
+	RequestedPrice upbit.Decimal `json:"requested_price" jsonschema:"Price requested by the caller before tick size adjustment"`
+	PriceAdjusted  bool          `json:"price_adjusted" jsonschema:"Whether the price was changed to align with the tick size"`
+}
+
+func ReplaceOrder(ctx context.Context, req *mcp.CallToolRequest, params *ReplaceOrderRequest) (
+	*mcp.CallToolResult,
+	*ReplaceOrderResult,
+	error,
+) {
+	var res mcp.CallToolResult
+
+	trader, ok := ctx.Value(upbitTraderKey{}).(upbit.Trader)
+	if !ok {
+		return nil, nil, missingDependency("Upbit trader")
+	}
+
+	if err := requireOneOf(params.UUID, params.Identifier); err != nil {
+		return nil, nil, err
+	}
+
+	var order upbit.Order
+	var err error
+	if params.UUID != "" {
+		order, err = trader.GetOrder(ctx, params.UUID)
+	} else {
+		order, err = trader.GetOrderByIdentifier(ctx, params.Identifier)
+	}
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+	if order.OrdType != "limit" || order.State != upbit.OrderStateWait {
+		return nil, nil, validationError("only open limit orders can be replaced, order %s is a %s order in state %s", order.Uuid, order.OrdType, order.State)
+	}
+
+	// 이미 사용한 identifier로 주문하면 새 주문 대신 기존 주문이 반환되므로 취소하기 전에 거절한다
+	if params.NewIdentifier != "" {
+		if _, err := trader.GetOrderByIdentifier(ctx, params.NewIdentifier); err == nil {
+			return nil, nil, toolError(&upbit.APIError{StatusCode: 400, Name: upbit.ErrDuplicateIdentifier, Message: fmt.Sprintf("identifier %s is already used by another order", params.NewIdentifier)})
+		}
+	}
+
+	price, err := normalizeLimitPrice(order.Market, params.Price, params.PriceRounding)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	// 자전거래 방지 모드는 원래 주문의 설정을 따른다
+	smpType := order.SmpType
+	if smpType == "" {
+		smpType = upbit.SmpCancelMaker
+	}
+
+	newParams := upbit.RequestParams{
+		Market:     order.Market,
+		Side:       order.Side,
+		OrdType:    "limit",
+		Price:      price,
+		Volume:     order.RemainingVolume,
+		SmpType:    smpType,
+		Identifier: params.NewIdentifier,
+	}
+
+	// 취소한 뒤에 확인을 거절하면 주문이 사라지므로 현재 남은 수량으로 먼저 확인을 받는다.
+	// 취소 사이에 체결되어 수량이 줄어드는 것은 확인한 주문보다 작으므로 다시 묻지 않는다.
+	if err := confirmOrder(ctx, req, newParams); err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	result := &ReplaceOrderResult{
+		RequestedPrice: params.Price,
+		PriceAdjusted:  !price.Equal(params.Price),
+	}
+
+	if _, err := trader.CancelOrder(ctx, order.Uuid); err != nil {
+		// 조회와 취소 사이에 전부 체결되면 취소할 주문이 없다. 바꿀 수량도 없으므로 체결된 주문을 그대로 알린다
+		if filled, getErr := trader.GetOrder(ctx, order.Uuid); getErr == nil && filled.State == upbit.OrderStateDone {
+			result.CanceledOrder = filled
+			return &res, result, nil
+		}
+		return nil, nil, toolError(err)
+	}
+	canceled, err := upbit.WaitOrderClosed(ctx, trader, order.Uuid, replaceWaitTimeout, replacePollInterval)
+	if err != nil {
+		return nil, nil, toolError(err)
+	}
+
+	result.CanceledOrder = canceled
+	if canceled.RemainingVolume.Sign() <= 0 {
+		return &res, result, nil
+	}
+
+	newParams.Volume = canceled.RemainingVolume
+	newOrder, err := trader.PlaceOrder(ctx, newParams)
+	if err != nil {
+		return nil, nil, replaceFailed(canceled, err)
+	}
+	result.NewOrder = &newOrder
+	result.Replaced = true
+	return &res, result, nil
+}
+
+// replaceFailed 원래 주문은 취소되었지만 새 주문이 실패한 경우의 오류. 남은 수량이 주문 없이 남았음을 알린다
+func replaceFailed(canceled upbit.Order, err error) error {
+	te := toolError(err).(*toolErr)
+	te.payload.Message = fmt.Sprintf("order %s was canceled with %s remaining, but the replacement order failed: %s", canceled.Uuid, canceled.RemainingVolume, te.payload.Message)
+	return te
+}
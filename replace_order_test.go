@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+func TestReplaceOrder(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	client := ex.Client()
+	ctx := context.WithValue(context.Background(), upbitTraderKey{}, upbit.Trader(client))
+
+	order, err := client.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "90000000", Volume: "0.001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, result, err := ReplaceOrder(ctx, nil, &ReplaceOrderRequest{UUID: order.Uuid, Price: "95000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Replaced || result.NewOrder == nil || !result.NewOrder.Price.Equal("95000000") || !result.NewOrder.Volume.Equal("0.001") {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.CanceledOrder.State != upbit.OrderStateCancel {
+		t.Errorf("canceled state = %s", result.CanceledOrder.State)
+	}
+}
+
+func TestReplaceOrderFilledBeforeCancel(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	client := ex.Client()
+	trader := ex.FillBefore(client, "KRW-BTC", "90000000")
+	ctx := context.WithValue(context.Background(), upbitTraderKey{}, upbit.Trader(trader))
+
+	order, err := client.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "90000000", Volume: "0.001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 취소하기 전에 전부 체결되면 오류 대신 체결된 주문을 반환하고 새 주문은 내지 않는다
+	_, result, err := ReplaceOrder(ctx, nil, &ReplaceOrderRequest{UUID: order.Uuid, Price: "95000000"})
+	if err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+	if result.Replaced || result.NewOrder != nil {
+		t.Errorf("replacement placed for a filled order: %+v", result.NewOrder)
+	}
+	if result.CanceledOrder.State != upbit.OrderStateDone || !result.CanceledOrder.ExecutedVolume.Equal("0.001") {
+		t.Errorf("canceled order = %+v", result.CanceledOrder)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+}
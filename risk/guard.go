@@ -0,0 +1,423 @@
+// Package risk 주문이 거래소에 도달하기 전에 손실 한도를 확인하는 위험 관리 계층.
+package risk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+)
+
+// 위반한 한도를 나타내는 규칙 이름
+const (
+	RuleMarketNotAllowed = "market_not_allowed"
+	RuleMaxOrderKRW      = "max_order_krw"
+	RuleMaxDailyKRW      = "max_daily_krw"
+	RuleMaxPosition      = "max_position_pct"
+	RuleMaxOpenOrders    = "max_open_orders"
+)
+
+// stateName 일일 거래 금액을 저장하는 파일 이름
+const stateName = "risk"
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+// priceTTL 조회한 현재가를 다시 쓰는 시간. 연달아 들어오는 주문마다 시세를 조회하지 않도록 한다
+const priceTTL = 2 * time.Second
+
+// marketsTTL 조회한 마켓 목록을 다시 쓰는 시간
+const marketsTTL = 10 * time.Minute
+
+// Limits 주문 한도. 0이나 빈 값은 제한하지 않는다.
+type Limits struct {
+	// MaxOrderKRW 주문 하나의 최대 금액 (KRW 환산)
+	MaxOrderKRW upbit.Decimal
+	// MaxDailyKRW 하루(KST) 동안 주문할 수 있는 최대 금액 (KRW 환산)
+	MaxDailyKRW upbit.Decimal
+	// MaxPositionPct 매수 후 한 자산이 전체 자산에서 차지할 수 있는 최대 비율 (%)
+	MaxPositionPct float64
+	// AllowedMarkets 주문할 수 있는 마켓. 비어 있으면 모든 마켓을 허용한다.
+	AllowedMarkets []string
+	// DeniedMarkets 주문할 수 없는 마켓
+	DeniedMarkets []string
+	// MaxOpenOrders 동시에 대기할 수 있는 최대 주문 수
+	MaxOpenOrders int
+}
+
+// Violation 한도를 넘어 거절된 주문
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("risk limit %s: %s", v.Rule, v.Message)
+}
+
+func violation(rule, format string, args ...any) error {
+	return &Violation{Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+// Market 주문 금액과 자산 가치를 KRW로 환산할 때 사용하는 시세 조회 기능
+type Market interface {
+	GetMarkets(ctx context.Context) ([]upbit.MarketInfo, error)
+	GetTicker(ctx context.Context, symbol string) ([]upbit.Ticker, error)
+}
+
+// Guard 주문을 한도와 비교한 뒤 통과한 주문만 내부 Trader로 전달하는 upbit.Trader.
+// 주문 외의 조회와 취소는 그대로 전달한다.
+type Guard struct {
+	upbit.Trader
+
+	market Market
+	limits Limits
+	store  *store.Store
+
+	// mu 일일 거래 금액의 확인과 예약, 기록을 보호한다. 거래소 요청 중에는 잡지 않는다
+	mu    sync.Mutex
+	state state
+	// reserved 한도를 통과해 거래소 응답을 기다리는 주문의 금액 합계
+	reserved upbit.Decimal
+	// inflight 한도를 통과해 거래소 응답을 기다리는 주문 수. 아직 대기 주문 목록에 없으므로 따로 센다
+	inflight int
+
+	// cacheMu 현재가와 마켓 목록 캐시를 보호한다
+	cacheMu   sync.Mutex
+	cache     map[string]cachedPrice
+	markets   map[string]bool
+	marketsAt time.Time
+}
+
+// cachedPrice 캐시에 보관한 마켓 하나의 현재가
+type cachedPrice struct {
+	price upbit.Decimal
+	at    time.Time
+}
+
+// state 재시작 후에도 유지되는 일일 거래 금액
+type state struct {
+	Date      string        `json:"date"`
+	TradedKRW upbit.Decimal `json:"traded_krw"`
+}
+
+// NewGuard trader 앞에 한도 확인 계층을 둔다. 일일 거래 금액은 st에 저장된다.
+func NewGuard(trader upbit.Trader, market Market, limits Limits, st *store.Store) (*Guard, error) {
+	g := &Guard{
+		Trader: trader,
+		market: market,
+		limits: limits,
+		store:  st,
+		cache:  map[string]cachedPrice{},
+	}
+	if _, err := st.Load(stateName, &g.state); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// TradedToday 오늘(KST) 주문한 금액 (KRW 환산)
+func (g *Guard) TradedToday() upbit.Decimal {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.tradedToday(time.Now())
+}
+
+// PlaceOrder 한도를 확인하고 통과하면 주문한다. 주문이 접수되면 주문 금액을 일일 거래 금액에 더한다.
+// 거래소 응답을 기다리는 동안 주문 금액과 대기 주문 한 자리를 예약해 두므로
+// 동시에 들어온 주문이 함께 일일 한도나 대기 주문 수 한도를 넘지 못한다.
+func (g *Guard) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	notional, open, err := g.check(ctx, params)
+	if err != nil {
+		return upbit.Order{}, err
+	}
+	if err := g.reserve(notional, open); err != nil {
+		return upbit.Order{}, err
+	}
+
+	order, err := g.Trader.PlaceOrder(ctx, params)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.reserved = g.reserved.Sub(notional)
+	g.inflight--
+	if err != nil {
+		return order, err
+	}
+	if notional.IsZero() {
+		return order, nil
+	}
+	if err := g.record(notional); err != nil {
+		return order, fmt.Errorf("order %s was placed but the daily traded amount could not be saved: %w", order.Uuid, err)
+	}
+	return order, nil
+}
+
+// check 주문이 모든 한도를 지키는지 확인하고 주문 금액(KRW 환산)과 대기 중인 주문 수를 반환한다.
+// 거래소 응답을 기다리는 주문까지 합친 대기 주문 수는 reserve에서 다시 확인한다.
+func (g *Guard) check(ctx context.Context, params upbit.RequestParams) (notional upbit.Decimal, open int, err error) {
+	l := g.limits
+	if slices.Contains(l.DeniedMarkets, params.Market) {
+		return "", 0, violation(RuleMarketNotAllowed, "market %s is in the denied market list", params.Market)
+	}
+	if len(l.AllowedMarkets) > 0 && !slices.Contains(l.AllowedMarkets, params.Market) {
+		return "", 0, violation(RuleMarketNotAllowed, "market %s is not in the allowed market list %v", params.Market, l.AllowedMarkets)
+	}
+
+	if l.MaxOpenOrders > 0 {
+		open, err = g.countOpenOrders(ctx, l.MaxOpenOrders)
+		if err != nil {
+			return "", 0, err
+		}
+		if open >= l.MaxOpenOrders {
+			return "", 0, violation(RuleMaxOpenOrders, "%d open orders already reached the limit of %d", open, l.MaxOpenOrders)
+		}
+	}
+
+	// 금액 한도가 없으면 시세를 조회하지 않는다
+	if l.MaxOrderKRW.Sign() <= 0 && l.MaxDailyKRW.Sign() <= 0 && l.MaxPositionPct <= 0 {
+		return "0", open, nil
+	}
+
+	// 주문 금액과 보유 자산을 KRW로 환산하는 데 필요한 마켓의 현재가만 조회한다
+	_, quote := splitMarket(params.Market)
+	var codes []string
+	if !upbit.IsAmountOrder(params.OrdType, params.Side) && params.OrdType != upbit.OrdTypeLimit {
+		codes = append(codes, params.Market)
+	}
+	if quote != "KRW" {
+		codes = append(codes, "KRW-"+quote)
+	}
+	checkPosition := l.MaxPositionPct > 0 && params.Side == "bid"
+	var accounts []upbit.Account
+	if checkPosition {
+		accounts, err = g.Trader.GetAccounts(ctx)
+		if err != nil {
+			return "", 0, err
+		}
+		for _, a := range accounts {
+			if a.Currency != "KRW" {
+				codes = append(codes, "KRW-"+a.Currency)
+			}
+		}
+	}
+
+	prices, err := g.prices(ctx, codes)
+	if err != nil {
+		return "", 0, err
+	}
+
+	notional, err = g.notional(params, prices)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if l.MaxOrderKRW.Sign() > 0 && notional.GreaterThan(l.MaxOrderKRW) {
+		return "", 0, violation(RuleMaxOrderKRW, "order amount %s KRW exceeds the per-order limit %s KRW", notional.Round(0, upbit.RoundUp), l.MaxOrderKRW)
+	}
+
+	if checkPosition {
+		if err := g.checkPosition(params, notional, accounts, prices); err != nil {
+			return "", 0, err
+		}
+	}
+	return notional, open, nil
+}
+
+// reserve 일일 한도와 대기 주문 수 한도를 확인하고 주문 금액과 대기 주문 한 자리를 예약한다.
+// open은 check에서 센 대기 주문 수이다
+func (g *Guard) reserve(notional upbit.Decimal, open int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if l := g.limits; l.MaxOpenOrders > 0 && open+g.inflight >= l.MaxOpenOrders {
+		return violation(RuleMaxOpenOrders, "%d open orders and %d orders being placed already reached the limit of %d", open, g.inflight, l.MaxOpenOrders)
+	}
+
+	if l := g.limits; l.MaxDailyKRW.Sign() > 0 {
+		traded := g.tradedToday(time.Now()).Add(g.reserved)
+		if traded.Add(notional).GreaterThan(l.MaxDailyKRW) {
+			return violation(RuleMaxDailyKRW, "order amount %s KRW would exceed the daily limit %s KRW (already traded today: %s KRW)", notional.Round(0, upbit.RoundUp), l.MaxDailyKRW, traded.Round(0, upbit.RoundUp))
+		}
+	}
+	g.reserved = g.reserved.Add(notional)
+	g.inflight++
+	return nil
+}
+
+// checkPosition 매수 후 자산 비중이 한도를 넘는지 확인
+func (g *Guard) checkPosition(params upbit.RequestParams, notional upbit.Decimal, accounts []upbit.Account, prices map[string]upbit.Decimal) error {
+	base, _ := splitMarket(params.Market)
+	var total, position upbit.Decimal
+	for _, a := range accounts {
+		value, ok := valueKRW(a.Currency, a.Balance.Add(a.Locked), prices)
+		if !ok {
+			continue
+		}
+		total = total.Add(value)
+		if a.Currency == base {
+			position = value
+		}
+	}
+
+	// 매수 금액만큼 결제 화폐가 해당 자산으로 바뀌므로 전체 자산은 그대로이다
+	position = position.Add(notional)
+	if total.Sign() <= 0 {
+		return violation(RuleMaxPosition, "portfolio value is zero")
+	}
+
+	pct := position.Float64() / total.Float64() * 100
+	if pct > g.limits.MaxPositionPct {
+		return violation(RuleMaxPosition, "%s would be %.2f%% of the portfolio after this order, above the limit of %.2f%%", base, pct, g.limits.MaxPositionPct)
+	}
+	return nil
+}
+
+// countOpenOrders 대기 중인 주문 수. limit개 이상인지만 알면 되므로 그 이상은 세지 않는다
+func (g *Guard) countOpenOrders(ctx context.Context, limit int) (int, error) {
+	const pageSize = 100
+
+	count := 0
+	for page := 1; count < limit; page++ {
+		orders, err := g.Trader.GetOpenOrders(ctx, upbit.RequestParams{Page: page, Limit: pageSize})
+		if err != nil {
+			return 0, err
+		}
+		count += len(orders)
+		if len(orders) < pageSize {
+			break
+		}
+	}
+	return count, nil
+}
+
+// notional 주문 금액을 KRW로 환산
+func (g *Guard) notional(params upbit.RequestParams, prices map[string]upbit.Decimal) (upbit.Decimal, error) {
+	base, quote := splitMarket(params.Market)
+
+	var amount upbit.Decimal
+	switch {
+	case upbit.IsAmountOrder(params.OrdType, params.Side):
+		amount = params.Price
+	case params.OrdType == upbit.OrdTypeLimit:
+		amount = params.Price.Mul(params.Volume)
+	default:
+		price, ok := prices[base+"/"+quote]
+		if !ok {
+			return "", fmt.Errorf("no price for %s to estimate the order amount", params.Market)
+		}
+		amount = params.Volume.Mul(price)
+	}
+
+	value, ok := valueKRW(quote, amount, prices)
+	if !ok {
+		return "", fmt.Errorf("cannot convert %s to KRW", quote)
+	}
+	return value, nil
+}
+
+// prices codes 마켓의 현재가. "BTC/KRW" 형식의 키로 저장한다.
+// 거래소에 없는 마켓은 건너뛰고, priceTTL 안에 조회한 현재가는 다시 조회하지 않는다.
+func (g *Guard) prices(ctx context.Context, codes []string) (map[string]upbit.Decimal, error) {
+	markets, err := g.listedMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g.cacheMu.Lock()
+	now := time.Now()
+	prices := map[string]upbit.Decimal{}
+	var stale []string
+	for _, code := range codes {
+		if !markets[code] || slices.Contains(stale, code) {
+			continue
+		}
+		if q, ok := g.cache[code]; ok && now.Sub(q.at) < priceTTL {
+			base, quote := splitMarket(code)
+			prices[base+"/"+quote] = q.price
+			continue
+		}
+		stale = append(stale, code)
+	}
+	g.cacheMu.Unlock()
+	if len(stale) == 0 {
+		return prices, nil
+	}
+
+	tickers, err := g.market.GetTicker(ctx, strings.Join(stale, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+	for _, t := range tickers {
+		g.cache[t.Market] = cachedPrice{price: t.TradePrice, at: now}
+		base, quote := splitMarket(t.Market)
+		prices[base+"/"+quote] = t.TradePrice
+	}
+	return prices, nil
+}
+
+// listedMarkets 거래소에 있는 마켓. marketsTTL 동안 다시 조회하지 않는다
+func (g *Guard) listedMarkets(ctx context.Context) (map[string]bool, error) {
+	g.cacheMu.Lock()
+	if g.markets != nil && time.Since(g.marketsAt) < marketsTTL {
+		defer g.cacheMu.Unlock()
+		return g.markets, nil
+	}
+	g.cacheMu.Unlock()
+
+	list, err := g.market.GetMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	markets := make(map[string]bool, len(list))
+	for _, m := range list {
+		markets[m.Market] = true
+	}
+
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+	g.markets, g.marketsAt = markets, time.Now()
+	return markets, nil
+}
+
+func (g *Guard) tradedToday(now time.Time) upbit.Decimal {
+	if g.state.Date != now.In(kst).Format(time.DateOnly) {
+		return "0"
+	}
+	return g.state.TradedKRW
+}
+
+// record 주문 금액을 일일 거래 금액에 더하고 저장한다
+func (g *Guard) record(notional upbit.Decimal) error {
+	now := time.Now()
+	g.state = state{
+		Date:      now.In(kst).Format(time.DateOnly),
+		TradedKRW: g.tradedToday(now).Add(notional),
+	}
+	return g.store.Save(stateName, g.state)
+}
+
+// valueKRW currency 수량을 KRW로 환산
+func valueKRW(currency string, amount upbit.Decimal, prices map[string]upbit.Decimal) (upbit.Decimal, bool) {
+	if currency == "KRW" {
+		return amount, true
+	}
+	price, ok := prices[currency+"/KRW"]
+	if !ok {
+		return "", false
+	}
+	return amount.Mul(price), true
+}
+
+// splitMarket "KRW-BTC" -> ("BTC", "KRW")
+func splitMarket(market string) (base, quote string) {
+	quote, base, _ = strings.Cut(market, "-")
+	return base, quote
+}
@@ -0,0 +1,130 @@
+package risk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"upbit-mcp-server/risk"
+	"upbit-mcp-server/store"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+)
+
+func newGuard(t *testing.T, limits risk.Limits) (*risk.Guard, *upbittest.Exchange) {
+	t.Helper()
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000", "BTC": "0.01"})
+	ex.AddMarket(upbit.MarketInfo{Market: "KRW-ETH"})
+	ex.SetPrice("KRW-ETH", "5000000")
+
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ex.Client(upbit.WithRetryPolicy(upbit.RetryPolicy{MaxAttempts: 1}))
+	g, err := risk.NewGuard(client, client, limits, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g, ex
+}
+
+func TestGuardFetchesOnlyNeededPrices(t *testing.T) {
+	g, ex := newGuard(t, risk.Limits{MaxOrderKRW: "1000000"})
+	ctx := context.Background()
+
+	// 금액이 정해진 KRW 마켓 주문은 현재가가 필요 없다
+	if _, err := g.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypePrice, Price: "10000"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := ex.RequestCount("GET", "ticker"); n != 0 {
+		t.Errorf("ticker requests = %d, want 0", n)
+	}
+
+	// 시장가 매도는 그 마켓의 현재가만 조회하고, 잠시 동안은 캐시를 쓴다
+	for range 2 {
+		if _, err := g.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "ask", OrdType: upbit.OrdTypeMarket, Volume: "0.001"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := ex.RequestCount("GET", "ticker"); n != 1 {
+		t.Errorf("ticker requests = %d, want 1", n)
+	}
+}
+
+func TestGuardDailyLimit(t *testing.T) {
+	g, ex := newGuard(t, risk.Limits{MaxDailyKRW: "15000"})
+	ctx := context.Background()
+	bid := upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypePrice, Price: "10000"}
+
+	// 거래소가 거절한 주문은 예약한 금액을 돌려놓는다
+	ex.FailNext(upbittest.Failure{Method: "POST", Path: "orders", Status: 400, Name: upbit.ErrInsufficientFundsBid, Message: "rejected"})
+	if _, err := g.PlaceOrder(ctx, bid); err == nil {
+		t.Fatal("rejected order succeeded")
+	}
+	if _, err := g.PlaceOrder(ctx, bid); err != nil {
+		t.Fatalf("order after a rejection: %v", err)
+	}
+	if traded := g.TradedToday(); !traded.Equal("10000") {
+		t.Errorf("traded today = %s, want 10000", traded)
+	}
+
+	_, err := g.PlaceOrder(ctx, bid)
+	var v *risk.Violation
+	if !errors.As(err, &v) || v.Rule != risk.RuleMaxDailyKRW {
+		t.Errorf("order over the daily limit = %v, want %s", err, risk.RuleMaxDailyKRW)
+	}
+}
+
+// blockingTrader 주문을 거래소에 보내기 전에 release가 닫힐 때까지 기다린다
+type blockingTrader struct {
+	upbit.Trader
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTrader) PlaceOrder(ctx context.Context, params upbit.RequestParams) (upbit.Order, error) {
+	b.entered <- struct{}{}
+	<-b.release
+	return b.Trader.PlaceOrder(ctx, params)
+}
+
+func TestGuardReservesOpenOrderSlots(t *testing.T) {
+	ex := upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+	client := ex.Client()
+	trader := &blockingTrader{Trader: client, entered: make(chan struct{}, 1), release: make(chan struct{})}
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := risk.NewGuard(trader, client, risk.Limits{MaxOpenOrders: 1}, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	bid := upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypeLimit, Price: "90000000", Volume: "0.001"}
+
+	done := make(chan error)
+	go func() {
+		_, err := g.PlaceOrder(ctx, bid)
+		done <- err
+	}()
+	<-trader.entered
+
+	// 첫 주문이 아직 대기 주문 목록에 없어도 응답을 기다리는 동안 자리를 차지한다
+	_, err = g.PlaceOrder(ctx, bid)
+	var v *risk.Violation
+	if !errors.As(err, &v) || v.Rule != risk.RuleMaxOpenOrders {
+		t.Errorf("order while another is being placed = %v, want %s", err, risk.RuleMaxOpenOrders)
+	}
+
+	close(trader.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.PlaceOrder(ctx, bid); !errors.As(err, &v) || v.Rule != risk.RuleMaxOpenOrders {
+		t.Errorf("order over the open order limit = %v, want %s", err, risk.RuleMaxOpenOrders)
+	}
+	if n := ex.RequestCount("POST", "orders"); n != 1 {
+		t.Errorf("orders placed = %d, want 1", n)
+	}
+}
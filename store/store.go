@@ -0,0 +1,93 @@
+// Package store 서버를 다시 시작해도 유지해야 하는 상태를 JSON 파일로 저장한다.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store 디렉터리 하나에 이름별 JSON 파일로 값을 저장한다
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// DefaultDir 기본 상태 디렉터리 (~/.upbit-mcp-server)
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".upbit-mcp-server"), nil
+}
+
+// Open dir을 상태 디렉터리로 사용하는 Store 생성. 디렉터리가 없으면 만든다.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create state dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir 상태 디렉터리 경로
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Sub 하위 디렉터리를 사용하는 Store. 모의 거래처럼 상태를 분리해야 할 때 사용
+func (s *Store) Sub(name string) (*Store, error) {
+	return Open(filepath.Join(s.dir, name))
+}
+
+// Load name에 저장된 값을 v로 읽는다. 저장된 적이 없으면 v를 그대로 두고 false를 반환한다.
+func (s *Store) Load(name string, v any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("decode %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Save v를 name에 저장한다. 임시 파일에 쓴 뒤 이름을 바꾸므로 중간에 종료되어도 이전 값이 남는다.
+func (s *Store) Save(name string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
@@ -0,0 +1,97 @@
+package upbit
+
+import (
+	"fmt"
+	"slices"
+)
+
+// 주문 전 검증에서 사용하는 거절 사유. 업비트 오류 이름과 겹치는 경우 같은 이름을 사용한다.
+const (
+	RejectMarketNotActive    = "market_not_active"
+	RejectUnsupportedSide    = "unsupported_side"
+	RejectUnsupportedOrdType = "unsupported_ord_type"
+	RejectOverMaxTotal       = "over_max_total"
+)
+
+// OrderRejection 주문 가능 정보(orders/chance)로 검증한 결과 거절된 주문
+type OrderRejection struct {
+	Name    string
+	Message string
+}
+
+func (e *OrderRejection) Error() string {
+	return fmt.Sprintf("order rejected: %s: %s", e.Name, e.Message)
+}
+
+func reject(name, format string, args ...any) error {
+	return &OrderRejection{Name: name, Message: fmt.Sprintf(format, args...)}
+}
+
+// CheckOrder 주문 가능 정보로 주문을 거래소에 보내기 전에 검증한다.
+// 마켓 상태, 주문 종류, 호가 단위, 최소/최대 주문 금액, 수수료를 포함한 주문 가능 잔고를 확인하며
+// 주문 금액을 알 수 없는 시장가/최유리 매도는 refPrice(현재가)로 금액을 추정한다. refPrice가 0이면 금액 검증은 생략한다.
+func (c Chance) CheckOrder(params RequestParams, refPrice Decimal) error {
+	m := c.Market
+	if m.State != "" && m.State != "active" {
+		return reject(RejectMarketNotActive, "market %s is not active (state: %s)", m.Id, m.State)
+	}
+	if len(m.OrderSides) > 0 && !slices.Contains(m.OrderSides, params.Side) {
+		return reject(RejectUnsupportedSide, "market %s does not support side %q (supported: %v)", m.Id, params.Side, m.OrderSides)
+	}
+
+	ordTypes, minTotal, quote := m.BidTypes, m.Bid.MinTotal, m.Bid.Currency
+	if params.Side == "ask" {
+		ordTypes, minTotal = m.AskTypes, m.Ask.MinTotal
+	}
+	ordType := ChanceOrdType(params.OrdType, params.TimeInForce)
+	if len(ordTypes) > 0 && !slices.Contains(ordTypes, ordType) {
+		return reject(RejectUnsupportedOrdType, "market %s does not support %s orders of type %q (supported: %v)", m.Id, params.Side, ordType, ordTypes)
+	}
+
+	if params.OrdType == OrdTypeLimit {
+		if _, err := PriceUnit(m.Id, params.Price); err == nil && !IsValidPrice(m.Id, params.Price) {
+			name := ErrInvalidPriceBid
+			if params.Side == "ask" {
+				name = ErrInvalidPriceAsk
+			}
+			return reject(name, "price %s is not aligned to the tick size of %s", params.Price, m.Id)
+		}
+	}
+
+	var total Decimal
+	switch {
+	case IsAmountOrder(params.OrdType, params.Side):
+		total = params.Price
+	case params.OrdType == OrdTypeLimit:
+		total = params.Price.Mul(params.Volume)
+	default:
+		total = params.Volume.Mul(refPrice)
+	}
+
+	if !total.IsZero() {
+		if total.LessThan(minTotal) {
+			name := ErrUnderMinTotalBid
+			if params.Side == "ask" {
+				name = ErrUnderMinTotalAsk
+			}
+			return reject(name, "order total %s %s is below the minimum order total %s %s", total, quote, minTotal, quote)
+		}
+		if m.MaxTotal.Sign() > 0 && total.GreaterThan(m.MaxTotal) {
+			return reject(RejectOverMaxTotal, "order total %s %s exceeds the maximum order total %s %s", total, quote, m.MaxTotal, quote)
+		}
+	}
+
+	switch params.Side {
+	case "bid":
+		// 매수 주문은 수수료까지 포함한 금액을 묶어둔다
+		cost := total.Add(total.Mul(c.BidFee))
+		if cost.GreaterThan(c.BidAccount.Balance) {
+			return reject(ErrInsufficientFundsBid, "order cost %s %s including fee (rate %s) exceeds available balance %s %s", cost, quote, c.BidFee, c.BidAccount.Balance, c.BidAccount.Currency)
+		}
+	case "ask":
+		if params.Volume.GreaterThan(c.AskAccount.Balance) {
+			return reject(ErrInsufficientFundsAsk, "order volume %s exceeds available balance %s %s", params.Volume, c.AskAccount.Balance, c.AskAccount.Currency)
+		}
+	}
+	return nil
+}
@@ -1,191 +1,228 @@
 package upbit
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"sort"
 	"strings"
-	"time"
 )
 
+// BaseURL 업비트 API 기본 주소
 const BaseURL = "https://api.upbit.com/v1/"
 
 type Client struct {
-	AccessKey  string
-	SecretKey  string
-	HttpClient *http.Client
+	AccessKey   string
+	SecretKey   string
+	BaseURL     string
+	UserAgent   string
+	HttpClient  *http.Client
+	RateLimiter *RateLimiter
+	RetryPolicy RetryPolicy
 }
 
 // NewClient 업비트 클라이언트 생성
-func NewClient(accessKey, secretKey string) *Client {
-	return &Client{
+func NewClient(accessKey, secretKey string, opts ...Option) *Client {
+	c := &Client{
 		AccessKey: accessKey,
 		SecretKey: secretKey,
+		BaseURL:   BaseURL,
 		HttpClient: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout: DefaultTimeout,
 		},
+		RateLimiter: NewRateLimiter(DefaultRateLimits()),
+		RetryPolicy: DefaultRetryPolicy(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // GetAccounts: 전체 계좌 조회
-func (c *Client) GetAccounts() ([]Account, error) {
+func (c *Client) GetAccounts(ctx context.Context) ([]Account, error) {
 	var res []Account
-	err := c.doRequest("GET", "accounts", nil, &res)
+	err := c.doRequest(ctx, "GET", "accounts", nil, &res)
 	return res, err
 }
 
 // GetOrderHistory: 완료된 주문 조회
-func (c *Client) GetOrderHistory(params RequestParams) ([]Order, error) {
+func (c *Client) GetOrderHistory(ctx context.Context, params RequestParams) ([]Order, error) {
 	var res []Order
-	err := c.doRequest("GET", "orders/closed", params, &res)
+	err := c.doRequest(ctx, "GET", "orders/closed", params, &res)
 	return res, err
 }
 
 // GetOrder: 특정 주문 조회
-func (c *Client) GetOrder(uuid string) (Order, error) {
+func (c *Client) GetOrder(ctx context.Context, uuid string) (Order, error) {
 	var res Order
 	params := RequestParams{Uuid: uuid}
-	err := c.doRequest("GET", "order", params, &res)
+	err := c.doRequest(ctx, "GET", "order", params, &res)
+	return res, err
+}
+
+// GetOrderByIdentifier: 주문할 때 지정한 identifier로 주문 조회
+func (c *Client) GetOrderByIdentifier(ctx context.Context, identifier string) (Order, error) {
+	var res Order
+	params := RequestParams{Identifier: identifier}
+	err := c.doRequest(ctx, "GET", "order", params, &res)
 	return res, err
 }
 
 // GetOpenOrders: 진행중인 주문 리스트 조회
-func (c *Client) GetOpenOrders(params RequestParams) ([]Order, error) {
+func (c *Client) GetOpenOrders(ctx context.Context, params RequestParams) ([]Order, error) {
 	var res []Order
-	err := c.doRequest("GET", "orders/open", params, &res)
+	err := c.doRequest(ctx, "GET", "orders/open", params, &res)
 	return res, err
 }
 
 // CancelOrder: 주문 취소
-func (c *Client) CancelOrder(uuid string) (bool, error) {
+func (c *Client) CancelOrder(ctx context.Context, uuid string) (bool, error) {
 	var res Order
 	params := RequestParams{Uuid: uuid}
-	err := c.doRequest("DELETE", "order", params, &res)
+	err := c.doRequest(ctx, "DELETE", "order", params, &res)
 	// 성공하면 uuid가 담긴 객체가 옴
 	return err == nil && res.Uuid != "", err
 }
 
+// CancelOrderByIdentifier: identifier로 주문 취소
+func (c *Client) CancelOrderByIdentifier(ctx context.Context, identifier string) (bool, error) {
+	var res Order
+	params := RequestParams{Identifier: identifier}
+	err := c.doRequest(ctx, "DELETE", "order", params, &res)
+	return err == nil && res.Uuid != "", err
+}
+
 // PlaceOrder: 주문하기
-func (c *Client) PlaceOrder(params RequestParams) (Order, error) {
+func (c *Client) PlaceOrder(ctx context.Context, params RequestParams) (Order, error) {
 	var res Order
-	err := c.doRequest("POST", "orders", params, &res)
+	err := c.doRequest(ctx, "POST", "orders", params, &res)
 	return res, err
 }
 
 // GetChance: 주문 가능 정보 확인
-func (c *Client) GetChance(market string) (Chance, error) {
+func (c *Client) GetChance(ctx context.Context, market string) (Chance, error) {
 	var res Chance
 	params := RequestParams{Market: market}
-	err := c.doRequest("GET", "orders/chance", params, &res)
+	err := c.doRequest(ctx, "GET", "orders/chance", params, &res)
 	return res, err
 }
 
 // GetCoinAddresses: 전체 입금 주소 조회
-func (c *Client) GetCoinAddresses() ([]CoinAddress, error) {
+func (c *Client) GetCoinAddresses(ctx context.Context) ([]CoinAddress, error) {
 	var res []CoinAddress
-	err := c.doRequest("GET", "deposits/coin_addresses", nil, &res)
+	err := c.doRequest(ctx, "GET", "deposits/coin_addresses", nil, &res)
 	return res, err
 }
 
 // GetCoinAddress: 특정 코인 입금 주소 조회
-func (c *Client) GetCoinAddress(currency string) (CoinAddress, error) {
+func (c *Client) GetCoinAddress(ctx context.Context, currency string) (CoinAddress, error) {
 	var res CoinAddress
 	params := RequestParams{Currency: currency}
-	err := c.doRequest("GET", "deposits/coin_address", params, &res)
+	err := c.doRequest(ctx, "GET", "deposits/coin_address", params, &res)
 	return res, err
 }
 
 // GetWithdraws: 출금 리스트 조회
-func (c *Client) GetWithdraws(params RequestParams) ([]Deposit, error) {
+func (c *Client) GetWithdraws(ctx context.Context, params RequestParams) ([]Deposit, error) {
 	var res []Deposit
-	err := c.doRequest("GET", "withdraws", params, &res)
+	err := c.doRequest(ctx, "GET", "withdraws", params, &res)
 	return res, err
 }
 
 // GetWithdraw: 개별 출금 조회
-func (c *Client) GetWithdraw(uuid string) (Deposit, error) {
+func (c *Client) GetWithdraw(ctx context.Context, uuid string) (Deposit, error) {
 	var res Deposit
 	params := RequestParams{Uuid: uuid}
-	err := c.doRequest("GET", "withdraw", params, &res)
+	err := c.doRequest(ctx, "GET", "withdraw", params, &res)
 	return res, err
 }
 
 // DepositKrw: 원화 입금하기
-func (c *Client) DepositKrw(amount string) (Deposit, error) {
+func (c *Client) DepositKrw(ctx context.Context, amount Decimal) (Deposit, error) {
 	var res Deposit
 	params := RequestParams{Amount: amount}
-	err := c.doRequest("POST", "deposits/krw", params, &res)
+	err := c.doRequest(ctx, "POST", "deposits/krw", params, &res)
 	return res, err
 }
 
 // GetWalletStatus: 지갑 상태 조회
-func (c *Client) GetWalletStatus() ([]WalletStatus, error) {
+func (c *Client) GetWalletStatus(ctx context.Context) ([]WalletStatus, error) {
 	var res []WalletStatus
-	err := c.doRequest("GET", "status/wallet", nil, &res)
+	err := c.doRequest(ctx, "GET", "status/wallet", nil, &res)
 	return res, err
 }
 
 // GetTicks: 최근 체결 내역
-func (c *Client) GetTicks(params RequestParams) ([]Tick, error) {
+func (c *Client) GetTicks(ctx context.Context, params RequestParams) ([]Tick, error) {
 	var res []Tick
-	err := c.doNonAuthRequest("trades/ticks", params, &res)
+	err := c.doNonAuthRequest(ctx, "trades/ticks", params, &res)
 	return res, err
 }
 
 // GetTicker: 현재가 정보
-func (c *Client) GetTicker(symbol string) ([]Ticker, error) {
+func (c *Client) GetTicker(ctx context.Context, symbol string) ([]Ticker, error) {
 	var res []Ticker
 	// endpoint pattern: ticker?markets=KRW-BTC
-	err := c.doNonAuthRequest("ticker", map[string]string{"markets": symbol}, &res)
+	err := c.doNonAuthRequest(ctx, "ticker", map[string]string{"markets": symbol}, &res)
 	return res, err
 }
 
 // GetOrderBooks: 호가 정보
-func (c *Client) GetOrderBooks(symbol string) ([]OrderBook, error) {
+func (c *Client) GetOrderBooks(ctx context.Context, symbol string) ([]OrderBook, error) {
+	return c.GetOrderBooksByLevel(ctx, symbol, "")
+}
+
+// GetOrderBooksByLevel: 가격 단위(level)로 묶은 호가 정보. level이 비어 있으면 묶지 않는다 (KRW 마켓만 지원)
+func (c *Client) GetOrderBooksByLevel(ctx context.Context, symbol string, level Decimal) ([]OrderBook, error) {
 	var res []OrderBook
-	err := c.doNonAuthRequest("orderbook", map[string]string{"markets": symbol}, &res)
+	params := map[string]string{"markets": symbol}
+	if level != "" {
+		params["level"] = level.String()
+	}
+	err := c.doNonAuthRequest(ctx, "orderbook", params, &res)
 	return res, err
 }
 
 // GetDayCandles: 일봉
-func (c *Client) GetDayCandles(params RequestParams) ([]*Candle, error) {
+func (c *Client) GetDayCandles(ctx context.Context, params RequestParams) ([]*Candle, error) {
 	var res []*Candle
-	err := c.doNonAuthRequest("candles/days", params, &res)
+	err := c.doNonAuthRequest(ctx, "candles/days", params, &res)
 	return res, err
 }
 
 // GetWeekCandles: 주봉
-func (c *Client) GetWeekCandles(params RequestParams) ([]*Candle, error) {
+func (c *Client) GetWeekCandles(ctx context.Context, params RequestParams) ([]*Candle, error) {
 	var res []*Candle
-	err := c.doNonAuthRequest("candles/weeks", params, &res)
+	err := c.doNonAuthRequest(ctx, "candles/weeks", params, &res)
 	return res, err
 }
 
 // GetMonthCandles: 월봉
-func (c *Client) GetMonthCandles(params RequestParams) ([]*Candle, error) {
+func (c *Client) GetMonthCandles(ctx context.Context, params RequestParams) ([]*Candle, error) {
 	var res []*Candle
-	err := c.doNonAuthRequest("candles/months", params, &res)
+	err := c.doNonAuthRequest(ctx, "candles/months", params, &res)
 	return res, err
 }
 
 // GetMinuteCandles: 분봉
-func (c *Client) GetMinuteCandles(unit int, params RequestParams) ([]*Candle, error) {
+func (c *Client) GetMinuteCandles(ctx context.Context, unit int, params RequestParams) ([]*Candle, error) {
 	var res []*Candle
 	endpoint := fmt.Sprintf("candles/minutes/%d", unit)
-	err := c.doNonAuthRequest(endpoint, params, &res)
+	err := c.doNonAuthRequest(ctx, endpoint, params, &res)
 	return res, err
 }
 
 // GetMarkets: 마켓 코드 조회
-func (c *Client) GetMarkets() ([]MarketInfo, error) {
+func (c *Client) GetMarkets(ctx context.Context) ([]MarketInfo, error) {
 	var res []MarketInfo
-	err := c.doNonAuthRequest("market/all", nil, &res)
+	err := c.doNonAuthRequest(ctx, "market/all", nil, &res)
 	return res, err
 }
 
 // GetMarketTrends: 상승률/거래량 Top 10 조회
-func (c *Client) GetMarketTrends(limit int) (*MarketTrends, error) {
-	markets, err := c.GetMarkets()
+func (c *Client) GetMarketTrends(ctx context.Context, limit int) (*MarketTrends, error) {
+	markets, err := c.GetMarkets(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +234,7 @@ func (c *Client) GetMarketTrends(limit int) (*MarketTrends, error) {
 		}
 	}
 
-	tickers, err := c.GetTicker(strings.Join(marketCodes, ","))
+	tickers, err := c.GetTicker(ctx, strings.Join(marketCodes, ","))
 	if err != nil {
 		return nil, err
 	}
@@ -231,7 +268,7 @@ func (c *Client) GetMarketTrends(limit int) (*MarketTrends, error) {
 
 	// Sort by trade volume
 	sort.Slice(trendInfos, func(i, j int) bool {
-		return trendInfos[i].TradeVolume > trendInfos[j].TradeVolume
+		return trendInfos[i].TradeVolume.GreaterThan(trendInfos[j].TradeVolume)
 	})
 	topVolume := trendInfos
 	if len(topVolume) > limit {
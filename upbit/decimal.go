@@ -0,0 +1,357 @@
+package upbit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal 가격, 수량, 잔고를 표현하는 10진수.
+//
+// 업비트는 계좌/주문 금액을 문자열로, 시세를 숫자로 내려주는데 float64로 다루면
+// 0.1+0.2 = 0.30000000000000004 같은 오차가 주문 수량에 섞여 들어간다.
+// Decimal은 값을 10진 문자열로 보관하고 연산은 math/big으로 정확하게 수행한다.
+// 문자열 타입이므로 JSON과 스키마상으로는 기존 string 필드와 같고, 빈 문자열은 0으로 취급한다.
+// JSON(도구 입력, API 응답, 저장 파일)과 ParseDecimal은 잘못된 값을 거절하므로
+// 숫자가 아닌 값으로 연산하는 것은 프로그램 오류이며 0으로 넘어가지 않고 panic한다.
+type Decimal string
+
+// maxScale 허용하는 소수점 자리수(또는 10의 거듭제곱)의 최대값
+const maxScale = 100
+
+// RoundingMode 소수점 자리를 줄일 때의 반올림 방식
+type RoundingMode int
+
+const (
+	// RoundDown 0에 가까운 쪽으로 버림
+	RoundDown RoundingMode = iota
+	// RoundUp 0에서 먼 쪽으로 올림
+	RoundUp
+	// RoundNearest 가장 가까운 값으로 반올림 (0.5는 올림)
+	RoundNearest
+)
+
+// ParseRoundingMode "down", "up", "nearest" 문자열을 RoundingMode로 변환
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch s {
+	case "down":
+		return RoundDown, nil
+	case "up":
+		return RoundUp, nil
+	case "nearest":
+		return RoundNearest, nil
+	}
+	return RoundDown, fmt.Errorf("invalid rounding mode %q (allowed: down, up, nearest)", s)
+}
+
+// ParseDecimal 10진수 문자열 파싱. 지수 표기(1e-8)도 허용하며 결과는 일반 표기로 정규화된다.
+func ParseDecimal(s string) (Decimal, error) {
+	u, scale, err := parseDecimal(s)
+	if err != nil {
+		return "", err
+	}
+	return newDecimal(u, scale), nil
+}
+
+// MustParseDecimal ParseDecimal과 같지만 실패하면 panic
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromInt 정수로 Decimal 생성
+func DecimalFromInt(n int64) Decimal {
+	return Decimal(strconv.FormatInt(n, 10))
+}
+
+// DecimalFromFloat float64로 Decimal 생성. 값을 표현하는 가장 짧은 10진 표기를 사용한다.
+func DecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "0"
+	}
+	return MustParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// String 정규화된 10진 표기
+func (d Decimal) String() string {
+	if d == "" {
+		return "0"
+	}
+	return string(d)
+}
+
+// Float64 통계 계산 등 근사값이 필요한 곳에서 사용
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero 값이 0인지 여부
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Sign 음수면 -1, 0이면 0, 양수면 1
+func (d Decimal) Sign() int {
+	u, _ := d.parts()
+	return u.Sign()
+}
+
+// Cmp d < o 이면 -1, 같으면 0, d > o 이면 1
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal 값이 같은지 비교. "1.0"과 "1"처럼 표기가 달라도 값이 같으면 true
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return newDecimal(a.Add(a, b), scale)
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return newDecimal(a.Sub(a, b), scale)
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	a, as := d.parts()
+	b, bs := o.parts()
+	return newDecimal(a.Mul(a, b), as+bs)
+}
+
+// Div d / o 를 소수점 places 자리까지 구한다 (나머지는 버림). o가 0이면 panic
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	a, as := d.parts()
+	b, bs := o.parts()
+	if b.Sign() == 0 {
+		panic("upbit: decimal division by zero")
+	}
+
+	// a*10^-as / (b*10^-bs) = (a * 10^(places+bs-as) / b) * 10^-places
+	shift := int64(places) + int64(bs) - int64(as)
+	if shift >= 0 {
+		a.Mul(a, pow10(shift))
+	} else {
+		b.Mul(b, pow10(-shift))
+	}
+	return newDecimal(a.Quo(a, b), places)
+}
+
+func (d Decimal) Neg() Decimal {
+	u, scale := d.parts()
+	return newDecimal(u.Neg(u), scale)
+}
+
+func (d Decimal) Abs() Decimal {
+	u, scale := d.parts()
+	return newDecimal(u.Abs(u), scale)
+}
+
+// Round 소수점 places 자리로 맞춘다
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	u, scale := d.parts()
+	if scale <= places {
+		return d.normalize()
+	}
+
+	factor := pow10(int64(scale - places))
+	q, r := new(big.Int).QuoRem(u, factor, new(big.Int))
+	if r.Sign() != 0 {
+		switch mode {
+		case RoundUp:
+			q.Add(q, big.NewInt(int64(u.Sign())))
+		case RoundNearest:
+			if r.Abs(r).Lsh(r, 1).Cmp(factor) >= 0 {
+				q.Add(q, big.NewInt(int64(u.Sign())))
+			}
+		}
+	}
+	return newDecimal(q, places)
+}
+
+// RoundToStep step의 배수로 맞춘다. 호가 단위나 수량 단위에 가격을 맞출 때 사용
+func (d Decimal) RoundToStep(step Decimal, mode RoundingMode) Decimal {
+	if step.Sign() <= 0 {
+		return d.normalize()
+	}
+
+	a, b, scale := align(d, step)
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() != 0 {
+		switch mode {
+		case RoundUp:
+			q.Add(q, big.NewInt(int64(a.Sign())))
+		case RoundNearest:
+			if r.Abs(r).Lsh(r, 1).Cmp(b) >= 0 {
+				q.Add(q, big.NewInt(int64(a.Sign())))
+			}
+		}
+	}
+	return newDecimal(q.Mul(q, b), scale)
+}
+
+// MinDecimal 둘 중 작은 값
+func MinDecimal(a, b Decimal) Decimal {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// MaxDecimal 둘 중 큰 값
+func MaxDecimal(a, b Decimal) Decimal {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// UnmarshalJSON 문자열("1.5"), 숫자(1.5), null을 모두 허용한다.
+// 숫자는 float64를 거치지 않고 JSON 원문 그대로 읽으므로 정밀도 손실이 없다.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = ""
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*d = ""
+			return nil
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) normalize() Decimal {
+	u, scale := d.parts()
+	return newDecimal(u, scale)
+}
+
+// parts 값을 unscaled * 10^-scale 형태로 분해한다. 잘못된 값이면 panic
+func (d Decimal) parts() (*big.Int, int32) {
+	u, scale, err := parseDecimal(string(d))
+	if err != nil {
+		panic("upbit: " + err.Error())
+	}
+	return u, scale
+}
+
+func parseDecimal(s string) (*big.Int, int32, error) {
+	if s == "" {
+		return new(big.Int), 0, nil
+	}
+
+	mantissa, exponent := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa, exponent = s[:i], exp
+	}
+
+	neg := strings.HasPrefix(mantissa, "-")
+	unsigned := strings.TrimLeft(mantissa, "+-")
+	if len(mantissa)-len(unsigned) > 1 {
+		return nil, 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	mantissa = unsigned
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil, 0, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	u, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	if neg {
+		u.Neg(u)
+	}
+
+	// 터무니없이 큰 지수로 거대한 수를 만들지 않도록 범위를 제한한다
+	scale := int64(len(fracPart)) - exponent
+	if scale > maxScale || scale < -maxScale {
+		return nil, 0, fmt.Errorf("decimal exponent out of range %q", s)
+	}
+	return u, int32(scale), nil
+}
+
+// newDecimal unscaled * 10^-scale 값을 불필요한 0 없이 표기한다
+func newDecimal(u *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		u = new(big.Int).Mul(u, pow10(int64(-scale)))
+		scale = 0
+	}
+
+	neg := u.Sign() < 0
+	digits := new(big.Int).Abs(u).String()
+	if scale > 0 {
+		if len(digits) <= int(scale) {
+			digits = strings.Repeat("0", int(scale)-len(digits)+1) + digits
+		}
+		point := len(digits) - int(scale)
+		frac := strings.TrimRight(digits[point:], "0")
+		digits = digits[:point]
+		if frac != "" {
+			digits += "." + frac
+		}
+	}
+
+	if neg && digits != "0" {
+		digits = "-" + digits
+	}
+	return Decimal(digits)
+}
+
+// align 두 값을 같은 scale의 정수로 맞춘다
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	au, as := a.parts()
+	bu, bs := b.parts()
+	switch {
+	case as < bs:
+		au.Mul(au, pow10(int64(bs-as)))
+		return au, bu, bs
+	case as > bs:
+		bu.Mul(bu, pow10(int64(as-bs)))
+	}
+	return au, bu, as
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
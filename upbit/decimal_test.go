@@ -0,0 +1,263 @@
+package upbit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Decimal
+		wantErr bool
+	}{
+		{in: "", want: "0"},
+		{in: "0", want: "0"},
+		{in: "1.500", want: "1.5"},
+		{in: "001.0", want: "1"},
+		{in: "-0.0", want: "0"},
+		{in: "+2.25", want: "2.25"},
+		{in: ".5", want: "0.5"},
+		{in: "5.", want: "5"},
+		{in: "1e-8", want: "0.00000001"},
+		{in: "1.5E3", want: "1500"},
+		{in: "-2.5e-2", want: "-0.025"},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "1e1000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDecimal(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseDecimal(%q) = %s, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseDecimal(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Decimal
+		want Decimal
+	}{
+		// float64로는 0.30000000000000004가 되는 경우
+		{name: "0.1+0.2", got: Decimal("0.1").Add("0.2"), want: "0.3"},
+		{name: "빈 값은 0", got: Decimal("").Add("1.5"), want: "1.5"},
+		{name: "자리수가 다른 덧셈", got: Decimal("100").Add("0.00000001"), want: "100.00000001"},
+		{name: "뺄셈", got: Decimal("1").Sub("0.9999"), want: "0.0001"},
+		{name: "음수 결과", got: Decimal("1").Sub("2.5"), want: "-1.5"},
+		{name: "곱셈", got: Decimal("0.001").Mul("95000000"), want: "95000"},
+		{name: "곱셈 소수", got: Decimal("1.5").Mul("-0.2"), want: "-0.3"},
+		{name: "0 곱셈", got: Decimal("-3").Mul("0"), want: "0"},
+		{name: "부호 반전", got: Decimal("2.5").Neg(), want: "-2.5"},
+		{name: "0 부호 반전", got: Decimal("0").Neg(), want: "0"},
+		{name: "절대값", got: Decimal("-0.01").Abs(), want: "0.01"},
+		{name: "DecimalFromInt", got: DecimalFromInt(-42), want: "-42"},
+		{name: "DecimalFromFloat", got: DecimalFromFloat(0.1), want: "0.1"},
+		{name: "DecimalFromFloat 지수", got: DecimalFromFloat(1e-9), want: "0.000000001"},
+		{name: "MinDecimal", got: MinDecimal("1.10", "1.09"), want: "1.09"},
+		{name: "MaxDecimal", got: MaxDecimal("-1", "-2"), want: "-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalDiv(t *testing.T) {
+	tests := []struct {
+		a, b   Decimal
+		places int32
+		want   Decimal
+	}{
+		{a: "1", b: "3", places: 8, want: "0.33333333"},
+		// 반올림하지 않고 버린다
+		{a: "2", b: "3", places: 8, want: "0.66666666"},
+		{a: "-2", b: "3", places: 2, want: "-0.66"},
+		{a: "10000", b: "95000000", places: 8, want: "0.00010526"},
+		{a: "0.0003", b: "0.01", places: 8, want: "0.03"},
+		{a: "7", b: "2", places: 0, want: "3"},
+		{a: "1", b: "0.00000001", places: 0, want: "100000000"},
+		{a: "1234.5678", b: "1", places: 2, want: "1234.56"},
+		{a: "0", b: "7", places: 4, want: "0"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Div(tt.b, tt.places); got != tt.want {
+			t.Errorf("%s / %s (%d) = %s, want %s", tt.a, tt.b, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalDivByZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Div by zero did not panic")
+		}
+	}()
+	Decimal("1").Div("0", 8)
+}
+
+func TestDecimalInvalidPanics(t *testing.T) {
+	// 잘못된 값이 0으로 계산되어 주문 수량이나 한도에 섞이지 않도록 한다
+	for _, f := range []func(){
+		func() { Decimal("abc").Sign() },
+		func() { Decimal("1").Add("1,000") },
+		func() { Decimal("1").LessThan("NaN") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("arithmetic on an invalid decimal did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestDecimalCmp(t *testing.T) {
+	tests := []struct {
+		a, b Decimal
+		want int
+	}{
+		{a: "1", b: "1.000", want: 0},
+		{a: "", b: "0", want: 0},
+		{a: "0.1", b: "0.09", want: 1},
+		{a: "-1", b: "0.5", want: -1},
+		{a: "100000000", b: "99999999.99999999", want: 1},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Cmp(tt.b); got != tt.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.a.Equal(tt.b); got != (tt.want == 0) {
+			t.Errorf("Equal(%s, %s) = %v", tt.a, tt.b, got)
+		}
+		if got := tt.a.LessThan(tt.b); got != (tt.want < 0) {
+			t.Errorf("LessThan(%s, %s) = %v", tt.a, tt.b, got)
+		}
+		if got := tt.a.GreaterThan(tt.b); got != (tt.want > 0) {
+			t.Errorf("GreaterThan(%s, %s) = %v", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		in     Decimal
+		places int32
+		mode   RoundingMode
+		want   Decimal
+	}{
+		{in: "1.23456", places: 2, mode: RoundDown, want: "1.23"},
+		{in: "1.23456", places: 2, mode: RoundUp, want: "1.24"},
+		{in: "1.23456", places: 2, mode: RoundNearest, want: "1.23"},
+		{in: "1.235", places: 2, mode: RoundNearest, want: "1.24"},
+		{in: "-1.235", places: 2, mode: RoundNearest, want: "-1.24"},
+		{in: "-1.231", places: 2, mode: RoundDown, want: "-1.23"},
+		{in: "-1.231", places: 2, mode: RoundUp, want: "-1.24"},
+		{in: "1.5", places: 4, mode: RoundUp, want: "1.5"},
+		{in: "1.20", places: 1, mode: RoundUp, want: "1.2"},
+		{in: "0.00000001", places: 0, mode: RoundUp, want: "1"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Round(tt.places, tt.mode); got != tt.want {
+			t.Errorf("Round(%s, %d, %d) = %s, want %s", tt.in, tt.places, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalRoundToStep(t *testing.T) {
+	tests := []struct {
+		in   Decimal
+		step Decimal
+		mode RoundingMode
+		want Decimal
+	}{
+		{in: "95001234", step: "1000", mode: RoundDown, want: "95001000"},
+		{in: "95001234", step: "1000", mode: RoundUp, want: "95002000"},
+		{in: "95001500", step: "1000", mode: RoundNearest, want: "95002000"},
+		{in: "95001499", step: "1000", mode: RoundNearest, want: "95001000"},
+		{in: "95001000", step: "1000", mode: RoundUp, want: "95001000"},
+		{in: "12.37", step: "0.05", mode: RoundDown, want: "12.35"},
+		{in: "12.37", step: "0.05", mode: RoundUp, want: "12.4"},
+		{in: "0.123456789", step: "0.0001", mode: RoundNearest, want: "0.1235"},
+		{in: "-12.37", step: "0.05", mode: RoundDown, want: "-12.35"},
+		{in: "-12.37", step: "0.05", mode: RoundUp, want: "-12.4"},
+		// 0 이하의 단위는 정규화만 한다
+		{in: "1.50", step: "0", mode: RoundUp, want: "1.5"},
+		{in: "1.50", step: "-1", mode: RoundUp, want: "1.5"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.RoundToStep(tt.step, tt.mode); got != tt.want {
+			t.Errorf("RoundToStep(%s, %s, %d) = %s, want %s", tt.in, tt.step, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for in, want := range map[string]RoundingMode{"down": RoundDown, "up": RoundUp, "nearest": RoundNearest} {
+		if got, err := ParseRoundingMode(in); err != nil || got != want {
+			t.Errorf("ParseRoundingMode(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseRoundingMode("half"); err == nil {
+		t.Error("ParseRoundingMode(half) succeeded, want error")
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Decimal
+		wantErr bool
+	}{
+		{in: `"1.50"`, want: "1.5"},
+		{in: `1.50`, want: "1.5"},
+		// float64를 거치면 잃어버리는 자리수
+		{in: `0.12345678901234567890`, want: "0.1234567890123456789"},
+		{in: `null`, want: ""},
+		{in: `""`, want: ""},
+		{in: `"abc"`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got struct {
+				V Decimal `json:"v"`
+			}
+			err := json.Unmarshal([]byte(`{"v":`+tt.in+`}`), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %q, want error", tt.in, got.V)
+				}
+				return
+			}
+			if err != nil || got.V != tt.want {
+				t.Fatalf("Unmarshal(%s) = %q, %v, want %q", tt.in, got.V, err, tt.want)
+			}
+		})
+	}
+
+	// 문자열로 직렬화되어 업비트 요청 형식과 같다
+	b, _ := json.Marshal(struct {
+		V Decimal `json:"v"`
+	}{V: "0.001"})
+	if string(b) != `{"v":"0.001"}` {
+		t.Errorf("Marshal = %s", b)
+	}
+}
@@ -0,0 +1,144 @@
+package upbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 자주 쓰이는 업비트 오류 이름
+const (
+	ErrInsufficientFundsBid = "insufficient_funds_bid"
+	ErrInsufficientFundsAsk = "insufficient_funds_ask"
+	ErrUnderMinTotalBid     = "under_min_total_bid"
+	ErrUnderMinTotalAsk     = "under_min_total_ask"
+	ErrValidation           = "validation_error"
+	ErrInvalidPriceBid      = "invalid_price_bid"
+	ErrInvalidPriceAsk      = "invalid_price_ask"
+	ErrOrderNotFound        = "order_not_found"
+	ErrDuplicateIdentifier  = "duplicate_identifier"
+	ErrInvalidQueryPayload  = "invalid_query_payload"
+	ErrJwtVerification      = "jwt_verification"
+	ErrExpiredAccessKey     = "expired_access_key"
+	ErrNonceUsed            = "nonce_used"
+	ErrNoAuthorizationToken = "no_authorization_token"
+	ErrTooManyRequests      = "too_many_requests"
+	ErrServerError          = "server_error"
+)
+
+// APIError 업비트 API 오류 응답.
+// 업비트는 {"error":{"name":"...","message":"..."}} 형식으로 오류를 응답하며
+// errors.As로 꺼내 Name을 확인하면 오류 종류를 구분할 수 있다.
+type APIError struct {
+	StatusCode   int
+	Name         string
+	Message      string
+	RemainingReq *RemainingReq
+	Body         string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("upbit api error: %s (status: %d): %s", e.Name, e.StatusCode, e.Message)
+}
+
+// IsRateLimited 요청 수 제한(429)에 걸렸는지 여부
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsServerError 업비트 서버 오류(5xx) 여부
+func (e *APIError) IsServerError() bool {
+	return e.StatusCode >= 500
+}
+
+// IsTemporary 잠시 후 다시 시도하면 성공할 수 있는 오류인지 여부 (네트워크 오류, 요청 수 제한, 거래소 서버 오류)
+func IsTemporary(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRateLimited() || apiErr.IsServerError()
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
+// RemainingReq Remaining-Req 헤더로 전달되는 요청 수 제한 정보
+// e.g. "group=default; min=1800; sec=29"
+type RemainingReq struct {
+	Group string `json:"group"`
+	Min   int    `json:"min"`
+	Sec   int    `json:"sec"`
+}
+
+// ParseRemainingReq Remaining-Req 헤더 파싱
+func ParseRemainingReq(header string) (*RemainingReq, bool) {
+	if header == "" {
+		return nil, false
+	}
+
+	var res RemainingReq
+	for _, part := range strings.Split(header, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, false
+		}
+		switch key {
+		case "group":
+			res.Group = value
+		case "min":
+			res.Min, _ = strconv.Atoi(value)
+		case "sec":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, false
+			}
+			res.Sec = n
+		}
+	}
+
+	if res.Group == "" {
+		return nil, false
+	}
+	return &res, true
+}
+
+// newAPIError 실패한 응답으로부터 APIError 생성
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+	apiErr.RemainingReq, _ = ParseRemainingReq(resp.Header.Get("Remaining-Req"))
+
+	var envelope struct {
+		Error struct {
+			Name    json.RawMessage `json:"name"`
+			Message string          `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && len(envelope.Error.Name) > 0 {
+		// name이 숫자로 오는 경우도 있어 문자열로 통일
+		var name string
+		if err := json.Unmarshal(envelope.Error.Name, &name); err != nil {
+			name = string(envelope.Error.Name)
+		}
+		apiErr.Name = name
+		apiErr.Message = envelope.Error.Message
+		return apiErr
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.Name = ErrTooManyRequests
+	case resp.StatusCode >= 500:
+		apiErr.Name = ErrServerError
+	default:
+		apiErr.Name = strings.ReplaceAll(strings.ToLower(http.StatusText(resp.StatusCode)), " ", "_")
+	}
+	apiErr.Message = strings.TrimSpace(string(body))
+	return apiErr
+}
@@ -0,0 +1,61 @@
+package upbit
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout 요청 타임아웃 기본값
+const DefaultTimeout = time.Second * 10
+
+// Option NewClient에 전달하는 클라이언트 설정
+type Option func(*Client)
+
+// WithBaseURL API 주소를 변경한다. 로컬 테스트 서버나 프록시를 바라보게 할 때 사용
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL == "" {
+			return
+		}
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		c.BaseURL = baseURL
+	}
+}
+
+// WithTransport HTTP 요청을 보낼 RoundTripper를 지정한다
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.HttpClient.Transport = rt
+	}
+}
+
+// WithTimeout 요청 하나에 걸리는 최대 시간을 지정한다
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.HttpClient.Timeout = timeout
+	}
+}
+
+// WithUserAgent 모든 요청에 User-Agent 헤더를 붙인다
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithRateLimiter 요청 수 제한에 사용할 RateLimiter를 지정한다. nil이면 제한하지 않는다.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) {
+		c.RateLimiter = l
+	}
+}
+
+// WithRetryPolicy 일시적인 오류에 대한 재시도 정책을 지정한다
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = p
+	}
+}
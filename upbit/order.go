@@ -0,0 +1,93 @@
+package upbit
+
+// 주문 상태
+const (
+	OrderStateWait   = "wait"
+	OrderStateWatch  = "watch"
+	OrderStateDone   = "done"
+	OrderStateCancel = "cancel"
+)
+
+// 주문 종류. 최유리 주문(best)은 매수면 주문 금액(price), 매도면 수량(volume)으로 주문하며 time_in_force가 필요하다
+const (
+	OrdTypeLimit  = "limit"
+	OrdTypePrice  = "price"
+	OrdTypeMarket = "market"
+	OrdTypeBest   = "best"
+)
+
+// 주문 조건 (time_in_force). 지정가와 최유리 주문에만 사용할 수 있다
+const (
+	TimeInForceIOC = "ioc" // 즉시 체결 가능한 수량만 체결하고 나머지는 취소
+	TimeInForceFOK = "fok" // 전량 즉시 체결할 수 없으면 주문 전체를 취소
+)
+
+// 자전거래 체결 방지 모드 (smp_type)
+const (
+	SmpCancelMaker = "cancel_maker" // 먼저 낸 주문(메이커)을 취소
+	SmpCancelTaker = "cancel_taker" // 새 주문(테이커)을 취소
+	SmpReduce      = "reduce"       // 겹치는 수량만큼 양쪽 주문 수량을 줄임
+)
+
+// IsAmountOrder 수량 대신 주문 금액(price)으로 주문하는 종류인지 여부 (시장가 매수, 최유리 매수)
+func IsAmountOrder(ordType, side string) bool {
+	return ordType == OrdTypePrice || (ordType == OrdTypeBest && side == "bid")
+}
+
+// ChanceOrdType 주문 가능 정보의 bid_types/ask_types에서 사용하는 주문 종류 이름. e.g. limit, limit_ioc, best_fok
+func ChanceOrdType(ordType, timeInForce string) string {
+	if timeInForce == "" {
+		return ordType
+	}
+	return ordType + "_" + timeInForce
+}
+
+// IsOpen 아직 체결되거나 취소될 수 있는 주문인지 여부
+func (o Order) IsOpen() bool {
+	return o.State == OrderStateWait || o.State == OrderStateWatch
+}
+
+// ExecutedFunds 체결된 금액 합계 (결제 화폐 기준, 수수료 제외). 체결 내역이 있어야 계산된다.
+func (o Order) ExecutedFunds() Decimal {
+	var funds Decimal = "0"
+	for _, t := range o.Trades {
+		funds = funds.Add(t.Funds)
+	}
+	return funds
+}
+
+// SettledFunds 체결 금액. 체결 내역이 없는 조회 결과는 주문 가격과 체결 수량으로 계산하므로
+// 지정가 주문에서만 정확하다.
+func (o Order) SettledFunds() Decimal {
+	if len(o.Trades) > 0 {
+		return o.ExecutedFunds()
+	}
+	return o.Price.Mul(o.ExecutedVolume)
+}
+
+// AvgFillPrice 체결 수량으로 가중 평균한 체결 가격. 체결 내역이 없으면 0
+func (o Order) AvgFillPrice() Decimal {
+	var volume Decimal = "0"
+	for _, t := range o.Trades {
+		volume = volume.Add(t.Volume)
+	}
+	if volume.IsZero() {
+		return "0"
+	}
+	return o.ExecutedFunds().Div(volume, 8)
+}
+
+// FilledPercent 주문 수량 중 체결된 비율 (%).
+// 시장가 매수와 최유리 매수는 주문 수량이 없으므로 주문 금액 중 체결된 금액의 비율을 사용한다.
+func (o Order) FilledPercent() float64 {
+	if IsAmountOrder(o.OrdType, o.Side) {
+		if o.Price.Sign() <= 0 {
+			return 0
+		}
+		return o.ExecutedFunds().Float64() / o.Price.Float64() * 100
+	}
+	if o.Volume.Sign() <= 0 {
+		return 0
+	}
+	return o.ExecutedVolume.Float64() / o.Volume.Float64() * 100
+}
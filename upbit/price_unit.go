@@ -0,0 +1,86 @@
+package upbit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// priceBand 가격 구간별 호가 단위. min 이상인 가격에 unit을 적용한다.
+type priceBand struct {
+	min  Decimal
+	unit Decimal
+}
+
+// priceUnitTables 결제 화폐별 호가 단위표. 높은 가격 구간부터 나열한다.
+var priceUnitTables = map[string][]priceBand{
+	"KRW": {
+		{min: "2000000", unit: "1000"},
+		{min: "1000000", unit: "500"},
+		{min: "500000", unit: "100"},
+		{min: "100000", unit: "50"},
+		{min: "10000", unit: "10"},
+		{min: "1000", unit: "1"},
+		{min: "100", unit: "0.1"},
+		{min: "10", unit: "0.01"},
+		{min: "1", unit: "0.001"},
+		{min: "0.1", unit: "0.0001"},
+		{min: "0.01", unit: "0.00001"},
+		{min: "0.001", unit: "0.000001"},
+		{min: "0.0001", unit: "0.0000001"},
+		{min: "0", unit: "0.00000001"},
+	},
+	"BTC": {
+		{min: "0", unit: "0.00000001"},
+	},
+	"USDT": {
+		{min: "10", unit: "0.01"},
+		{min: "1", unit: "0.001"},
+		{min: "0.1", unit: "0.0001"},
+		{min: "0.01", unit: "0.00001"},
+		{min: "0.001", unit: "0.000001"},
+		{min: "0.0001", unit: "0.0000001"},
+		{min: "0", unit: "0.00000001"},
+	},
+}
+
+// PriceUnit 마켓(KRW-BTC 등)과 가격에 해당하는 호가 단위
+func PriceUnit(market string, price Decimal) (Decimal, error) {
+	quote, _, _ := strings.Cut(market, "-")
+	table, ok := priceUnitTables[quote]
+	if !ok {
+		return "", fmt.Errorf("unsupported quote currency %q in market %q", quote, market)
+	}
+
+	for _, band := range table {
+		if !price.LessThan(band.min) {
+			return band.unit, nil
+		}
+	}
+	return table[len(table)-1].unit, nil
+}
+
+// NormalizePrice 가격을 호가 단위에 맞춘다.
+// 구간 경계를 넘어 올림되면 결과는 경계값이 되는데, 경계값은 위 구간의 호가 단위로도 나누어 떨어진다.
+func NormalizePrice(market string, price Decimal, mode RoundingMode) (Decimal, error) {
+	if price.Sign() <= 0 {
+		return "", fmt.Errorf("price must be positive: %s", price)
+	}
+
+	unit, err := PriceUnit(market, price)
+	if err != nil {
+		return "", err
+	}
+
+	normalized := price.RoundToStep(unit, mode)
+	if normalized.IsZero() {
+		// 최소 호가 단위보다 작은 가격을 버림한 경우
+		normalized = unit
+	}
+	return normalized, nil
+}
+
+// IsValidPrice 가격이 호가 단위에 맞는지 여부
+func IsValidPrice(market string, price Decimal) bool {
+	normalized, err := NormalizePrice(market, price, RoundDown)
+	return err == nil && normalized.Equal(price)
+}
@@ -0,0 +1,128 @@
+package upbit
+
+import "testing"
+
+func TestPriceUnit(t *testing.T) {
+	tests := []struct {
+		market string
+		price  Decimal
+		want   Decimal
+	}{
+		// KRW 마켓: 각 구간의 경계값과 바로 아래 값
+		{"KRW-BTC", "150000000", "1000"},
+		{"KRW-BTC", "2000000", "1000"},
+		{"KRW-BTC", "1999999", "500"},
+		{"KRW-BTC", "1000000", "500"},
+		{"KRW-BTC", "999999", "100"},
+		{"KRW-BTC", "500000", "100"},
+		{"KRW-BTC", "499999", "50"},
+		{"KRW-BTC", "100000", "50"},
+		{"KRW-BTC", "99999", "10"},
+		{"KRW-BTC", "10000", "10"},
+		{"KRW-BTC", "9999", "1"},
+		{"KRW-BTC", "1000", "1"},
+		{"KRW-BTC", "999.9", "0.1"},
+		{"KRW-BTC", "100", "0.1"},
+		{"KRW-BTC", "99.99", "0.01"},
+		{"KRW-BTC", "10", "0.01"},
+		{"KRW-BTC", "9.999", "0.001"},
+		{"KRW-BTC", "1", "0.001"},
+		{"KRW-BTC", "0.9999", "0.0001"},
+		{"KRW-BTC", "0.1", "0.0001"},
+		{"KRW-BTC", "0.09999", "0.00001"},
+		{"KRW-BTC", "0.01", "0.00001"},
+		{"KRW-BTC", "0.001", "0.000001"},
+		{"KRW-BTC", "0.0001", "0.0000001"},
+		{"KRW-BTC", "0.00009", "0.00000001"},
+		// BTC 마켓은 가격과 관계없이 0.00000001
+		{"BTC-ETH", "0.05", "0.00000001"},
+		{"BTC-ETH", "10", "0.00000001"},
+		// USDT 마켓
+		{"USDT-BTC", "65000", "0.01"},
+		{"USDT-BTC", "10", "0.01"},
+		{"USDT-BTC", "9.999", "0.001"},
+		{"USDT-BTC", "1", "0.001"},
+		{"USDT-BTC", "0.9999", "0.0001"},
+		{"USDT-BTC", "0.1", "0.0001"},
+		{"USDT-BTC", "0.01", "0.00001"},
+		{"USDT-BTC", "0.001", "0.000001"},
+		{"USDT-BTC", "0.0001", "0.0000001"},
+		{"USDT-BTC", "0.00001", "0.00000001"},
+	}
+	for _, tt := range tests {
+		got, err := PriceUnit(tt.market, tt.price)
+		if err != nil || got != tt.want {
+			t.Errorf("PriceUnit(%s, %s) = %s, %v, want %s", tt.market, tt.price, got, err, tt.want)
+		}
+	}
+
+	if _, err := PriceUnit("ETH-BTC", "1"); err == nil {
+		t.Error("PriceUnit with unsupported quote currency succeeded")
+	}
+}
+
+func TestNormalizePrice(t *testing.T) {
+	tests := []struct {
+		market  string
+		price   Decimal
+		mode    RoundingMode
+		want    Decimal
+		wantErr bool
+	}{
+		{market: "KRW-BTC", price: "95001234", mode: RoundDown, want: "95001000"},
+		{market: "KRW-BTC", price: "95001234", mode: RoundUp, want: "95002000"},
+		{market: "KRW-BTC", price: "95001500", mode: RoundNearest, want: "95002000"},
+		{market: "KRW-XRP", price: "812.34", mode: RoundNearest, want: "812.3"},
+		{market: "KRW-XRP", price: "1234.5", mode: RoundDown, want: "1234"},
+		// 구간 경계를 넘어 올림되면 경계값이 되고, 경계값은 위 구간 단위로도 맞다
+		{market: "KRW-BTC", price: "1999999", mode: RoundUp, want: "2000000"},
+		{market: "KRW-BTC", price: "99999", mode: RoundUp, want: "100000"},
+		{market: "KRW-BTC", price: "9.9999", mode: RoundUp, want: "10"},
+		{market: "USDT-BTC", price: "65000.123", mode: RoundDown, want: "65000.12"},
+		{market: "USDT-BTC", price: "9.9999", mode: RoundNearest, want: "10"},
+		{market: "BTC-ETH", price: "0.0512345678", mode: RoundNearest, want: "0.05123457"},
+		// 최소 단위보다 작은 가격을 버림하면 최소 단위가 된다
+		{market: "BTC-ETH", price: "0.000000001", mode: RoundDown, want: "0.00000001"},
+		{market: "KRW-BTC", price: "0", mode: RoundDown, wantErr: true},
+		{market: "KRW-BTC", price: "-1000", mode: RoundDown, wantErr: true},
+		{market: "EUR-BTC", price: "1000", mode: RoundDown, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePrice(tt.market, tt.price, tt.mode)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizePrice(%s, %s) = %s, want error", tt.market, tt.price, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePrice(%s, %s, %d) = %s, %v, want %s", tt.market, tt.price, tt.mode, got, err, tt.want)
+		}
+	}
+}
+
+func TestIsValidPrice(t *testing.T) {
+	tests := []struct {
+		market string
+		price  Decimal
+		want   bool
+	}{
+		{"KRW-BTC", "95001000", true},
+		{"KRW-BTC", "95001500", false},
+		{"KRW-BTC", "1999500", true},
+		{"KRW-BTC", "2000500", false},
+		{"KRW-XRP", "812.3", true},
+		{"KRW-XRP", "812.35", false},
+		{"USDT-BTC", "65000.12", true},
+		{"USDT-BTC", "65000.123", false},
+		{"BTC-ETH", "0.05123457", true},
+		{"BTC-ETH", "0.051234567", false},
+		{"KRW-BTC", "0", false},
+		{"EUR-BTC", "1000", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidPrice(tt.market, tt.price); got != tt.want {
+			t.Errorf("IsValidPrice(%s, %s) = %v, want %v", tt.market, tt.price, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package upbit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 업비트 요청 수 제한 그룹
+const (
+	GroupDefault   = "default"
+	GroupOrder     = "order"
+	GroupMarket    = "market"
+	GroupCandle    = "candle"
+	GroupTrade     = "trade"
+	GroupTicker    = "ticker"
+	GroupOrderBook = "orderbook"
+)
+
+// DefaultRateLimits 그룹별 초당 최대 요청 수
+func DefaultRateLimits() map[string]int {
+	return map[string]int{
+		GroupDefault:   30,
+		GroupOrder:     8,
+		GroupMarket:    10,
+		GroupCandle:    10,
+		GroupTrade:     10,
+		GroupTicker:    10,
+		GroupOrderBook: 10,
+	}
+}
+
+// RateLimiter 그룹별 토큰 버킷으로 요청 속도를 조절한다.
+// 토큰이 부족하면 오류를 반환하지 않고 차례가 올 때까지 호출자를 대기시키며,
+// 응답의 Remaining-Req 헤더로 서버가 알려준 남은 요청 수를 반영한다.
+//
+// 업비트는 1초 단위 고정 구간으로 요청 수를 세기 때문에 버킷 크기를 1로 두고
+// 요청 간격을 1/초당 요청 수로 고르게 유지한다. 버킷을 크게 잡으면 구간 경계에서
+// 두 배까지 몰린 요청이 429로 거절된다.
+type RateLimiter struct {
+	mu      sync.Mutex
+	limits  map[string]int
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	// tokens 사용할 수 있는 토큰 수. 대기 중인 호출자가 미리 가져간 만큼 음수가 될 수 있다.
+	tokens       float64
+	rate         float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewRateLimiter 그룹별 초당 요청 수로 RateLimiter 생성. 목록에 없는 그룹은 default 그룹의 제한을 따른다.
+func NewRateLimiter(limits map[string]int) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Wait 그룹의 토큰을 하나 얻을 때까지 대기한다
+func (l *RateLimiter) Wait(ctx context.Context, group string) error {
+	l.mu.Lock()
+	now := time.Now()
+	b := l.bucket(group, now)
+	b.tokens--
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if blocked := b.blockedUntil.Sub(now); blocked > delay {
+		delay = blocked
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// 사용하지 못한 토큰은 돌려준다
+		l.mu.Lock()
+		b.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Update Remaining-Req 헤더의 남은 요청 수를 반영한다
+func (l *RateLimiter) Update(rr *RemainingReq) {
+	if rr == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b := l.bucket(rr.Group, now)
+	if float64(rr.Sec) < b.tokens {
+		b.tokens = float64(rr.Sec)
+	}
+	if rr.Sec <= 0 {
+		l.block(b, now.Truncate(time.Second).Add(time.Second))
+	}
+}
+
+// Backoff 요청 수 제한(429) 응답을 받은 그룹의 요청을 d 만큼 멈춘다
+func (l *RateLimiter) Backoff(group string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.block(l.bucket(group, now), now.Add(d))
+}
+
+func (l *RateLimiter) block(b *bucket, until time.Time) {
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// bucket 그룹의 버킷을 가져오고 지난 시간만큼 토큰을 채운다
+func (l *RateLimiter) bucket(group string, now time.Time) *bucket {
+	b, ok := l.buckets[group]
+	if !ok {
+		limit, ok := l.limits[group]
+		if !ok {
+			limit = l.limits[GroupDefault]
+		}
+		if limit <= 0 {
+			limit = 1
+		}
+		b = &bucket{tokens: 1, rate: float64(limit), last: now}
+		l.buckets[group] = b
+		return b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > 1 {
+		b.tokens = 1
+	}
+	b.last = now
+	return b
+}
+
+// rateLimitGroup 엔드포인트가 속한 요청 수 제한 그룹
+func rateLimitGroup(method, endpoint string) string {
+	switch {
+	case method == http.MethodPost && endpoint == "orders":
+		return GroupOrder
+	case strings.HasPrefix(endpoint, "market/"):
+		return GroupMarket
+	case strings.HasPrefix(endpoint, "candles/"):
+		return GroupCandle
+	case strings.HasPrefix(endpoint, "trades/"):
+		return GroupTrade
+	case endpoint == "ticker":
+		return GroupTicker
+	case endpoint == "orderbook":
+		return GroupOrderBook
+	default:
+		return GroupDefault
+	}
+}
@@ -0,0 +1,187 @@
+package upbit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRemainingReq(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *RemainingReq
+	}{
+		{header: "group=default; min=1800; sec=29", want: &RemainingReq{Group: "default", Min: 1800, Sec: 29}},
+		{header: "group=order;min=480;sec=0", want: &RemainingReq{Group: "order", Min: 480, Sec: 0}},
+		{header: "group=market; sec=9", want: &RemainingReq{Group: "market", Sec: 9}},
+		{header: ""},
+		{header: "group=default; min=1800; sec=abc"},
+		{header: "min=1800; sec=29"},
+		{header: "group default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := ParseRemainingReq(tt.header)
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("ParseRemainingReq(%q) = %+v, want failure", tt.header, got)
+				}
+				return
+			}
+			if !ok || *got != *tt.want {
+				t.Fatalf("ParseRemainingReq(%q) = %+v, %v, want %+v", tt.header, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitGroup(t *testing.T) {
+	tests := []struct {
+		method   string
+		endpoint string
+		want     string
+	}{
+		{http.MethodPost, "orders", GroupOrder},
+		{http.MethodGet, "orders/open", GroupDefault},
+		{http.MethodGet, "orders/chance", GroupDefault},
+		{http.MethodDelete, "order", GroupDefault},
+		{http.MethodGet, "market/all", GroupMarket},
+		{http.MethodGet, "candles/minutes/1", GroupCandle},
+		{http.MethodGet, "trades/ticks", GroupTrade},
+		{http.MethodGet, "ticker", GroupTicker},
+		{http.MethodGet, "orderbook", GroupOrderBook},
+		{http.MethodGet, "accounts", GroupDefault},
+	}
+	for _, tt := range tests {
+		if got := rateLimitGroup(tt.method, tt.endpoint); got != tt.want {
+			t.Errorf("rateLimitGroup(%s, %s) = %s, want %s", tt.method, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+// elapsed n번 Wait하는 데 걸린 시간
+func elapsed(t *testing.T, l *RateLimiter, group string, n int) time.Duration {
+	t.Helper()
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if err := l.Wait(context.Background(), group); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return time.Since(start)
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits map[string]int
+		group  string
+		n      int
+		min    time.Duration
+		max    time.Duration
+	}{
+		// 버킷 크기가 1이므로 첫 요청만 바로 나가고 이후는 1/rate 간격으로 나간다
+		{name: "첫 요청은 대기 없음", limits: map[string]int{GroupDefault: 10}, group: GroupDefault, n: 1, max: 20 * time.Millisecond},
+		{name: "초당 10회", limits: map[string]int{GroupDefault: 10}, group: GroupDefault, n: 4, min: 280 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "목록에 없는 그룹은 default 제한", limits: map[string]int{GroupDefault: 20}, group: "unknown", n: 3, min: 90 * time.Millisecond, max: 200 * time.Millisecond},
+		{name: "0 이하의 제한은 초당 1회", limits: map[string]int{GroupDefault: 50, GroupOrder: 0}, group: GroupOrder, n: 2, min: 950 * time.Millisecond, max: 1200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewRateLimiter(tt.limits)
+			d := elapsed(t, l, tt.group, tt.n)
+			if d < tt.min || d > tt.max {
+				t.Errorf("%d waits took %v, want between %v and %v", tt.n, d, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRateLimiterGroupsAreIndependent(t *testing.T) {
+	l := NewRateLimiter(map[string]int{GroupDefault: 1, GroupOrder: 1})
+	if err := l.Wait(context.Background(), GroupDefault); err != nil {
+		t.Fatal(err)
+	}
+	// 다른 그룹의 토큰은 남아 있으므로 기다리지 않는다
+	if d := elapsed(t, l, GroupOrder, 1); d > 20*time.Millisecond {
+		t.Errorf("order group waited %v after default group was used", d)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		rr   *RemainingReq
+		min  time.Duration
+		max  time.Duration
+	}{
+		{name: "nil은 무시", rr: nil, max: 20 * time.Millisecond},
+		{name: "남은 요청이 있으면 대기 없음", rr: &RemainingReq{Group: GroupDefault, Sec: 5}, max: 20 * time.Millisecond},
+		// 남은 요청이 0이면 현재 1초 구간이 끝날 때까지 멈춘다
+		{name: "남은 요청 0", rr: &RemainingReq{Group: GroupDefault, Sec: 0}, min: time.Millisecond, max: 1050 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewRateLimiter(map[string]int{GroupDefault: 1000})
+			now := time.Now()
+			next := now.Truncate(time.Second).Add(time.Second)
+			l.Update(tt.rr)
+			d := elapsed(t, l, GroupDefault, 1)
+			if tt.min > 0 {
+				// 구간 경계까지 남은 시간만큼은 기다려야 한다
+				if want := next.Sub(now) - 10*time.Millisecond; d < want {
+					t.Errorf("waited %v, want at least %v", d, want)
+				}
+			}
+			if d > tt.max {
+				t.Errorf("waited %v, want at most %v", d, tt.max)
+			}
+		})
+	}
+}
+
+func TestRateLimiterUpdateLowersTokens(t *testing.T) {
+	l := NewRateLimiter(map[string]int{GroupDefault: 10})
+	// 서버가 남은 요청이 1회라고 알려주면 다음 요청은 바로 나가고 그다음은 간격을 둔다
+	l.Update(&RemainingReq{Group: GroupDefault, Sec: 1})
+	if d := elapsed(t, l, GroupDefault, 2); d < 80*time.Millisecond {
+		t.Errorf("2 waits took %v, want at least 80ms", d)
+	}
+}
+
+func TestRateLimiterBackoff(t *testing.T) {
+	l := NewRateLimiter(map[string]int{GroupDefault: 1000})
+	l.Backoff(GroupDefault, 150*time.Millisecond)
+	if d := elapsed(t, l, GroupDefault, 1); d < 140*time.Millisecond || d > 300*time.Millisecond {
+		t.Errorf("waited %v after backoff, want about 150ms", d)
+	}
+	// 더 짧은 Backoff는 이미 정해진 대기 시간을 줄이지 않는다
+	l.Backoff(GroupOrder, 150*time.Millisecond)
+	l.Backoff(GroupOrder, 10*time.Millisecond)
+	if d := elapsed(t, l, GroupOrder, 1); d < 140*time.Millisecond {
+		t.Errorf("shorter backoff shortened the wait to %v", d)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := NewRateLimiter(map[string]int{GroupDefault: 1})
+	if err := l.Wait(context.Background(), GroupDefault); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, GroupDefault); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want deadline exceeded", err)
+	}
+
+	// 취소된 호출자가 가져간 토큰은 돌려받으므로 대기 시간이 늘어나지 않는다
+	l.mu.Lock()
+	tokens := l.buckets[GroupDefault].tokens
+	l.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("tokens = %v after cancel, want the reserved token returned", tokens)
+	}
+}
@@ -0,0 +1,78 @@
+package upbit
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 일시적인 오류(네트워크 오류, 429, 5xx)에 대한 재시도 정책.
+// 조회 요청은 항상 재시도하고, 주문(POST orders)은 identifier가 지정되어
+// 중복 주문이 거절될 수 있을 때만 재시도한다.
+type RetryPolicy struct {
+	// MaxAttempts 첫 요청을 포함한 최대 시도 횟수. 1 이하이면 재시도하지 않는다.
+	MaxAttempts int
+	// BaseDelay 첫 재시도 대기 시간의 상한. 시도할 때마다 두 배로 늘어난다.
+	BaseDelay time.Duration
+	// MaxDelay 재시도 대기 시간의 최대값. 서버가 Retry-After로 이보다 오래
+	// 기다리라고 하면 재시도하지 않고 그 응답의 오류를 반환한다.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy 기본 재시도 정책
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// backoff attempt번째 재시도 전 대기 시간. 지수 백오프에 full jitter를 적용하고,
+// 서버가 Retry-After를 알려주면 그보다 짧게 기다리지 않는다.
+// retryAfter가 MaxDelay를 넘는 경우는 send가 재시도하지 않으므로 여기까지 오지 않는다.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	ceiling := p.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+
+	var delay time.Duration
+	if ceiling > 0 {
+		delay = rand.N(ceiling)
+	}
+	return max(delay, retryAfter)
+}
+
+// shouldRetry 요청을 다시 보내도 되는 오류인지 판단
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// 요청 생성 실패 등은 재시도해도 같은 결과이므로 전송 중 발생한 오류만 재시도
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter Retry-After 헤더 파싱 (초 또는 HTTP 날짜)
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package upbit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		attempt    int
+		retryAfter time.Duration
+		min, max   time.Duration
+	}{
+		// full jitter: [0, BaseDelay * 2^attempt) 에서 고르고 MaxDelay를 넘지 않는다
+		{name: "첫 재시도", policy: p, attempt: 0, max: 100 * time.Millisecond},
+		{name: "두 번째 재시도", policy: p, attempt: 1, max: 200 * time.Millisecond},
+		{name: "세 번째 재시도", policy: p, attempt: 2, max: 400 * time.Millisecond},
+		{name: "MaxDelay 상한", policy: p, attempt: 10, max: time.Second},
+		{name: "시프트 오버플로", policy: p, attempt: 70, max: time.Second},
+		// Retry-After보다 짧게 기다리지 않는다
+		{name: "Retry-After가 더 김", policy: p, attempt: 0, retryAfter: 800 * time.Millisecond, min: 800 * time.Millisecond, max: 800 * time.Millisecond},
+		{name: "Retry-After가 더 짧음", policy: p, attempt: 3, retryAfter: 50 * time.Millisecond, min: 50 * time.Millisecond, max: 800 * time.Millisecond},
+		{name: "대기 시간 0", policy: RetryPolicy{MaxAttempts: 3}, attempt: 2, max: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				d := tt.policy.backoff(tt.attempt, tt.retryAfter)
+				if d < tt.min || d > tt.max {
+					t.Fatalf("backoff(%d, %v) = %v, want between %v and %v", tt.attempt, tt.retryAfter, d, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffIsJittered(t *testing.T) {
+	p := DefaultRetryPolicy()
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 50; i++ {
+		seen[p.backoff(2, 0)] = true
+	}
+	if len(seen) < 10 {
+		t.Errorf("backoff returned only %d distinct delays, want jitter", len(seen))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		min, max time.Duration
+	}{
+		{name: "없음", header: ""},
+		{name: "초", header: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{name: "0초", header: "0"},
+		{name: "HTTP 날짜", header: time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat), min: 3 * time.Second, max: 5 * time.Second},
+		{name: "지난 날짜", header: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+		{name: "잘못된 값", header: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := parseRetryAfter(tt.header); d < tt.min || d > tt.max {
+				t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", tt.header, d, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{name: "429", ctx: context.Background(), resp: &http.Response{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "500", ctx: context.Background(), resp: &http.Response{StatusCode: http.StatusInternalServerError}, want: true},
+		{name: "503", ctx: context.Background(), resp: &http.Response{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "200", ctx: context.Background(), resp: &http.Response{StatusCode: http.StatusOK}},
+		{name: "400", ctx: context.Background(), resp: &http.Response{StatusCode: http.StatusBadRequest}},
+		{name: "401", ctx: context.Background(), resp: &http.Response{StatusCode: http.StatusUnauthorized}},
+		{name: "전송 오류", ctx: context.Background(), err: &url.Error{Op: "Get", URL: "https://api.upbit.com", Err: errors.New("connection reset")}, want: true},
+		{name: "요청 생성 오류", ctx: context.Background(), err: errors.New("invalid request")},
+		{name: "취소된 context", ctx: canceled, resp: &http.Response{StatusCode: http.StatusTooManyRequests}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldRetry(tt.ctx, tt.resp, tt.err); got != tt.want {
+				t.Errorf("shouldRetry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first, second time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		second = time.Now()
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient("access", "secret",
+		WithBaseURL(server.URL+"/v1/"),
+		WithRateLimiter(nil),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}),
+	)
+	if _, err := client.GetAccounts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+	// BaseDelay가 짧아도 Retry-After만큼 기다린다
+	if d := second.Sub(first); d < 950*time.Millisecond {
+		t.Errorf("retried after %v, want at least Retry-After (1s)", d)
+	}
+}
+
+func TestSendGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient("access", "secret",
+		WithBaseURL(server.URL+"/v1/"),
+		WithRateLimiter(nil),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}),
+	)
+	_, err := client.GetAccounts(context.Background())
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Name != ErrServerError {
+		t.Fatalf("err = %v, want 502 %s", err, ErrServerError)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+}
+
+func TestSendGivesUpWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"name":"too_many_requests","message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("access", "secret",
+		WithBaseURL(server.URL+"/v1/"),
+		WithRateLimiter(nil),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+	)
+	start := time.Now()
+	_, err := client.GetAccounts(context.Background())
+
+	// 60초를 기다리지 않고 429 응답의 오류를 바로 반환한다
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want 429", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("returned after %v", d)
+	}
+}
@@ -0,0 +1,48 @@
+package upbit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Trader 계좌 조회와 주문 기능.
+// *Client는 실제 업비트 계좌로, paper.Exchange는 가상 원장으로 주문을 처리한다.
+type Trader interface {
+	GetAccounts(ctx context.Context) ([]Account, error)
+	GetChance(ctx context.Context, market string) (Chance, error)
+	PlaceOrder(ctx context.Context, params RequestParams) (Order, error)
+	GetOrder(ctx context.Context, uuid string) (Order, error)
+	GetOrderByIdentifier(ctx context.Context, identifier string) (Order, error)
+	GetOpenOrders(ctx context.Context, params RequestParams) ([]Order, error)
+	GetOrderHistory(ctx context.Context, params RequestParams) ([]Order, error)
+	CancelOrder(ctx context.Context, uuid string) (bool, error)
+	CancelOrderByIdentifier(ctx context.Context, identifier string) (bool, error)
+}
+
+var _ Trader = (*Client)(nil)
+
+// WaitOrderClosed 주문이 체결되거나 취소되어 더 이상 대기 상태가 아닐 때까지 interval마다 조회한다.
+// 업비트는 취소 요청을 비동기로 처리하므로 취소 직후의 남은 수량은 닫힌 뒤에 조회한 값을 써야 한다.
+func WaitOrderClosed(ctx context.Context, trader Trader, uuid string, timeout, interval time.Duration) (Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		order, err := trader.GetOrder(ctx, uuid)
+		if err != nil {
+			return Order{}, err
+		}
+		if !order.IsOpen() {
+			return order, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return Order{}, fmt.Errorf("order %s is still %s: %w", uuid, order.State, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,6 +2,7 @@ package upbit
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
@@ -14,6 +15,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -116,11 +118,11 @@ func (c *Client) generateToken(queryParams map[string]string) (string, error) {
 }
 
 // doRequest: 실제 요청 수행
-func (c *Client) doRequest(method, endpoint string, params interface{}, result interface{}) error {
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, params interface{}, result interface{}) error {
 	paramMap := structToMap(params)
 
-	var body io.Reader
-	urlString := BaseURL + endpoint
+	var jsonBytes []byte
+	urlString := c.BaseURL + endpoint
 
 	// GET/DELETE는 쿼리 스트링에 파라미터 추가
 	if method == http.MethodGet || method == http.MethodDelete {
@@ -134,30 +136,45 @@ func (c *Client) doRequest(method, endpoint string, params interface{}, result i
 	} else {
 		// POST는 JSON Body 사용
 		if params != nil {
-			jsonBytes, err := json.Marshal(paramMap)
+			var err error
+			jsonBytes, err = json.Marshal(paramMap)
 			if err != nil {
 				return err
 			}
-			body = bytes.NewBuffer(jsonBytes)
 		}
 	}
 
-	req, err := http.NewRequest(method, urlString, body)
-	if err != nil {
-		return err
-	}
+	// 재시도마다 nonce가 다른 토큰이 필요하므로 요청을 매번 새로 만든다
+	newRequest := func() (*http.Request, error) {
+		var body io.Reader
+		if jsonBytes != nil {
+			body = bytes.NewReader(jsonBytes)
+		}
 
-	req.Header.Add("Content-Type", "application/json")
+		req, err := http.NewRequestWithContext(ctx, method, urlString, body)
+		if err != nil {
+			return nil, err
+		}
 
-	// 인증 토큰 추가 (Auth가 필요한 경우)
-	// paramMap은 Hash 생성을 위해 사용됨
-	token, err := c.generateToken(paramMap)
-	if err != nil {
-		return err
+		req.Header.Add("Content-Type", "application/json")
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+
+		// 인증 토큰 추가 (Auth가 필요한 경우)
+		// paramMap은 Hash 생성을 위해 사용됨
+		token, err := c.generateToken(paramMap)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Authorization", "Bearer "+token)
+		return req, nil
 	}
-	req.Header.Add("Authorization", "Bearer "+token)
 
-	resp, err := c.HttpClient.Do(req)
+	// 조회는 언제든 재시도할 수 있지만, 주문은 identifier가 있어야 중복 주문 없이 재시도할 수 있다
+	retry := method == http.MethodGet || (method == http.MethodPost && paramMap["identifier"] != "")
+
+	resp, err := c.send(ctx, newRequest, rateLimitGroup(method, endpoint), retry)
 	if err != nil {
 		return err
 	}
@@ -166,7 +183,7 @@ func (c *Client) doRequest(method, endpoint string, params interface{}, result i
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("API error status: %d, body: %s", resp.StatusCode, string(respBody))
+		return newAPIError(resp, respBody)
 	}
 
 	// Result가 nil이 아니고 포인터일 때만 언마샬링
@@ -180,9 +197,9 @@ func (c *Client) doRequest(method, endpoint string, params interface{}, result i
 }
 
 // doNonAuthRequest: 인증이 필요 없는 요청 (시세 조회 등)
-func (c *Client) doNonAuthRequest(endpoint string, params interface{}, result interface{}) error {
+func (c *Client) doNonAuthRequest(ctx context.Context, endpoint string, params interface{}, result interface{}) error {
 	paramMap := structToMap(params)
-	urlString := BaseURL + endpoint
+	urlString := c.BaseURL + endpoint
 
 	if len(paramMap) > 0 {
 		q := url.Values{}
@@ -192,13 +209,19 @@ func (c *Client) doNonAuthRequest(endpoint string, params interface{}, result in
 		urlString += "?" + q.Encode()
 	}
 
-	req, err := http.NewRequest(http.MethodGet, urlString, nil)
-	if err != nil {
-		return err
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlString, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Content-Type", "application/json")
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+		return req, nil
 	}
-	req.Header.Add("Content-Type", "application/json")
 
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.send(ctx, newRequest, rateLimitGroup(http.MethodGet, endpoint), true)
 	if err != nil {
 		return err
 	}
@@ -206,7 +229,7 @@ func (c *Client) doNonAuthRequest(endpoint string, params interface{}, result in
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error status: %d, body: %s", resp.StatusCode, string(respBody))
+		return newAPIError(resp, respBody)
 	}
 
 	if result != nil {
@@ -214,3 +237,65 @@ func (c *Client) doNonAuthRequest(endpoint string, params interface{}, result in
 	}
 	return nil
 }
+
+// send: 요청 수 제한을 지키며 요청을 보낸다.
+// retry가 true이면 네트워크 오류와 429/5xx 응답을 RetryPolicy에 따라 재시도한다.
+func (c *Client) send(ctx context.Context, newRequest func() (*http.Request, error), group string, retry bool) (*http.Response, error) {
+	attempts := 1
+	if retry && c.RetryPolicy.MaxAttempts > 1 {
+		attempts = c.RetryPolicy.MaxAttempts
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.sendOnce(ctx, newRequest, group)
+		if attempt+1 >= attempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			if retryAfter > c.RetryPolicy.MaxDelay {
+				// 허용한 대기 시간 안에 다시 보낼 수 없으므로 이 응답의 오류를 돌려준다
+				return resp, err
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(c.RetryPolicy.backoff(attempt, retryAfter))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// sendOnce: 요청 수 제한을 지키며 요청을 한 번 보내고, 응답의 Remaining-Req 헤더를 반영
+func (c *Client) sendOnce(ctx context.Context, newRequest func() (*http.Request, error), group string) (*http.Response, error) {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx, group); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil || c.RateLimiter == nil {
+		return resp, err
+	}
+
+	if rr, ok := ParseRemainingReq(resp.Header.Get("Remaining-Req")); ok {
+		c.RateLimiter.Update(rr)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.RateLimiter.Backoff(group, time.Second)
+	}
+	return resp, nil
+}
@@ -0,0 +1,273 @@
+package upbittest
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+	"upbit-mcp-server/internal/ledger"
+	"upbit-mcp-server/upbit"
+)
+
+// DefaultFee 기본 주문 수수료율
+const DefaultFee upbit.Decimal = "0.0005"
+
+// Exchange 테스트용 인메모리 업비트 거래소.
+// 실제 REST API와 같은 경로와 JWT 인증 방식을 제공하므로 upbit.Client를
+// WithBaseURL(e.URL())로 생성하면 실제 자금 없이 주문 흐름 전체를 확인할 수 있다.
+//
+// 주문은 호가창이 설정된 마켓에서는 호가창을, 그렇지 않은 마켓에서는 현재가를
+// 무제한 유동성으로 간주해 체결된다. 체결되지 않은 지정가 주문은 SetPrice나
+// SetOrderBook으로 시세가 바뀔 때 다시 매칭된다.
+type Exchange struct {
+	AccessKey string
+	SecretKey string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	bidFee   upbit.Decimal
+	askFee   upbit.Decimal
+	minTotal map[string]upbit.Decimal
+	markets  map[string]upbit.MarketInfo
+	tickers  map[string]upbit.Ticker
+	books    map[string]upbit.OrderBook
+	candles  map[string][]upbit.Candle
+	ledger   *ledger.Ledger
+	nonces   map[string]bool
+	failures []Failure
+	requests map[string]int
+	limits   map[string]int
+	windows  map[string]*window
+}
+
+// window 그룹별 초당 요청 수 집계
+type window struct {
+	second int64
+	count  int
+}
+
+// Failure FailNext로 예약하는 오류 응답
+type Failure struct {
+	Method  string // 비어있으면 모든 메서드
+	Path    string // "/v1/" 이후의 경로. e.g. "orders", "ticker"
+	Status  int
+	Name    string
+	Message string
+	Header  http.Header
+}
+
+// NewExchange 테스트 거래소를 생성하고 HTTP 서버를 시작한다. 사용이 끝나면 Close를 호출해야 한다.
+func NewExchange(accessKey, secretKey string) *Exchange {
+	e := &Exchange{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		bidFee:    DefaultFee,
+		askFee:    DefaultFee,
+		minTotal:  map[string]upbit.Decimal{"KRW": "5000", "BTC": "0.00005", "USDT": "0.5"},
+		markets:   map[string]upbit.MarketInfo{},
+		tickers:   map[string]upbit.Ticker{},
+		books:     map[string]upbit.OrderBook{},
+		candles:   map[string][]upbit.Candle{},
+		ledger:    ledger.New(),
+		nonces:    map[string]bool{},
+		requests:  map[string]int{},
+		limits:    upbit.DefaultRateLimits(),
+		windows:   map[string]*window{},
+	}
+	e.server = httptest.NewServer(e.routes())
+	return e
+}
+
+// URL upbit.WithBaseURL에 전달할 API 주소
+func (e *Exchange) URL() string {
+	return e.server.URL + "/v1/"
+}
+
+// Client 테스트 거래소를 바라보는 클라이언트를 생성한다
+func (e *Exchange) Client(opts ...upbit.Option) *upbit.Client {
+	opts = append([]upbit.Option{upbit.WithBaseURL(e.URL())}, opts...)
+	return upbit.NewClient(e.AccessKey, e.SecretKey, opts...)
+}
+
+// Close HTTP 서버 종료
+func (e *Exchange) Close() {
+	e.server.Close()
+}
+
+// SetFees 매수/매도 수수료율 변경
+func (e *Exchange) SetFees(bidFee, askFee upbit.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.bidFee, e.askFee = bidFee, askFee
+}
+
+// SetMinTotal 결제 화폐별 최소 주문 금액 변경
+func (e *Exchange) SetMinTotal(currency string, minTotal upbit.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.minTotal[currency] = minTotal
+}
+
+// AddMarket 거래 가능한 마켓 추가
+func (e *Exchange) AddMarket(m upbit.MarketInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.markets[m.Market] = m
+}
+
+// SetAccount 계좌 잔고 설정
+func (e *Exchange) SetAccount(a upbit.Account) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w := e.ledger.Wallet(a.Currency)
+	w.Balance = orZero(a.Balance)
+	w.Locked = orZero(a.Locked)
+	w.AvgBuyPrice = orZero(a.AvgBuyPrice)
+	if a.UnitCurrency != "" {
+		w.UnitCurrency = a.UnitCurrency
+	}
+}
+
+// SetBalance 주문 가능 잔고만 간단히 설정
+func (e *Exchange) SetBalance(currency string, balance upbit.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ledger.Wallet(currency).Balance = orZero(balance)
+}
+
+// SetTicker 현재가 정보를 설정하고 대기 중인 주문을 다시 매칭한다
+func (e *Exchange) SetTicker(t upbit.Ticker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tickers[t.Market] = t
+	e.matchResting(t.Market)
+}
+
+// SetPrice 현재가만 변경하고 대기 중인 주문을 다시 매칭한다
+func (e *Exchange) SetPrice(market string, price upbit.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.tickers[market]
+	if !ok {
+		t = upbit.Ticker{Market: market, OpeningPrice: price, HighPrice: price, LowPrice: price, PrevClosingPrice: price}
+	}
+	t.TradePrice = price
+	t.HighPrice = upbit.MaxDecimal(t.HighPrice, price)
+	t.LowPrice = upbit.MinDecimal(t.LowPrice, price)
+	if t.PrevClosingPrice.Sign() > 0 {
+		t.SignedChangePrice = price.Sub(t.PrevClosingPrice)
+		t.SignedChangeRate = t.SignedChangePrice.Float64() / t.PrevClosingPrice.Float64()
+		t.ChangePrice = t.SignedChangePrice.Abs()
+		t.ChangeRate = math.Abs(t.SignedChangeRate)
+	}
+	switch t.SignedChangePrice.Sign() {
+	case 1:
+		t.Change = "RISE"
+	case -1:
+		t.Change = "FALL"
+	default:
+		t.Change = "EVEN"
+	}
+	now := time.Now()
+	t.Timestamp = now.UnixMilli()
+	t.TradeTimestamp = now.UnixMilli()
+	e.tickers[market] = t
+	e.matchResting(market)
+}
+
+// SetOrderBook 호가창을 설정하고 대기 중인 주문을 다시 매칭한다
+func (e *Exchange) SetOrderBook(b upbit.OrderBook) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.books[b.Market] = b
+	e.matchResting(b.Market)
+}
+
+// SetCandles 캔들 데이터 설정. 실제 API처럼 최신 캔들이 앞에 오도록 전달해야 한다.
+// key는 "days/KRW-BTC", "minutes/1/KRW-BTC" 형식
+func (e *Exchange) SetCandles(key string, candles []upbit.Candle) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.candles[key] = candles
+}
+
+// SetRateLimit 그룹의 초당 요청 수 제한 변경. 0이면 제한하지 않는다.
+func (e *Exchange) SetRateLimit(group string, perSecond int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.limits[group] = perSecond
+}
+
+// FailNext 조건에 맞는 다음 요청 하나에 지정한 오류를 응답한다
+func (e *Exchange) FailNext(f Failure) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures = append(e.failures, f)
+}
+
+// RequestCount 지금까지 받은 요청 수. e.g. RequestCount("POST", "orders")
+func (e *Exchange) RequestCount(method, path string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.requests[method+" "+path]
+}
+
+// Account 현재 계좌 상태 조회
+func (e *Exchange) Account(currency string) upbit.Account {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ledger.Wallet(currency).Account(currency)
+}
+
+// Order 주문 상태 조회
+func (e *Exchange) Order(uuid string) (upbit.Order, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, ok := e.ledger.Order(uuid)
+	if !ok {
+		return upbit.Order{}, false
+	}
+	return o.Detail(), true
+}
+
+func (e *Exchange) feeRate(side string) upbit.Decimal {
+	if side == "bid" {
+		return e.bidFee
+	}
+	return e.askFee
+}
+
+// liquidity 주문 방향에 맞는 상대 호가를 유리한 가격 순으로 반환.
+// 호가창이 없으면 현재가를 무제한 유동성으로 본다.
+func (e *Exchange) liquidity(market, side string) []ledger.Level {
+	if book, ok := e.books[market]; ok && len(book.OrderbookUnits) > 0 {
+		return ledger.BookLevels(book, side)
+	}
+	if t, ok := e.tickers[market]; ok && t.TradePrice.Sign() > 0 {
+		return []ledger.Level{{Price: t.TradePrice, Unlimited: true}}
+	}
+	return nil
+}
+
+// match 주문을 현재 유동성에 매칭한다. 설정한 호가는 체결해도 줄어들지 않는다
+func (e *Exchange) match(o *ledger.Order) {
+	e.ledger.Match(o, e.liquidity(o.Market, o.Side), e.feeRate(o.Side), false)
+}
+
+func (e *Exchange) matchResting(market string) {
+	for _, o := range e.ledger.Waiting(market) {
+		e.match(o)
+	}
+}
+
+// orZero 설정된 적 없는 값(빈 문자열)을 업비트처럼 "0"으로 내려준다
+func orZero(d upbit.Decimal) upbit.Decimal {
+	if d == "" {
+		return "0"
+	}
+	return d
+}
@@ -0,0 +1,278 @@
+package upbittest_test
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+	"upbit-mcp-server/upbit"
+	"upbit-mcp-server/upbittest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func newExchange(t *testing.T) *upbittest.Exchange {
+	return upbittest.New(t, "KRW-BTC", "100000000", map[string]upbit.Decimal{"KRW": "1000000"})
+}
+
+// fastRetry 테스트가 오래 걸리지 않도록 재시도 대기 시간을 줄인 정책
+func fastRetry(attempts int) upbit.Option {
+	return upbit.WithRetryPolicy(upbit.RetryPolicy{
+		MaxAttempts: attempts,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	})
+}
+
+func TestSignedRoundTrip(t *testing.T) {
+	ex := newExchange(t)
+	client := ex.Client()
+	ctx := context.Background()
+
+	// 파라미터가 있는 GET: query_hash가 URL 쿼리와 일치해야 한다
+	chance, err := client.GetChance(ctx, "KRW-BTC")
+	if err != nil {
+		t.Fatalf("GetChance: %v", err)
+	}
+	if !chance.BidAccount.Balance.Equal("1000000") {
+		t.Errorf("bid balance = %s, want 1000000", chance.BidAccount.Balance)
+	}
+
+	// POST: query_hash가 JSON Body와 일치해야 한다
+	placed, err := client.PlaceOrder(ctx, upbit.RequestParams{
+		Market:     "KRW-BTC",
+		Side:       "bid",
+		OrdType:    upbit.OrdTypeLimit,
+		Price:      "90000000",
+		Volume:     "0.001",
+		Identifier: "round-trip-1",
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if placed.State != upbit.OrderStateWait {
+		t.Errorf("state = %s, want %s", placed.State, upbit.OrderStateWait)
+	}
+
+	// 지정가 아래에서는 체결되지 않고 주문 금액과 수수료가 묶인다
+	got, err := client.GetOrder(ctx, placed.Uuid)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got.Identifier != "round-trip-1" || !got.RemainingVolume.Equal("0.001") {
+		t.Errorf("order = %+v", got)
+	}
+	if krw := ex.Account("KRW"); !krw.Locked.Equal("90045") {
+		t.Errorf("locked = %s, want 90045", krw.Locked)
+	}
+
+	// 시세가 지정가까지 내려오면 체결된다
+	ex.SetPrice("KRW-BTC", "90000000")
+	got, err = client.GetOrder(ctx, placed.Uuid)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got.State != upbit.OrderStateDone || !got.ExecutedVolume.Equal("0.001") {
+		t.Errorf("state = %s, executed = %s", got.State, got.ExecutedVolume)
+	}
+
+	accounts, err := client.GetAccounts(ctx)
+	if err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	balances := map[string]upbit.Decimal{}
+	for _, a := range accounts {
+		balances[a.Currency] = a.Balance
+	}
+	if !balances["BTC"].Equal("0.001") || !balances["KRW"].Equal("909955") {
+		t.Errorf("balances = %v", balances)
+	}
+}
+
+func TestQueryHashMismatch(t *testing.T) {
+	ex := newExchange(t)
+
+	tests := []struct {
+		name      string
+		queryHash string
+		alg       string
+	}{
+		{name: "다른 쿼리의 해시", queryHash: hashOf("market=KRW-ETH"), alg: "SHA512"},
+		{name: "해시 누락", queryHash: "", alg: ""},
+		{name: "알고리즘 불일치", queryHash: hashOf("market=KRW-BTC"), alg: "SHA256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{
+				"access_key": ex.AccessKey,
+				"nonce":      uuid.NewString(),
+			}
+			if tt.queryHash != "" {
+				claims["query_hash"] = tt.queryHash
+				claims["query_hash_alg"] = tt.alg
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ex.SecretKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, ex.URL()+"orders/chance?"+url.Values{"market": {"KRW-BTC"}}.Encode(), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				Error struct {
+					Name string `json:"name"`
+				} `json:"error"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if resp.StatusCode != http.StatusUnauthorized || body.Error.Name != upbit.ErrInvalidQueryPayload {
+				t.Errorf("status = %d, name = %q, want 401 %s", resp.StatusCode, body.Error.Name, upbit.ErrInvalidQueryPayload)
+			}
+		})
+	}
+
+	// 같은 요청을 올바르게 서명하면 통과한다
+	if _, err := ex.Client().GetChance(context.Background(), "KRW-BTC"); err != nil {
+		t.Errorf("signed request rejected: %v", err)
+	}
+}
+
+func hashOf(queryString string) string {
+	hash := sha512.Sum512([]byte(queryString))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestTooManyRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		method   string
+		path     string
+		call     func(ctx context.Context, c *upbit.Client) error
+		wantErr  bool
+		requests int
+	}{
+		{
+			name:     "조회는 재시도 후 성공",
+			attempts: 3,
+			method:   http.MethodGet,
+			path:     "accounts",
+			call: func(ctx context.Context, c *upbit.Client) error {
+				_, err := c.GetAccounts(ctx)
+				return err
+			},
+			requests: 2,
+		},
+		{
+			name:     "재시도하지 않으면 429를 그대로 반환",
+			attempts: 1,
+			method:   http.MethodGet,
+			path:     "accounts",
+			call: func(ctx context.Context, c *upbit.Client) error {
+				_, err := c.GetAccounts(ctx)
+				return err
+			},
+			wantErr:  true,
+			requests: 1,
+		},
+		{
+			name:     "identifier 없는 주문은 재시도하지 않음",
+			attempts: 3,
+			method:   http.MethodPost,
+			path:     "orders",
+			call: func(ctx context.Context, c *upbit.Client) error {
+				_, err := c.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypePrice, Price: "10000"})
+				return err
+			},
+			wantErr:  true,
+			requests: 1,
+		},
+		{
+			name:     "identifier 있는 주문은 재시도",
+			attempts: 3,
+			method:   http.MethodPost,
+			path:     "orders",
+			call: func(ctx context.Context, c *upbit.Client) error {
+				_, err := c.PlaceOrder(ctx, upbit.RequestParams{Market: "KRW-BTC", Side: "bid", OrdType: upbit.OrdTypePrice, Price: "10000", Identifier: "retry-1"})
+				return err
+			},
+			requests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := newExchange(t)
+			ex.FailNext(upbittest.Failure{
+				Method:  tt.method,
+				Path:    tt.path,
+				Status:  http.StatusTooManyRequests,
+				Name:    upbit.ErrTooManyRequests,
+				Message: "Too many API requests.",
+			})
+			// 클라이언트 속도 제한기의 1초 대기를 피하려고 끈다
+			client := ex.Client(upbit.WithRateLimiter(nil), fastRetry(tt.attempts))
+
+			err := tt.call(context.Background(), client)
+			if tt.wantErr {
+				var apiErr *upbit.APIError
+				if !errors.As(err, &apiErr) || !apiErr.IsRateLimited() || apiErr.Name != upbit.ErrTooManyRequests {
+					t.Fatalf("err = %v, want 429 %s", err, upbit.ErrTooManyRequests)
+				}
+				if !upbit.IsTemporary(err) {
+					t.Errorf("429 should be temporary")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n := ex.RequestCount(tt.method, tt.path); n != tt.requests {
+				t.Errorf("requests = %d, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	ex := newExchange(t)
+	ex.SetRateLimit(upbit.GroupDefault, 2)
+
+	// 서버 제한을 넘기면 Remaining-Req와 함께 429를 받는다
+	client := ex.Client(upbit.WithRateLimiter(nil), fastRetry(1))
+	ctx := context.Background()
+
+	var apiErr *upbit.APIError
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetAccounts(ctx); err != nil {
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			break
+		}
+	}
+	if apiErr == nil || !apiErr.IsRateLimited() {
+		t.Fatalf("expected 429 within the same second, got %v", apiErr)
+	}
+	if apiErr.RemainingReq == nil || apiErr.RemainingReq.Group != upbit.GroupDefault || apiErr.RemainingReq.Sec != 0 {
+		t.Errorf("remaining = %+v", apiErr.RemainingReq)
+	}
+
+	// 기본 속도 제한기는 Remaining-Req를 반영해 다음 구간까지 기다리므로 429를 받지 않는다
+	fresh := newExchange(t)
+	fresh.SetRateLimit(upbit.GroupDefault, 2)
+	limited := fresh.Client(fastRetry(1))
+	for i := 0; i < 5; i++ {
+		if _, err := limited.GetAccounts(ctx); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package upbittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"upbit-mcp-server/upbit"
+)
+
+// Fixture 테스트 거래소의 초기 상태. JSON 파일로 작성해 LoadFixtureFile로 불러올 수 있다.
+//
+// Candles의 키는 캔들 종류와 마켓을 합친 값이다. e.g. "days/KRW-BTC", "minutes/1/KRW-BTC"
+type Fixture struct {
+	Markets    []upbit.MarketInfo        `json:"markets"`
+	Accounts   []upbit.Account           `json:"accounts"`
+	Tickers    []upbit.Ticker            `json:"tickers"`
+	OrderBooks []upbit.OrderBook         `json:"orderbooks"`
+	Candles    map[string][]upbit.Candle `json:"candles"`
+}
+
+// LoadFixture 픽스처의 내용을 거래소 상태에 반영한다. 기존 상태에 덮어쓴다.
+func (e *Exchange) LoadFixture(f Fixture) {
+	for _, m := range f.Markets {
+		e.AddMarket(m)
+	}
+	for _, a := range f.Accounts {
+		e.SetAccount(a)
+	}
+	for _, t := range f.Tickers {
+		e.SetTicker(t)
+	}
+	for _, b := range f.OrderBooks {
+		e.SetOrderBook(b)
+	}
+	for key, candles := range f.Candles {
+		e.SetCandles(key, candles)
+	}
+}
+
+// LoadFixtureFile JSON 픽스처 파일을 읽어 거래소 상태에 반영한다
+func (e *Exchange) LoadFixtureFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("parse fixture %s: %w", path, err)
+	}
+
+	e.LoadFixture(f)
+	return nil
+}
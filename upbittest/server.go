@@ -0,0 +1,538 @@
+package upbittest
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"upbit-mcp-server/internal/ledger"
+	"upbit-mcp-server/upbit"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// apiError 업비트 오류 응답 형식 {"error":{"name":...,"message":...}}
+type apiError struct {
+	status  int
+	name    string
+	message string
+}
+
+func (e *Exchange) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// 시세 조회 (인증 불필요)
+	mux.HandleFunc("GET /v1/market/all", e.public("market", e.handleMarkets))
+	mux.HandleFunc("GET /v1/ticker", e.public("ticker", e.handleTicker))
+	mux.HandleFunc("GET /v1/orderbook", e.public("orderbook", e.handleOrderBook))
+	mux.HandleFunc("GET /v1/candles/{kind}", e.public("candle", e.handleCandles))
+	mux.HandleFunc("GET /v1/candles/minutes/{unit}", e.public("candle", e.handleCandles))
+
+	// 거래 및 계좌 (인증 필요)
+	mux.HandleFunc("GET /v1/accounts", e.private("default", e.handleAccounts))
+	mux.HandleFunc("GET /v1/orders/chance", e.private("default", e.handleChance))
+	mux.HandleFunc("POST /v1/orders", e.private("order", e.handlePlaceOrder))
+	mux.HandleFunc("GET /v1/order", e.private("default", e.handleGetOrder))
+	mux.HandleFunc("DELETE /v1/order", e.private("default", e.handleCancelOrder))
+	mux.HandleFunc("GET /v1/orders/open", e.private("default", e.handleOpenOrders))
+	mux.HandleFunc("GET /v1/orders/closed", e.private("default", e.handleClosedOrders))
+
+	return mux
+}
+
+type handlerFunc func(params map[string]string) (any, *apiError)
+
+// public 인증이 필요 없는 API 핸들러
+func (e *Exchange) public(group string, h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e.serve(w, r, group, false, h)
+	}
+}
+
+// private JWT 인증이 필요한 API 핸들러
+func (e *Exchange) private(group string, h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e.serve(w, r, group, true, h)
+	}
+}
+
+func (e *Exchange) serve(w http.ResponseWriter, r *http.Request, group string, auth bool, h handlerFunc) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	e.mu.Lock()
+	e.requests[r.Method+" "+path]++
+	f, failed := e.popFailure(r.Method, path)
+	remaining, limited := e.consume(group)
+	e.mu.Unlock()
+
+	w.Header().Set("Remaining-Req", fmt.Sprintf("group=%s; min=1800; sec=%d", group, remaining))
+	if limited {
+		writeError(w, &apiError{http.StatusTooManyRequests, upbit.ErrTooManyRequests, "Too many API requests."})
+		return
+	}
+	if failed {
+		for k, vs := range f.Header {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+		writeError(w, &apiError{status: f.Status, name: f.Name, message: f.Message})
+		return
+	}
+
+	params, queryString, err := readParams(r)
+	if err != nil {
+		writeError(w, &apiError{status: http.StatusBadRequest, name: "invalid_parameter", message: err.Error()})
+		return
+	}
+
+	if auth {
+		if err := e.authenticate(r, queryString); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	e.mu.Lock()
+	res, apiErr := h(params)
+	e.mu.Unlock()
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(res)
+}
+
+// consume 그룹의 이번 초 요청 수를 늘리고 남은 요청 수를 반환한다
+func (e *Exchange) consume(group string) (remaining int, limited bool) {
+	limit := e.limits[group]
+	if limit <= 0 {
+		return 999, false
+	}
+
+	now := time.Now().Unix()
+	w, ok := e.windows[group]
+	if !ok || w.second != now {
+		w = &window{second: now}
+		e.windows[group] = w
+	}
+	w.count++
+	return max(limit-w.count, 0), w.count > limit
+}
+
+func (e *Exchange) popFailure(method, path string) (Failure, bool) {
+	for i, f := range e.failures {
+		if (f.Method == "" || f.Method == method) && f.Path == path {
+			e.failures = append(e.failures[:i], e.failures[i+1:]...)
+			return f, true
+		}
+	}
+	return Failure{}, false
+}
+
+// readParams 요청 파라미터와 query_hash 검증에 사용할 쿼리 스트링을 읽는다.
+// GET/DELETE는 URL 쿼리를, POST는 JSON Body를 사용한다.
+func readParams(r *http.Request) (map[string]string, string, error) {
+	params := map[string]string{}
+	for _, name := range []string{"kind", "unit"} {
+		if v := r.PathValue(name); v != "" {
+			params[name] = v
+		}
+	}
+
+	if r.Method != http.MethodPost {
+		for k, vs := range r.URL.Query() {
+			params[k] = vs[0]
+		}
+		queryString, err := url.QueryUnescape(r.URL.RawQuery)
+		return params, queryString, err
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) == 0 {
+		return params, "", err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]string, 0, len(raw))
+	for k, v := range raw {
+		params[k] = fmt.Sprintf("%v", v)
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+params[k])
+	}
+	return params, strings.Join(parts, "&"), nil
+}
+
+// authenticate 업비트와 같은 방식으로 JWT 토큰과 query_hash를 검증한다
+func (e *Exchange) authenticate(r *http.Request, queryString string) *apiError {
+	header := r.Header.Get("Authorization")
+	tokenString, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || tokenString == "" {
+		return &apiError{http.StatusUnauthorized, "no_authorization_token", "Authorization token does not exist."}
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		return []byte(e.SecretKey), nil
+	}, jwt.WithValidMethods([]string{"HS256", "HS512"}))
+	if err != nil {
+		return &apiError{http.StatusUnauthorized, "jwt_verification", "Failed to verify Jwt token."}
+	}
+
+	if claims["access_key"] != e.AccessKey {
+		return &apiError{http.StatusUnauthorized, "invalid_access_key", "잘못된 엑세스 키입니다."}
+	}
+
+	nonce, _ := claims["nonce"].(string)
+	if nonce == "" {
+		return &apiError{http.StatusUnauthorized, "jwt_verification", "Failed to verify Jwt token."}
+	}
+	e.mu.Lock()
+	used := e.nonces[nonce]
+	e.nonces[nonce] = true
+	e.mu.Unlock()
+	if used {
+		return &apiError{http.StatusUnauthorized, "nonce_used", "이미 요청한 nonce값이 다시 사용되었습니다."}
+	}
+
+	queryHash, _ := claims["query_hash"].(string)
+	if queryString == "" {
+		if queryHash != "" {
+			return &apiError{http.StatusUnauthorized, "invalid_query_payload", "JWT 헤더의 페이로드가 올바르지 않습니다."}
+		}
+		return nil
+	}
+
+	hash := sha512.Sum512([]byte(queryString))
+	if claims["query_hash_alg"] != "SHA512" || queryHash != hex.EncodeToString(hash[:]) {
+		return &apiError{http.StatusUnauthorized, "invalid_query_payload", "JWT 헤더의 페이로드가 올바르지 않습니다."}
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(err.status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]string{"name": err.name, "message": err.message},
+	})
+}
+
+func notFound(name, message string) *apiError {
+	return &apiError{http.StatusNotFound, name, message}
+}
+
+func badRequest(name, message string) *apiError {
+	return &apiError{http.StatusBadRequest, name, message}
+}
+
+func (e *Exchange) handleMarkets(params map[string]string) (any, *apiError) {
+	res := make([]upbit.MarketInfo, 0, len(e.markets))
+	for _, m := range e.markets {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Market < res[j].Market })
+	return res, nil
+}
+
+func (e *Exchange) handleTicker(params map[string]string) (any, *apiError) {
+	var res []upbit.Ticker
+	for _, market := range strings.Split(params["markets"], ",") {
+		t, ok := e.tickers[market]
+		if !ok {
+			return nil, notFound("Code not found", "Code not found")
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+func (e *Exchange) handleOrderBook(params map[string]string) (any, *apiError) {
+	var res []upbit.OrderBook
+	for _, market := range strings.Split(params["markets"], ",") {
+		b, ok := e.books[market]
+		if !ok {
+			return nil, notFound("Code not found", "Code not found")
+		}
+		res = append(res, b)
+	}
+	return res, nil
+}
+
+func (e *Exchange) handleCandles(params map[string]string) (any, *apiError) {
+	kind := params["kind"]
+	if unit, ok := params["unit"]; ok {
+		kind = "minutes/" + unit
+	}
+
+	candles, ok := e.candles[kind+"/"+params["market"]]
+	if !ok {
+		if _, known := e.markets[params["market"]]; !known {
+			return nil, notFound("Code not found", "Code not found")
+		}
+	}
+
+	count := 1
+	if v, ok := params["count"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			return nil, badRequest("invalid_parameter", "count must be between 1 and 200")
+		}
+		count = n
+	}
+
+	to := normalizeCandleTime(params["to"])
+	res := []upbit.Candle{}
+	for _, c := range candles {
+		if to != "" && normalizeCandleTime(c.CandleDateTimeUtc) >= to {
+			continue
+		}
+		res = append(res, c)
+		if len(res) == count {
+			break
+		}
+	}
+	return res, nil
+}
+
+// normalizeCandleTime 캔들 시각 비교를 위해 "yyyy-MM-ddTHH:mm:ss" 형식으로 맞춘다
+func normalizeCandleTime(s string) string {
+	s = strings.Replace(s, " ", "T", 1)
+	return strings.TrimSuffix(s, "Z")
+}
+
+func (e *Exchange) handleAccounts(params map[string]string) (any, *apiError) {
+	return e.ledger.Accounts(), nil
+}
+
+func (e *Exchange) handleChance(params map[string]string) (any, *apiError) {
+	market := params["market"]
+	if _, ok := e.markets[market]; !ok {
+		return nil, notFound("market_does_not_exist", "마켓을 찾지 못했습니다.")
+	}
+
+	base, quote := ledger.SplitMarket(market)
+	minTotal := e.minTotal[quote]
+	bidAccount := e.ledger.Wallet(quote).Account(quote)
+	askAccount := e.ledger.Wallet(base).Account(base)
+
+	return upbit.Chance{
+		BidFee:      e.bidFee,
+		AskFee:      e.askFee,
+		MakerBidFee: e.bidFee,
+		MakerAskFee: e.askFee,
+		Market: upbit.ChanceMarket{
+			Id:         market,
+			Name:       base + "/" + quote,
+			OrderSides: []string{"ask", "bid"},
+			BidTypes:   []string{"limit", "price", "best_fok", "best_ioc", "limit_ioc", "limit_fok"},
+			AskTypes:   []string{"limit", "market", "best_fok", "best_ioc", "limit_ioc", "limit_fok"},
+			Bid:        upbit.BidChanceLimit{Currency: quote, MinTotal: minTotal},
+			Ask:        upbit.AskChanceLimit{Currency: quote, MinTotal: minTotal},
+			MaxTotal:   "1000000000",
+			State:      "active",
+		},
+		BidAccount: upbit.BidAccount(bidAccount),
+		AskAccount: upbit.AskAccount(askAccount),
+	}, nil
+}
+
+func (e *Exchange) handlePlaceOrder(params map[string]string) (any, *apiError) {
+	market := params["market"]
+	if _, ok := e.markets[market]; !ok {
+		return nil, notFound("market_does_not_exist", "마켓을 찾지 못했습니다.")
+	}
+
+	price, err := upbit.ParseDecimal(params["price"])
+	if err != nil {
+		return nil, badRequest("validation_error", "invalid price")
+	}
+	volume, err := upbit.ParseDecimal(params["volume"])
+	if err != nil {
+		return nil, badRequest("validation_error", "invalid volume")
+	}
+
+	if id := params["identifier"]; id != "" && e.ledger.ByIdentifier(id) != nil {
+		return nil, badRequest(upbit.ErrDuplicateIdentifier, "이미 사용된 identifier입니다.")
+	}
+
+	req := upbit.RequestParams{
+		Market:      market,
+		Identifier:  params["identifier"],
+		Side:        params["side"],
+		OrdType:     params["ord_type"],
+		TimeInForce: params["time_in_force"],
+		SmpType:     params["smp_type"],
+		Price:       price,
+		Volume:      volume,
+	}
+	if apiErr := e.validateOrder(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	o := ledger.NewOrder(req, time.Now())
+	if err := e.ledger.Place(o, e.bidFee); err != nil {
+		var placeErr *upbit.APIError
+		errors.As(err, &placeErr)
+		return nil, &apiError{status: placeErr.StatusCode, name: placeErr.Name, message: placeErr.Message}
+	}
+
+	// 응답은 접수 시점의 상태이고, 매칭은 그 이후에 일어난다
+	res := o.Snapshot()
+	e.match(o)
+	return res, nil
+}
+
+// validateOrder 주문 파라미터와 최소 주문 금액 검증
+func (e *Exchange) validateOrder(o upbit.RequestParams) *apiError {
+	_, quote := ledger.SplitMarket(o.Market)
+	minTotal := e.minTotal[quote]
+
+	switch o.SmpType {
+	case "", upbit.SmpCancelMaker, upbit.SmpCancelTaker, upbit.SmpReduce:
+	default:
+		return badRequest("validation_error", fmt.Sprintf("invalid smp_type: %s", o.SmpType))
+	}
+	switch o.TimeInForce {
+	case "":
+		if o.OrdType == "best" {
+			return badRequest("validation_error", "best orders require time_in_force")
+		}
+	case upbit.TimeInForceIOC, upbit.TimeInForceFOK:
+		if o.OrdType != "limit" && o.OrdType != "best" {
+			return badRequest("validation_error", "time_in_force is only supported for limit and best orders")
+		}
+	default:
+		return badRequest("validation_error", fmt.Sprintf("invalid time_in_force: %s", o.TimeInForce))
+	}
+
+	switch {
+	case o.Side == "bid" && o.OrdType == "limit", o.Side == "ask" && o.OrdType == "limit":
+		if o.Price.Sign() <= 0 || o.Volume.Sign() <= 0 {
+			return badRequest("validation_error", "price and volume are required for limit orders")
+		}
+		if !upbit.IsValidPrice(o.Market, o.Price) {
+			return badRequest("invalid_price_"+o.Side, "주문 가격 단위를 잘못 입력하셨습니다. 확인 후 시도해주세요.")
+		}
+		if o.Price.Mul(o.Volume).LessThan(minTotal) {
+			return badRequest("under_min_total_"+o.Side, fmt.Sprintf("최소주문금액 이상으로 주문해주세요 (%s %s)", minTotal, quote))
+		}
+	case o.Side == "bid" && (o.OrdType == "price" || o.OrdType == "best"):
+		if o.Price.Sign() <= 0 || !o.Volume.IsZero() {
+			return badRequest("validation_error", o.OrdType+" buy orders require price and must not have volume")
+		}
+		if o.Price.LessThan(minTotal) {
+			return badRequest("under_min_total_bid", fmt.Sprintf("최소주문금액 이상으로 주문해주세요 (%s %s)", minTotal, quote))
+		}
+	case o.Side == "ask" && (o.OrdType == "market" || o.OrdType == "best"):
+		if o.Volume.Sign() <= 0 || !o.Price.IsZero() {
+			return badRequest("validation_error", o.OrdType+" sell orders require volume and must not have price")
+		}
+		if t, ok := e.tickers[o.Market]; ok && o.Volume.Mul(t.TradePrice).LessThan(minTotal) {
+			return badRequest("under_min_total_ask", fmt.Sprintf("최소주문금액 이상으로 주문해주세요 (%s %s)", minTotal, quote))
+		}
+	default:
+		return badRequest("validation_error", fmt.Sprintf("unsupported order: side=%s ord_type=%s", o.Side, o.OrdType))
+	}
+	return nil
+}
+
+// findOrder uuid 또는 identifier로 주문을 찾는다
+func (e *Exchange) findOrder(params map[string]string) (*ledger.Order, *apiError) {
+	if id := params["uuid"]; id != "" {
+		if o, ok := e.ledger.Order(id); ok {
+			return o, nil
+		}
+	} else if identifier := params["identifier"]; identifier != "" {
+		if o := e.ledger.ByIdentifier(identifier); o != nil {
+			return o, nil
+		}
+	} else {
+		return nil, badRequest("validation_error", "uuid or identifier is required")
+	}
+	return nil, notFound("order_not_found", "주문을 찾지 못했습니다.")
+}
+
+func (e *Exchange) handleGetOrder(params map[string]string) (any, *apiError) {
+	o, apiErr := e.findOrder(params)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return o.Detail(), nil
+}
+
+func (e *Exchange) handleCancelOrder(params map[string]string) (any, *apiError) {
+	o, apiErr := e.findOrder(params)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	// 실제 API와 같이 취소 요청 시점의 주문 상태를 응답한다
+	res := o.Snapshot()
+	if !e.ledger.Cancel(o) {
+		return nil, notFound("order_not_found", "주문을 찾지 못했습니다.")
+	}
+	return res, nil
+}
+
+func (e *Exchange) handleOpenOrders(params map[string]string) (any, *apiError) {
+	page := atoiDefault(params["page"], 1)
+	limit := atoiDefault(params["limit"], 100)
+	if page < 1 || limit < 1 || limit > 100 {
+		return nil, badRequest("validation_error", "invalid page or limit")
+	}
+
+	orders := e.ledger.Filter(params["market"], params["order_by"], func(o *ledger.Order) bool {
+		return o.State == upbit.OrderStateWait
+	})
+
+	start := min((page-1)*limit, len(orders))
+	end := min(start+limit, len(orders))
+	return orders[start:end], nil
+}
+
+func (e *Exchange) handleClosedOrders(params map[string]string) (any, *apiError) {
+	limit := atoiDefault(params["limit"], 100)
+	if limit < 1 || limit > 1000 {
+		return nil, badRequest("validation_error", "invalid limit")
+	}
+
+	state := params["state"]
+	if state != "" && state != "done" && state != "cancel" {
+		return nil, badRequest("validation_error", "state must be done or cancel")
+	}
+
+	orders := e.ledger.Filter(params["market"], params["order_by"], func(o *ledger.Order) bool {
+		if state == "" {
+			return o.State == upbit.OrderStateDone || o.State == upbit.OrderStateCancel
+		}
+		return o.State == state
+	})
+	return orders[:min(limit, len(orders))], nil
+}
+
+func atoiDefault(s string, fallback int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,41 @@
+package upbittest
+
+import (
+	"context"
+	"testing"
+	"upbit-mcp-server/upbit"
+)
+
+// New 테스트가 끝나면 닫히는 거래소를 생성하고 market을 등록한다.
+// market의 현재가는 price이며, balances는 화폐별 주문 가능 잔고이다.
+func New(t testing.TB, market string, price upbit.Decimal, balances map[string]upbit.Decimal) *Exchange {
+	t.Helper()
+
+	ex := NewExchange("ak", "sk")
+	t.Cleanup(ex.Close)
+
+	ex.AddMarket(upbit.MarketInfo{Market: market})
+	ex.SetPrice(market, price)
+	for currency, balance := range balances {
+		ex.SetBalance(currency, balance)
+	}
+	return ex
+}
+
+// FillBefore 주문을 취소하기 직전에 market의 현재가를 price로 바꾸는 Trader.
+// 취소하려던 지정가 주문이 취소 요청보다 먼저 체결되는 경우를 재현한다.
+func (e *Exchange) FillBefore(trader upbit.Trader, market string, price upbit.Decimal) upbit.Trader {
+	return &fillBeforeCancel{Trader: trader, ex: e, market: market, price: price}
+}
+
+type fillBeforeCancel struct {
+	upbit.Trader
+	ex     *Exchange
+	market string
+	price  upbit.Decimal
+}
+
+func (f *fillBeforeCancel) CancelOrder(ctx context.Context, uuid string) (bool, error) {
+	f.ex.SetPrice(f.market, f.price)
+	return f.Trader.CancelOrder(ctx, uuid)
+}